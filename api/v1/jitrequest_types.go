@@ -26,20 +26,62 @@ type JitRequestSpec struct {
 	Reporter string `json:"userEmail"`
 	// Additional user emails to add to the Jira request
 	AdditionUserEmails []string `json:"additionalEmails,omitempty"`
+	// Additional typed subjects (users, groups or service accounts) to bind the role to
+	Subjects []SubjectSpec `json:"subjects,omitempty"`
 	// Role to bind
 	ClusterRole string `json:"clusterRole"`
 	// Namespace to bind role and user
-	Namespaces []string `json:"namespaces"`
+	Namespaces []string `json:"namespaces,omitempty"`
+	// Bind the role cluster-wide with a ClusterRoleBinding instead of namespaced Role Bindings
+	ClusterScoped bool `json:"clusterScoped,omitempty"`
 	// Optional labels to filter namespace on
 	NamespaceLabels map[string]string `json:"namespaceLabels,omitempty"`
 	// Start time for the JIT access, i.e. "2024-12-04T21:00:00Z"
-	// ISO 8601 format
-	StartTime metav1.Time `json:"startTime"`
+	// ISO 8601 format, required unless startOnApproval is set
+	StartTime metav1.Time `json:"startTime,omitempty"`
 	// End time for the JIT access, i.e. "2024-12-04T22:00:00Z"
-	// ISO 8601 format
-	EndTime metav1.Time `json:"endTime"`
+	// ISO 8601 format, required unless duration is set
+	EndTime metav1.Time `json:"endTime,omitempty"`
+	// Duration of the JIT access from the start time as an alternative to endTime, i.e. "1h"
+	Duration *metav1.Duration `json:"duration,omitempty"`
+	// Start the JIT access as soon as the Jira ticket is approved instead of at startTime
+	StartOnApproval bool `json:"startOnApproval,omitempty"`
 	// Custom Jira workflow fields
 	JiraFields map[string]string `json:"jiraFields"`
+	// Optional key of an existing open Jira issue to link the Jira ticket to, i.e. an incident ticket
+	LinkedJiraTicket string `json:"linkedJiraTicket,omitempty"`
+	// Revoke access early, removes the Role Bindings and keeps the JitRequest for auditing
+	Revocation *RevocationSpec `json:"revocation,omitempty"`
+	// Request to extend the end time of a Succeeded JitRequest, subject to Jira re-approval
+	Extension *ExtensionSpec `json:"extension,omitempty"`
+}
+
+// ExtensionSpec defines a request to extend the end time of a JitRequest
+type ExtensionSpec struct {
+	// New end time for the JIT access, i.e. "2024-12-04T23:00:00Z"
+	// ISO 8601 format
+	EndTime metav1.Time `json:"endTime"`
+	// Reason for extending access
+	Reason string `json:"reason"`
+}
+
+// RevocationSpec defines who revoked a JitRequest and why
+type RevocationSpec struct {
+	// The user revoking access, must be the Kubernetes username making the change
+	RevokedBy string `json:"revokedBy"`
+	// Reason for revoking access
+	Reason string `json:"reason"`
+}
+
+// SubjectSpec defines a Role Binding subject for a JitRequest
+type SubjectSpec struct {
+	// Kind of subject, one of User, Group or ServiceAccount
+	// +kubebuilder:validation:Enum=User;Group;ServiceAccount
+	Kind string `json:"kind"`
+	// Name of the user, group or service account
+	Name string `json:"name"`
+	// Namespace of the service account, required for ServiceAccount subjects
+	Namespace string `json:"namespace,omitempty"`
 }
 
 // JitRequestStatus defines the observed state of JitRequest.
@@ -51,22 +93,106 @@ type JitRequestStatus struct {
 	Message string `json:"message,omitempty"`
 	// Jira ticket for jit request
 	JiraTicket string `json:"jiraTicket,omitempty"`
+	// Current status of the Jira ticket, updated while polling for approval
+	JiraStatus string `json:"jiraStatus,omitempty"`
 	// Start time for the JIT access, i.e. "2024-12-04T21:00:00Z"
-	// ISO 8601 format
-	StartTime metav1.Time `json:"startTime"`
+	// ISO 8601 format, set at grant time for startOnApproval requests
+	StartTime metav1.Time `json:"startTime,omitempty"`
 	// End time for the JIT access, i.e. "2024-12-04T22:00:00Z"
+	// ISO 8601 format, set at grant time for startOnApproval requests
+	EndTime metav1.Time `json:"endTime,omitempty"`
+	// Status of the latest extension request
+	Extension *ExtensionStatus `json:"extension,omitempty"`
+	// Distinct approvers of the Jira ticket, from the approvals or the changelog of the ticket
+	Approvals []Approval `json:"approvals,omitempty"`
+	// Time the Jira ticket was approved, the latest of the approvals
+	ApprovedAt *metav1.Time `json:"approvedAt,omitempty"`
+	// Status of the Jira ticket when it was approved
+	ApprovedJiraStatus string `json:"approvedJiraStatus,omitempty"`
+	// Time the JitRequest reached a final state (Rejected, Revoked or Expired)
+	CompletionTime *metav1.Time `json:"completionTime,omitempty"`
+	// Conditions of the jit request, kept up to date alongside the state
+	// +listType=map
+	// +listMapKey=type
+	// +patchStrategy=merge
+	// +patchMergeKey=type
+	// +optional
+	Conditions []metav1.Condition `json:"conditions,omitempty" patchStrategy:"merge" patchMergeKey:"type"`
+}
+
+// Condition types
+const (
+	// ConditionTicketCreated is true once the Jira ticket has been created
+	ConditionTicketCreated = "TicketCreated"
+	// ConditionValidated is true once the JitRequest has passed validation against the config
+	ConditionValidated = "Validated"
+	// ConditionApproved is true once the Jira ticket has been approved
+	ConditionApproved = "Approved"
+	// ConditionAccessGranted is true while the role binding(s) exist
+	ConditionAccessGranted = "AccessGranted"
+	// ConditionExpired is true once the end time has been reached and access removed
+	ConditionExpired = "Expired"
+	// ConditionRevoked is true once access has been revoked early
+	ConditionRevoked = "Revoked"
+	// ConditionJiraAvailable is false while the JitRequest is requeued because Jira is unavailable
+	ConditionJiraAvailable = "JiraAvailable"
+	// ConditionJiraTicketClosed is false until the Jira ticket of a rejected, revoked or expired JitRequest has been commented and transitioned
+	ConditionJiraTicketClosed = "JiraTicketClosed"
+)
+
+// JitRequest states
+const (
+	StatePreApproved = "Pre-Approved"
+	StateSucceeded   = "Succeeded"
+	StateRejected    = "Rejected"
+	StateRevoked     = "Revoked"
+	StateExpired     = "Expired"
+)
+
+// Extension states
+const (
+	ExtensionPending  = "Pending"
+	ExtensionApproved = "Approved"
+	ExtensionRejected = "Rejected"
+)
+
+// ExtensionStatus defines the observed state of an extension request
+type ExtensionStatus struct {
+	// State of the extension request, one of Pending, Approved or Rejected
+	State string `json:"state"`
+	// Requested end time for the JIT access
 	// ISO 8601 format
 	EndTime metav1.Time `json:"endTime"`
+	// Detailed message of the extension request
+	Message string `json:"message,omitempty"`
+	// Time the extension was requested, only approvals after it count towards the extension
+	RequestedAt *metav1.Time `json:"requestedAt,omitempty"`
+	// Distinct approvers of the extension, recorded for the serviceDesk and changelog approval modes
+	Approvals []Approval `json:"approvals,omitempty"`
+}
+
+// Approval defines an approval of the Jira ticket
+type Approval struct {
+	// Jira user name, or accountId on Jira Cloud, of the approver
+	Approver string `json:"approver"`
+	// Display name of the approver
+	DisplayName string `json:"displayName,omitempty"`
+	// Time of the approval, or the time it was first seen if Jira has no time for it
+	ApprovedAt metav1.Time `json:"approvedAt"`
 }
 
 // +kubebuilder:object:root=true
 // +kubebuilder:subresource:status
 // +kubebuilder:resource:scope=Cluster,shortName=jitreq
-// +kubebuilder:printcolumn:name="User",type=string,JSONPath=`.spec.user`
+// +kubebuilder:printcolumn:name="User",type=string,JSONPath=`.spec.userEmail`
 // +kubebuilder:printcolumn:name="Cluster Role",type=string,JSONPath=`.spec.clusterRole`
-// +kubebuilder:printcolumn:name="Namespace",type=string,JSONPath=`.spec.namespace`
-// +kubebuilder:printcolumn:name="Start Time",type=string,JSONPath=`.spec.startTime`
-// +kubebuilder:printcolumn:name="End Time",type=string,JSONPath=`.spec.endTime`
+// +kubebuilder:printcolumn:name="Namespaces",type=string,JSONPath=`.spec.namespaces`
+// +kubebuilder:printcolumn:name="State",type=string,JSONPath=`.status.state`
+// +kubebuilder:printcolumn:name="Jira Ticket",type=string,JSONPath=`.status.jiraTicket`
+// +kubebuilder:printcolumn:name="Jira Status",type=string,JSONPath=`.status.jiraStatus`,priority=1
+// +kubebuilder:printcolumn:name="Start Time",type=string,JSONPath=`.status.startTime`
+// +kubebuilder:printcolumn:name="End Time",type=string,JSONPath=`.status.endTime`
+// +kubebuilder:printcolumn:name="Completed",type=date,JSONPath=`.status.completionTime`
 
 // JitRequest is the Schema for the jitrequests API.
 type JitRequest struct {
@@ -24,16 +24,34 @@ import (
 type JustInTimeConfigSpec struct {
 	// Configure allowed cluster roles to bind for a JitRequest
 	AllowedClusterRoles []string `json:"allowedClusterRoles" validate:"required"`
+	// Configure allowed cluster roles to bind cluster-wide for a cluster scoped JitRequest
+	AllowedClusterScopedRoles []string `json:"allowedClusterScopedRoles,omitempty"`
 	// The value of the approved state for a Jira ticket, i.e. "Approved"
 	JiraWorkflowApproveStatus string `json:"workflowApprovedStatus" validate:"required"`
-	// The workflow transition ID for rejecting a ticket
+	// Optional value of the rejected state for a Jira ticket, pending JitRequests are rejected as soon as it is reached, i.e. "Rejected"
+	JiraWorkflowRejectedStatus string `json:"workflowRejectedStatus,omitempty"`
+	// Optional further statuses a Jira ticket is declined or closed in, pending JitRequests are rejected as soon as one is reached, i.e. ["Declined", "Closed"]
+	JiraWorkflowRejectedStatuses []string `json:"workflowRejectedStatuses,omitempty"`
+	// The workflow transition ID or name for rejecting a ticket
 	RejectedTransitionID string `json:"rejectedTransitionID" validate:"required"`
 	// The Jira project key
 	JiraProject string `json:"jiraProject" validate:"required"`
 	// The Jira issue type
 	JiraIssueType string `json:"jiraIssueType" validate:"required"`
-	// The workflow transition ID for an approved ticket
+	// Optional Jira project keys of existing issues a JitRequest's linkedJiraTicket can be in, i.e. ["INC"], defaults to the jiraProject
+	LinkedIssueProjects []string `json:"linkedIssueProjects,omitempty"`
+	// Optional name of the issue link type to link the Jira ticket to a linked issue with, defaults to "Relates"
+	IssueLinkType string `json:"issueLinkType,omitempty"`
+	// Optional Jira Service Management service desk and request type, tickets are created as customer requests on behalf of the reporter
+	ServiceDesk *ServiceDeskSpec `json:"serviceDesk,omitempty"`
+	// The workflow transition ID or name for an approved ticket
 	CompletedTransitionID string `json:"completedTransitionID" validate:"required"`
+	// Optional workflow transition ID or name for a revoked ticket, the ticket is only commented on if not set
+	RevokedTransitionID string `json:"revokedTransitionID,omitempty"`
+	// Optional workflow transition ID or name for a ticket when access expires at end time, the ticket is only commented on if not set
+	ExpiredTransitionID string `json:"expiredTransitionID,omitempty"`
+	// Optional workflow transition ID or name to move a completed ticket back for approval of an extension
+	ExtensionTransitionID string `json:"extensionTransitionID,omitempty"`
 	// Required fields for the Jira ticket
 	RequiredFields *RequiredFieldsSpec `json:"requiredFields"`
 	// Optional additional fields to map to the ticket and enforce on a JitRequest's jiraFields
@@ -48,6 +66,71 @@ type JustInTimeConfigSpec struct {
 	NamespaceAllowedRegex string `json:"namespaceAllowedRegex,omitempty"`
 	// Toggle self-approval for JitRequests
 	SelfApprovalEnabled bool `json:"selfApprovalEnabled,omitempty"`
+	// Optional period to keep Rejected, Revoked and Expired JitRequests for auditing before deleting them, i.e. "168h"
+	RetentionPeriod *metav1.Duration `json:"retentionPeriod,omitempty"`
+	// Optional interval to poll pending Jira tickets for approval, i.e. "1m"
+	ApprovalPollInterval *metav1.Duration `json:"approvalPollInterval,omitempty"`
+	// Optional grace period after the start time to wait for a late approval before rejecting, i.e. "15m"
+	ApprovalGracePeriod *metav1.Duration `json:"approvalGracePeriod,omitempty"`
+	// Optional period a startOnApproval JitRequest waits for approval before it is rejected, i.e. "4h", defaults to "24h"
+	StartOnApprovalTimeout *metav1.Duration `json:"startOnApprovalTimeout,omitempty"`
+	// Optional how approval of a Jira ticket is checked, the approved status (default), Jira Service Management approvals or the approved status transitions in the changelog
+	// +kubebuilder:validation:Enum=status;serviceDesk;changelog
+	ApprovalMode string `json:"approvalMode,omitempty"`
+	// Optional policies keyed by cluster role, enforced on top of the allowed cluster roles
+	RolePolicies map[string]RolePolicySpec `json:"rolePolicies,omitempty"`
+	// Optional IANA timezone to format date and datetime fields in Jira, i.e. "Europe/London", defaults to the operator's local timezone
+	Timezone string `json:"timezone,omitempty"`
+	// Optional Go text/templates for the Jira ticket summary, description and comments
+	Templates *JiraTemplatesSpec `json:"templates,omitempty"`
+}
+
+// JiraTemplatesSpec defines Go text/templates rendered over the JitRequest, the environment and its namespaces,
+// the defaults are used for templates that are not set
+type JiraTemplatesSpec struct {
+	// Optional template for the ticket summary
+	Summary string `json:"summary,omitempty"`
+	// Optional template for the ticket description, no description is set by default
+	Description string `json:"description,omitempty"`
+	// Optional template for the comment when a JitRequest is pre-approved
+	PreApprovedComment string `json:"preApprovedComment,omitempty"`
+	// Optional template for the comment when access is granted
+	CompletedComment string `json:"completedComment,omitempty"`
+	// Optional template for the comment when a JitRequest is rejected
+	RejectedComment string `json:"rejectedComment,omitempty"`
+	// Optional template for the comment when access is revoked
+	RevokedComment string `json:"revokedComment,omitempty"`
+	// Optional template for the comment when access expires at end time
+	ExpiredComment string `json:"expiredComment,omitempty"`
+	// Optional template for the comment when an extension is requested
+	ExtensionRequestedComment string `json:"extensionRequestedComment,omitempty"`
+	// Optional template for the comment when an extension is granted
+	ExtensionCompletedComment string `json:"extensionCompletedComment,omitempty"`
+}
+
+// ServiceDeskSpec defines the Jira Service Management request type to create customer requests with
+type ServiceDeskSpec struct {
+	// The service desk ID
+	ServiceDeskID string `json:"serviceDeskID"`
+	// The request type ID, its fields must include the summary and the required and custom fields
+	RequestTypeID string `json:"requestTypeID"`
+}
+
+// RolePolicySpec defines the limits for JitRequests binding a cluster role
+type RolePolicySpec struct {
+	// Optional maximum duration of access from start time to end time, including extensions, i.e. "8h"
+	MaxDuration *metav1.Duration `json:"maxDuration,omitempty"`
+	// Optional maximum time between the JitRequest being created and the start time, i.e. "168h"
+	MaxLeadTime *metav1.Duration `json:"maxLeadTime,omitempty"`
+	// Optional regexes, each namespace must match at least one of them
+	NamespaceAllowedRegexes []string `json:"namespaceAllowedRegexes,omitempty"`
+	// Optional label selector the namespaces must match
+	NamespaceSelector *metav1.LabelSelector `json:"namespaceSelector,omitempty"`
+	// Optional jiraFields that must be set to a non-empty value
+	RequiredJiraFields []string `json:"requiredJiraFields,omitempty"`
+	// Optional number of distinct approvers required, requires the serviceDesk or changelog approval mode
+	// +kubebuilder:validation:Minimum=1
+	RequiredApprovals int `json:"requiredApprovals,omitempty"`
 }
 
 // EnvironmentSpec defines the specification for the environment
@@ -70,14 +153,79 @@ type RequiredFieldsSpec struct {
 
 // CustomField defines the custom Jira fields to use in a Jira create payload
 type CustomFieldSettings struct {
+	// The type of the Jira field, multi-value fields are comma separated and cascading selects are "parent:child"
+	// +kubebuilder:validation:Enum=text;date;datetime;select;multiselect;user;multiuser;number;labels;cascadingselect;url;group;multigroup
 	Type            string `json:"type" validate:"required"`
 	JiraCustomField string `json:"jiraCustomField" validate:"required"`
 }
 
 // JustInTimeConfigStatus defines the observed state of JustInTimeConfig.
 type JustInTimeConfigStatus struct {
+	// Conditions of the config, the results of validating it and validating it against Jira
+	// +listType=map
+	// +listMapKey=type
+	// +patchStrategy=merge
+	// +patchMergeKey=type
+	// +optional
+	Conditions []metav1.Condition `json:"conditions,omitempty" patchStrategy:"merge" patchMergeKey:"type"`
+}
+
+// Jira field types of CustomFieldSettings
+const (
+	FieldTypeText            = "text"
+	FieldTypeDate            = "date"
+	FieldTypeDateTime        = "datetime"
+	FieldTypeSelect          = "select"
+	FieldTypeMultiSelect     = "multiselect"
+	FieldTypeUser            = "user"
+	FieldTypeMultiUser       = "multiuser"
+	FieldTypeNumber          = "number"
+	FieldTypeLabels          = "labels"
+	FieldTypeCascadingSelect = "cascadingselect"
+	FieldTypeURL             = "url"
+	FieldTypeGroup           = "group"
+	FieldTypeMultiGroup      = "multigroup"
+)
+
+// FieldTypes are the supported Jira field types
+var FieldTypes = []string{
+	FieldTypeText,
+	FieldTypeDate,
+	FieldTypeDateTime,
+	FieldTypeSelect,
+	FieldTypeMultiSelect,
+	FieldTypeUser,
+	FieldTypeMultiUser,
+	FieldTypeNumber,
+	FieldTypeLabels,
+	FieldTypeCascadingSelect,
+	FieldTypeURL,
+	FieldTypeGroup,
+	FieldTypeMultiGroup,
 }
 
+// Approval modes
+const (
+	// ApprovalModeStatus approves a Jira ticket once it reaches the approved status
+	ApprovalModeStatus = "status"
+	// ApprovalModeServiceDesk counts the approvers of the Jira Service Management approvals of a ticket
+	ApprovalModeServiceDesk = "serviceDesk"
+	// ApprovalModeChangelog counts the users who transitioned a ticket to the approved status in its changelog
+	ApprovalModeChangelog = "changelog"
+)
+
+// Config condition types
+const (
+	// ConditionConfigValid is true once the regexes, approvals, field types, timezone and templates of the config are valid
+	ConditionConfigValid = "ConfigValid"
+	// ConditionJiraFieldsValid is true once every required and custom field exists on the Jira create screen with a matching type
+	ConditionJiraFieldsValid = "JiraFieldsValid"
+	// ConditionJiraStatusesValid is true once the approved (and rejected) status exist in the Jira project
+	ConditionJiraStatusesValid = "JiraStatusesValid"
+	// ConditionJiraTransitionsValid is true once every configured transition exists in the Jira workflow
+	ConditionJiraTransitionsValid = "JiraTransitionsValid"
+)
+
 // +kubebuilder:object:root=true
 // +kubebuilder:subresource:status
 // +kubebuilder:resource:scope=Cluster,shortName=jitcfg
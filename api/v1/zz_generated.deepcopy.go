@@ -21,9 +21,26 @@ limitations under the License.
 package v1
 
 import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Approval) DeepCopyInto(out *Approval) {
+	*out = *in
+	in.ApprovedAt.DeepCopyInto(&out.ApprovedAt)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Approval.
+func (in *Approval) DeepCopy() *Approval {
+	if in == nil {
+		return nil
+	}
+	out := new(Approval)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *CustomFieldSettings) DeepCopyInto(out *CustomFieldSettings) {
 	*out = *in
@@ -54,6 +71,64 @@ func (in *EnvironmentSpec) DeepCopy() *EnvironmentSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ExtensionSpec) DeepCopyInto(out *ExtensionSpec) {
+	*out = *in
+	in.EndTime.DeepCopyInto(&out.EndTime)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ExtensionSpec.
+func (in *ExtensionSpec) DeepCopy() *ExtensionSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ExtensionSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ExtensionStatus) DeepCopyInto(out *ExtensionStatus) {
+	*out = *in
+	in.EndTime.DeepCopyInto(&out.EndTime)
+	if in.RequestedAt != nil {
+		in, out := &in.RequestedAt, &out.RequestedAt
+		*out = (*in).DeepCopy()
+	}
+	if in.Approvals != nil {
+		in, out := &in.Approvals, &out.Approvals
+		*out = make([]Approval, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ExtensionStatus.
+func (in *ExtensionStatus) DeepCopy() *ExtensionStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ExtensionStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *JiraTemplatesSpec) DeepCopyInto(out *JiraTemplatesSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new JiraTemplatesSpec.
+func (in *JiraTemplatesSpec) DeepCopy() *JiraTemplatesSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(JiraTemplatesSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *JitRequest) DeepCopyInto(out *JitRequest) {
 	*out = *in
@@ -121,6 +196,11 @@ func (in *JitRequestSpec) DeepCopyInto(out *JitRequestSpec) {
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
+	if in.Subjects != nil {
+		in, out := &in.Subjects, &out.Subjects
+		*out = make([]SubjectSpec, len(*in))
+		copy(*out, *in)
+	}
 	if in.Namespaces != nil {
 		in, out := &in.Namespaces, &out.Namespaces
 		*out = make([]string, len(*in))
@@ -135,6 +215,11 @@ func (in *JitRequestSpec) DeepCopyInto(out *JitRequestSpec) {
 	}
 	in.StartTime.DeepCopyInto(&out.StartTime)
 	in.EndTime.DeepCopyInto(&out.EndTime)
+	if in.Duration != nil {
+		in, out := &in.Duration, &out.Duration
+		*out = new(metav1.Duration)
+		**out = **in
+	}
 	if in.JiraFields != nil {
 		in, out := &in.JiraFields, &out.JiraFields
 		*out = make(map[string]string, len(*in))
@@ -142,6 +227,16 @@ func (in *JitRequestSpec) DeepCopyInto(out *JitRequestSpec) {
 			(*out)[key] = val
 		}
 	}
+	if in.Revocation != nil {
+		in, out := &in.Revocation, &out.Revocation
+		*out = new(RevocationSpec)
+		**out = **in
+	}
+	if in.Extension != nil {
+		in, out := &in.Extension, &out.Extension
+		*out = new(ExtensionSpec)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new JitRequestSpec.
@@ -159,6 +254,33 @@ func (in *JitRequestStatus) DeepCopyInto(out *JitRequestStatus) {
 	*out = *in
 	in.StartTime.DeepCopyInto(&out.StartTime)
 	in.EndTime.DeepCopyInto(&out.EndTime)
+	if in.Extension != nil {
+		in, out := &in.Extension, &out.Extension
+		*out = new(ExtensionStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Approvals != nil {
+		in, out := &in.Approvals, &out.Approvals
+		*out = make([]Approval, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.ApprovedAt != nil {
+		in, out := &in.ApprovedAt, &out.ApprovedAt
+		*out = (*in).DeepCopy()
+	}
+	if in.CompletionTime != nil {
+		in, out := &in.CompletionTime, &out.CompletionTime
+		*out = (*in).DeepCopy()
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]metav1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new JitRequestStatus.
@@ -177,7 +299,7 @@ func (in *JustInTimeConfig) DeepCopyInto(out *JustInTimeConfig) {
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
 	in.Spec.DeepCopyInto(&out.Spec)
-	out.Status = in.Status
+	in.Status.DeepCopyInto(&out.Status)
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new JustInTimeConfig.
@@ -238,6 +360,26 @@ func (in *JustInTimeConfigSpec) DeepCopyInto(out *JustInTimeConfigSpec) {
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
+	if in.AllowedClusterScopedRoles != nil {
+		in, out := &in.AllowedClusterScopedRoles, &out.AllowedClusterScopedRoles
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.JiraWorkflowRejectedStatuses != nil {
+		in, out := &in.JiraWorkflowRejectedStatuses, &out.JiraWorkflowRejectedStatuses
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.LinkedIssueProjects != nil {
+		in, out := &in.LinkedIssueProjects, &out.LinkedIssueProjects
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.ServiceDesk != nil {
+		in, out := &in.ServiceDesk, &out.ServiceDesk
+		*out = new(ServiceDeskSpec)
+		**out = **in
+	}
 	if in.RequiredFields != nil {
 		in, out := &in.RequiredFields, &out.RequiredFields
 		*out = new(RequiredFieldsSpec)
@@ -260,6 +402,38 @@ func (in *JustInTimeConfigSpec) DeepCopyInto(out *JustInTimeConfigSpec) {
 		*out = new(EnvironmentSpec)
 		**out = **in
 	}
+	if in.RetentionPeriod != nil {
+		in, out := &in.RetentionPeriod, &out.RetentionPeriod
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.ApprovalPollInterval != nil {
+		in, out := &in.ApprovalPollInterval, &out.ApprovalPollInterval
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.ApprovalGracePeriod != nil {
+		in, out := &in.ApprovalGracePeriod, &out.ApprovalGracePeriod
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.StartOnApprovalTimeout != nil {
+		in, out := &in.StartOnApprovalTimeout, &out.StartOnApprovalTimeout
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.RolePolicies != nil {
+		in, out := &in.RolePolicies, &out.RolePolicies
+		*out = make(map[string]RolePolicySpec, len(*in))
+		for key, val := range *in {
+			(*out)[key] = *val.DeepCopy()
+		}
+	}
+	if in.Templates != nil {
+		in, out := &in.Templates, &out.Templates
+		*out = new(JiraTemplatesSpec)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new JustInTimeConfigSpec.
@@ -275,6 +449,13 @@ func (in *JustInTimeConfigSpec) DeepCopy() *JustInTimeConfigSpec {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *JustInTimeConfigStatus) DeepCopyInto(out *JustInTimeConfigStatus) {
 	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]metav1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new JustInTimeConfigStatus.
@@ -304,3 +485,88 @@ func (in *RequiredFieldsSpec) DeepCopy() *RequiredFieldsSpec {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RevocationSpec) DeepCopyInto(out *RevocationSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RevocationSpec.
+func (in *RevocationSpec) DeepCopy() *RevocationSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(RevocationSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RolePolicySpec) DeepCopyInto(out *RolePolicySpec) {
+	*out = *in
+	if in.MaxDuration != nil {
+		in, out := &in.MaxDuration, &out.MaxDuration
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.MaxLeadTime != nil {
+		in, out := &in.MaxLeadTime, &out.MaxLeadTime
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.NamespaceAllowedRegexes != nil {
+		in, out := &in.NamespaceAllowedRegexes, &out.NamespaceAllowedRegexes
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.NamespaceSelector != nil {
+		in, out := &in.NamespaceSelector, &out.NamespaceSelector
+		*out = new(metav1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.RequiredJiraFields != nil {
+		in, out := &in.RequiredJiraFields, &out.RequiredJiraFields
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RolePolicySpec.
+func (in *RolePolicySpec) DeepCopy() *RolePolicySpec {
+	if in == nil {
+		return nil
+	}
+	out := new(RolePolicySpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ServiceDeskSpec) DeepCopyInto(out *ServiceDeskSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ServiceDeskSpec.
+func (in *ServiceDeskSpec) DeepCopy() *ServiceDeskSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ServiceDeskSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SubjectSpec) DeepCopyInto(out *SubjectSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SubjectSpec.
+func (in *SubjectSpec) DeepCopy() *SubjectSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(SubjectSpec)
+	in.DeepCopyInto(out)
+	return out
+}
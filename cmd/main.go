@@ -17,12 +17,15 @@ limitations under the License.
 package main
 
 import (
+	"context"
 	"crypto/tls"
 	"flag"
 	"fmt"
+	"net/http"
 	"os"
 	"path/filepath"
 	"strconv"
+	"time"
 
 	// Import all Kubernetes client auth plugins (e.g. Azure, GCP, OIDC, etc.)
 	// to ensure that exec-entrypoint and run can make use of them.
@@ -31,10 +34,12 @@ import (
 	_ "k8s.io/client-go/plugin/pkg/client/auth"
 
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/certwatcher"
+	"sigs.k8s.io/controller-runtime/pkg/event"
 	"sigs.k8s.io/controller-runtime/pkg/healthz"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
 	"sigs.k8s.io/controller-runtime/pkg/metrics/filters"
@@ -45,6 +50,9 @@ import (
 	"jira-jit-rbac-operator/internal/config"
 	"jira-jit-rbac-operator/internal/controller"
 	webhookjustintimev1 "jira-jit-rbac-operator/internal/webhook/v1"
+	"jira-jit-rbac-operator/pkg/credentials"
+	"jira-jit-rbac-operator/pkg/resilience"
+	"jira-jit-rbac-operator/pkg/utils"
 	// +kubebuilder:scaffold:imports
 )
 
@@ -70,6 +78,12 @@ func main() {
 	var enableHTTP2 bool
 	var tlsOpts []func(*tls.Config)
 	var configurationName string
+	var jiraWebhookAddr string
+	var jiraCredentialsDir string
+	var jiraCredentialsSecret string
+	var jiraCredentialsReloadInterval time.Duration
+	jiraResilience := resilience.DefaultOptions()
+	var jiraUserCacheTTL time.Duration
 	flag.StringVar(&metricsAddr, "metrics-bind-address", "0", "The address the metrics endpoint binds to. "+
 		"Use :8443 for HTTPS or :8080 for HTTP, or leave as 0 to disable the metrics service.")
 	flag.StringVar(&probeAddr, "health-probe-bind-address", ":8081", "The address the probe endpoint binds to.")
@@ -93,6 +107,26 @@ func main() {
 		"jira-jit-rbac-operator-default",
 		"name of the JustInTimeConfig",
 	)
+	flag.StringVar(&jiraWebhookAddr, "jira-webhook-bind-address", "0", "The address the Jira webhook endpoint binds to, "+
+		"i.e. :8090. Requires JIRA_WEBHOOK_SECRET, leave as 0 to disable the Jira webhook endpoint.")
+	flag.StringVar(&jiraCredentialsDir, "jira-credentials-dir", "", "The directory of a mounted Secret with the Jira "+
+		"credentials, i.e. base-url and api-token. Takes precedence over --jira-credentials-secret and the JIRA_* env vars.")
+	flag.StringVar(&jiraCredentialsSecret, "jira-credentials-secret", "", "The name of a Secret in the operator "+
+		"namespace with the Jira credentials. Takes precedence over the JIRA_* env vars.")
+	flag.DurationVar(&jiraCredentialsReloadInterval, "jira-credentials-reload-interval", 30*time.Second,
+		"How often to reload the Jira credentials from --jira-credentials-dir or --jira-credentials-secret, 0 to disable.")
+	flag.DurationVar(&jiraResilience.Timeout, "jira-request-timeout", jiraResilience.Timeout,
+		"The timeout of each attempt of a Jira request, 0 to disable.")
+	flag.IntVar(&jiraResilience.MaxRetries, "jira-max-retries", jiraResilience.MaxRetries,
+		"How often to retry a Jira request that was rate limited or failed because Jira is unavailable.")
+	flag.DurationVar(&jiraResilience.MaxBackoff, "jira-max-backoff", jiraResilience.MaxBackoff,
+		"The maximum jittered backoff between retries of a Jira request, a longer Retry-After opens the circuit breaker.")
+	flag.IntVar(&jiraResilience.BreakerThreshold, "jira-circuit-breaker-threshold", jiraResilience.BreakerThreshold,
+		"The consecutive failed Jira requests that open the circuit breaker, 0 to disable.")
+	flag.DurationVar(&jiraResilience.BreakerCooldown, "jira-circuit-breaker-cooldown", jiraResilience.BreakerCooldown,
+		"How long the circuit breaker stays open before probing Jira again, JitRequests are requeued until then.")
+	flag.DurationVar(&jiraUserCacheTTL, "jira-user-cache-ttl", utils.DefaultUserCacheTTL,
+		"How long a Jira user found by email is cached, 0 to disable.")
 	// Read DEBUG_LOG from env var
 	debugLog, logVarErr := strconv.ParseBool(os.Getenv("DEBUG_LOG"))
 	if logVarErr != nil {
@@ -234,37 +268,97 @@ func main() {
 	}
 
 	// Jira client
-	jiraBaseUrl := "http://my-jira-release.default.svc.cluster.local:80"
-	if customJiraBaseUrl := os.Getenv("JIRA_BASE_URL"); customJiraBaseUrl != "" {
-		jiraBaseUrl = customJiraBaseUrl
+	jiraFlavour, err := utils.ParseJiraFlavour(os.Getenv("JIRA_FLAVOUR"))
+	if err != nil {
+		setupLog.Error(err, "unable to start jira client")
+		os.Exit(1)
+	}
+	// Jira Cloud API tokens use basic auth with the account email, Jira Server uses a bearer PAT
+	defaultAuthMode := credentials.AuthModeBearer
+	if jiraFlavour == utils.JiraCloud {
+		defaultAuthMode = credentials.AuthModeBasic
+	}
+
+	// Jira credentials from a mounted Secret, a Secret or the env vars, reloaded without a restart
+	var jiraCredentialsSource credentials.Source
+	switch {
+	case jiraCredentialsDir != "":
+		jiraCredentialsSource = &credentials.FileSource{Dir: jiraCredentialsDir}
+	case jiraCredentialsSecret != "":
+		jiraCredentialsSource = &credentials.SecretSource{
+			Reader: mgr.GetAPIReader(),
+			Key:    types.NamespacedName{Namespace: controller.OperatorNamespace, Name: jiraCredentialsSecret},
+		}
+	default:
+		jiraBaseUrl := "http://my-jira-release.default.svc.cluster.local:80"
+		if customJiraBaseUrl := os.Getenv("JIRA_BASE_URL"); customJiraBaseUrl != "" {
+			jiraBaseUrl = customJiraBaseUrl
+		}
+		jiraCredentialsSource = credentials.StaticSource{
+			credentials.BaseURLKey:  []byte(jiraBaseUrl),
+			credentials.APITokenKey: []byte(os.Getenv("JIRA_API_TOKEN")),
+			credentials.UsernameKey: []byte(os.Getenv("JIRA_EMAIL")),
+		}
+		jiraCredentialsReloadInterval = 0
+	}
+	jiraCredentials := credentials.NewReloader(jiraCredentialsSource, defaultAuthMode, jiraCredentialsReloadInterval)
+	jiraCredentials.OnChange = utils.ClearUserCache
+	if _, err = jiraCredentials.Reload(context.Background()); err != nil {
+		setupLog.Error(err, "unable to load jira credentials", "source", jiraCredentialsSource.String())
+		os.Exit(1)
 	}
-	jiraPassword := os.Getenv("JIRA_API_TOKEN")
-	jiraClient, err := jira.New(nil, jiraBaseUrl)
+	if err = mgr.Add(jiraCredentials); err != nil {
+		setupLog.Error(err, "unable to add jira credentials reloader to manager")
+		os.Exit(1)
+	}
+	utils.SetUserCacheTTL(jiraUserCacheTTL)
+	// shared by the controllers and webhook, so they all back off while Jira is unavailable
+	jiraHTTPClient := &http.Client{Transport: resilience.NewTransport(jiraCredentials.Transport, jiraResilience)}
+	jiraClient, err := jira.New(jiraHTTPClient, jiraCredentials.Transport.Credentials().BaseURL.String())
 	if err != nil {
 		setupLog.Error(err, "unable to start jira client")
 		os.Exit(1)
 	}
-	jiraClient.Auth.SetBearerToken(jiraPassword)
+	setupLog.Info("Jira client configured", "source", jiraCredentialsSource.String(),
+		"baseUrl", jiraCredentials.Transport.Credentials().BaseURL.String(), "flavour", jiraFlavour)
+
+	// Jira webhook receiver to reconcile JitRequests as soon as their Jira ticket is updated
+	var jiraEvents chan event.GenericEvent
+	if jiraWebhookAddr != "0" {
+		jiraEvents = make(chan event.GenericEvent, controller.JiraEventsBufferSize)
+		if err = controller.SetupJiraWebhookWithManager(mgr, &controller.JiraWebhookReceiver{
+			Client:      mgr.GetClient(),
+			BindAddress: jiraWebhookAddr,
+			Secret:      os.Getenv("JIRA_WEBHOOK_SECRET"),
+			Events:      jiraEvents,
+		}); err != nil {
+			setupLog.Error(err, "unable to create jira webhook receiver")
+			os.Exit(1)
+		}
+	}
 
 	if err = (&controller.JitRequestReconciler{
-		JiraClient: jiraClient,
-		Client:     mgr.GetClient(),
-		Scheme:     mgr.GetScheme(),
-		Recorder:   mgr.GetEventRecorderFor("githubapp-controller"),
+		JiraClient:  jiraClient,
+		JiraFlavour: jiraFlavour,
+		Client:      mgr.GetClient(),
+		Scheme:      mgr.GetScheme(),
+		Recorder:    mgr.GetEventRecorderFor("githubapp-controller"),
+		JiraEvents:  jiraEvents,
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "JitRequest")
 		os.Exit(1)
 	}
 	if err = (&config.JustInTimeConfigReconciler{
-		Client: mgr.GetClient(),
-		Scheme: mgr.GetScheme(),
+		Client:     mgr.GetClient(),
+		Scheme:     mgr.GetScheme(),
+		JiraClient: jiraClient,
 	}).SetupWithManager(mgr, configurationName, configCacheFilePath); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "JustInTimeConfig")
 		os.Exit(1)
 	}
 	// nolint:goconst
 	if os.Getenv("ENABLE_WEBHOOKS") == "true" {
-		if err = webhookjustintimev1.SetupJitRequestWebhookWithManager(mgr, jiraClient); err != nil {
+		if err = webhookjustintimev1.SetupJitRequestWebhookWithManager(mgr, jiraClient, jiraFlavour); err != nil {
 			setupLog.Error(err, "unable to create webhook", "webhook", "JitRequest")
 			os.Exit(1)
 		}
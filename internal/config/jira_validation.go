@@ -0,0 +1,406 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"sort"
+	"strings"
+
+	jira "github.com/ctreminiom/go-atlassian/v2/jira/v2"
+	"github.com/ctreminiom/go-atlassian/v2/pkg/infra/models"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	justintimev1 "jira-jit-rbac-operator/api/v1"
+	"jira-jit-rbac-operator/pkg/configuration"
+)
+
+// Condition reasons for validating the config against Jira
+const (
+	ReasonValid             = "Valid"
+	ReasonInvalid           = "Invalid"
+	ReasonJiraUnavailable   = "JiraUnavailable"
+	ReasonNotVerifiable     = "NotVerifiable"
+	ReasonIssueTypeNotFound = "IssueTypeNotFound"
+)
+
+// jiraFieldSchemaTypes are the Jira field schema types accepted for each custom field type, array fields include the item type
+var jiraFieldSchemaTypes = map[string][]string{
+	justintimev1.FieldTypeText:            {"string"},
+	justintimev1.FieldTypeDate:            {"date"},
+	justintimev1.FieldTypeDateTime:        {"datetime"},
+	justintimev1.FieldTypeSelect:          {"option"},
+	justintimev1.FieldTypeMultiSelect:     {"array<option>"},
+	justintimev1.FieldTypeUser:            {"user"},
+	justintimev1.FieldTypeMultiUser:       {"array<user>"},
+	justintimev1.FieldTypeNumber:          {"number"},
+	justintimev1.FieldTypeLabels:          {"array<string>"},
+	justintimev1.FieldTypeCascadingSelect: {"option-with-child"},
+	justintimev1.FieldTypeURL:             {"string"},
+	justintimev1.FieldTypeGroup:           {"group"},
+	justintimev1.FieldTypeMultiGroup:      {"array<group>"},
+}
+
+// jiraIssueType is an issue type of a project from createmeta
+type jiraIssueType struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+// jiraField is a field from the field or createmeta APIs
+type jiraField struct {
+	ID      string `json:"id"`
+	FieldID string `json:"fieldId"`
+	Name    string `json:"name"`
+	Schema  struct {
+		Type  string `json:"type"`
+		Items string `json:"items"`
+	} `json:"schema"`
+}
+
+// schemaType returns the schema type of a field, with the item type for array fields
+func (f *jiraField) schemaType() string {
+	if f.Schema.Type == "array" {
+		return fmt.Sprintf("array<%s>", f.Schema.Items)
+	}
+	return f.Schema.Type
+}
+
+// jiraProjectStatuses are the statuses of an issue type in a project
+type jiraProjectStatuses struct {
+	Name     string `json:"name"`
+	Statuses []struct {
+		Name string `json:"name"`
+	} `json:"statuses"`
+}
+
+// jiraConfigValidator validates a JustInTimeConfig against the fields, statuses and workflow of the Jira project
+type jiraConfigValidator struct {
+	jiraClient *jira.Client
+	cfg        configuration.Configuration
+	issueType  *jiraIssueType
+}
+
+// validateJiraConfig returns the conditions from validating the config against Jira
+func validateJiraConfig(ctx context.Context, jiraClient *jira.Client, cfg configuration.Configuration, generation int64) []metav1.Condition {
+	v := &jiraConfigValidator{jiraClient: jiraClient, cfg: cfg}
+
+	condition := func(conditionType string, status metav1.ConditionStatus, reason, message string) metav1.Condition {
+		return metav1.Condition{
+			Type:               conditionType,
+			Status:             status,
+			ObservedGeneration: generation,
+			Reason:             reason,
+			Message:            message,
+		}
+	}
+
+	// everything depends on the issue type of the project
+	if err := v.getIssueType(ctx); err != nil {
+		// the config is only invalid if the issue type does not exist, not while Jira is unavailable
+		status, reason := metav1.ConditionUnknown, ReasonJiraUnavailable
+		if errors.Is(err, errIssueTypeNotFound) {
+			status, reason = metav1.ConditionFalse, ReasonIssueTypeNotFound
+		}
+		message := fmt.Sprintf("failed to get issue type '%s' of project '%s': %s", cfg.JiraIssueType(), cfg.JiraProject(), err)
+		return []metav1.Condition{
+			condition(justintimev1.ConditionJiraFieldsValid, status, reason, message),
+			condition(justintimev1.ConditionJiraStatusesValid, status, reason, message),
+			condition(justintimev1.ConditionJiraTransitionsValid, status, reason, message),
+		}
+	}
+
+	conditions := make([]metav1.Condition, 0, 3)
+	for _, check := range []struct {
+		conditionType string
+		validate      func(context.Context) ([]string, error)
+		valid         string
+	}{
+		{justintimev1.ConditionJiraFieldsValid, v.validateFields, "All required and custom fields exist on the create screen"},
+		{justintimev1.ConditionJiraStatusesValid, v.validateStatuses, "The workflow statuses exist in the project"},
+		{justintimev1.ConditionJiraTransitionsValid, v.validateTransitions, "The workflow transitions exist in the workflow"},
+	} {
+		problems, err := check.validate(ctx)
+		switch {
+		case errors.Is(err, errNotVerifiable):
+			conditions = append(conditions, condition(check.conditionType, metav1.ConditionUnknown, ReasonNotVerifiable,
+				"Cannot be verified with this Jira, transitions are resolved when a ticket is transitioned"))
+		case err != nil:
+			conditions = append(conditions, condition(check.conditionType, metav1.ConditionUnknown, ReasonJiraUnavailable, err.Error()))
+		case len(problems) > 0:
+			conditions = append(conditions, condition(check.conditionType, metav1.ConditionFalse, ReasonInvalid, strings.Join(problems, "; ")))
+		default:
+			conditions = append(conditions, condition(check.conditionType, metav1.ConditionTrue, ReasonValid, check.valid))
+		}
+	}
+	return conditions
+}
+
+var (
+	errIssueTypeNotFound = errors.New("issue type not found")
+	errNotVerifiable     = errors.New("not verifiable")
+)
+
+// getIssueType finds the configured issue type in the createmeta of the project
+func (v *jiraConfigValidator) getIssueType(ctx context.Context) error {
+	// Jira Cloud returns issueTypes, Jira Server/Data Center returns values
+	var response struct {
+		IssueTypes []jiraIssueType `json:"issueTypes"`
+		Values     []jiraIssueType `json:"values"`
+	}
+	endpoint := fmt.Sprintf("rest/api/2/issue/createmeta/%s/issuetypes", url.PathEscape(v.cfg.JiraProject()))
+	if err := v.get(ctx, endpoint, &response); err != nil {
+		return err
+	}
+
+	for _, issueType := range append(response.IssueTypes, response.Values...) {
+		if strings.EqualFold(issueType.Name, v.cfg.JiraIssueType()) {
+			v.issueType = &issueType
+			return nil
+		}
+	}
+	return errIssueTypeNotFound
+}
+
+// validateFields checks every required and custom field exists, is on the create screen and has a matching type
+func (v *jiraConfigValidator) validateFields(ctx context.Context) ([]string, error) {
+	var allFields []jiraField
+	if err := v.get(ctx, "rest/api/2/field", &allFields); err != nil {
+		return nil, err
+	}
+	known := map[string]bool{}
+	for _, field := range allFields {
+		known[field.ID] = true
+	}
+
+	screen, onScreen, err := v.getScreenFields(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	fields := map[string]justintimev1.CustomFieldSettings{}
+	for name, settings := range v.cfg.CustomFields() {
+		fields[name] = settings
+	}
+	if requiredFields := v.cfg.RequiredFields(); requiredFields != nil {
+		fields["StartTime"] = requiredFields.StartTime
+		fields["EndTime"] = requiredFields.EndTime
+		fields["ClusterRole"] = requiredFields.ClusterRole
+	}
+
+	// sorted for a stable condition message
+	names := make([]string, 0, len(fields))
+	for name := range fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var problems []string
+	if _, ok := onScreen["summary"]; !ok && v.cfg.ServiceDesk() != nil {
+		problems = append(problems, fmt.Sprintf("field 'summary' is not on %s", screen))
+	}
+	for _, name := range names {
+		settings := fields[name]
+		schemaTypes, ok := jiraFieldSchemaTypes[settings.Type]
+		if !ok {
+			problems = append(problems, fmt.Sprintf("field '%s' has unknown type '%s'", name, settings.Type))
+			continue
+		}
+		if !known[settings.JiraCustomField] {
+			problems = append(problems, fmt.Sprintf("field '%s' jiraCustomField '%s' does not exist", name, settings.JiraCustomField))
+			continue
+		}
+		field, ok := onScreen[settings.JiraCustomField]
+		if !ok {
+			problems = append(problems, fmt.Sprintf("field '%s' jiraCustomField '%s' is not on %s", name, settings.JiraCustomField, screen))
+			continue
+		}
+		if !slices.Contains(schemaTypes, field.schemaType()) {
+			problems = append(problems, fmt.Sprintf("field '%s' has type '%s' but jiraCustomField '%s' is a '%s' field", name, settings.Type, settings.JiraCustomField, field.schemaType()))
+		}
+	}
+	return problems, nil
+}
+
+// getScreenFields returns the fields tickets are created with, keyed by field ID. These are the fields of the request type
+// for customer requests, or the create screen of the issue type.
+func (v *jiraConfigValidator) getScreenFields(ctx context.Context) (string, map[string]jiraField, error) {
+	onScreen := map[string]jiraField{}
+
+	if serviceDesk := v.cfg.ServiceDesk(); serviceDesk != nil {
+		var requestType struct {
+			RequestTypeFields []struct {
+				FieldID    string `json:"fieldId"`
+				Name       string `json:"name"`
+				JiraSchema struct {
+					Type  string `json:"type"`
+					Items string `json:"items"`
+				} `json:"jiraSchema"`
+			} `json:"requestTypeFields"`
+		}
+		endpoint := fmt.Sprintf("rest/servicedeskapi/servicedesk/%s/requesttype/%s/field", url.PathEscape(serviceDesk.ServiceDeskID), url.PathEscape(serviceDesk.RequestTypeID))
+		if err := v.get(ctx, endpoint, &requestType); err != nil {
+			return "", nil, err
+		}
+		for _, requestTypeField := range requestType.RequestTypeFields {
+			field := jiraField{ID: requestTypeField.FieldID, FieldID: requestTypeField.FieldID, Name: requestTypeField.Name}
+			field.Schema.Type = requestTypeField.JiraSchema.Type
+			field.Schema.Items = requestTypeField.JiraSchema.Items
+			onScreen[field.FieldID] = field
+		}
+		return fmt.Sprintf("request type '%s' of service desk '%s'", serviceDesk.RequestTypeID, serviceDesk.ServiceDeskID), onScreen, nil
+	}
+
+	// Jira Cloud returns fields, Jira Server/Data Center returns values
+	var createMeta struct {
+		Fields []jiraField `json:"fields"`
+		Values []jiraField `json:"values"`
+	}
+	endpoint := fmt.Sprintf("rest/api/2/issue/createmeta/%s/issuetypes/%s?maxResults=1000", url.PathEscape(v.cfg.JiraProject()), v.issueType.ID)
+	if err := v.get(ctx, endpoint, &createMeta); err != nil {
+		return "", nil, err
+	}
+	for _, field := range append(createMeta.Fields, createMeta.Values...) {
+		onScreen[field.FieldID] = field
+	}
+	return fmt.Sprintf("the create screen of '%s'", v.issueType.Name), onScreen, nil
+}
+
+// validateStatuses checks the approved and rejected statuses exist for the issue type of the project
+func (v *jiraConfigValidator) validateStatuses(ctx context.Context) ([]string, error) {
+	var projectStatuses []jiraProjectStatuses
+	endpoint := fmt.Sprintf("rest/api/2/project/%s/statuses", url.PathEscape(v.cfg.JiraProject()))
+	if err := v.get(ctx, endpoint, &projectStatuses); err != nil {
+		return nil, err
+	}
+
+	statuses := map[string]bool{}
+	for _, issueType := range projectStatuses {
+		if !strings.EqualFold(issueType.Name, v.issueType.Name) {
+			continue
+		}
+		for _, status := range issueType.Statuses {
+			statuses[strings.ToLower(status.Name)] = true
+		}
+	}
+
+	var problems []string
+	configured := append([]string{v.cfg.JiraWorkflowApproveStatus(), v.cfg.JiraWorkflowRejectedStatus()}, v.cfg.JiraWorkflowRejectedStatuses()...)
+	for _, status := range configured {
+		if status != "" && !statuses[strings.ToLower(status)] {
+			problems = append(problems, fmt.Sprintf("status '%s' does not exist for '%s'", status, v.issueType.Name))
+		}
+	}
+	return problems, nil
+}
+
+// validateTransitions checks the configured transitions exist by ID or name in the workflow of the issue type,
+// the workflow is only available from the APIs of Jira Cloud
+func (v *jiraConfigValidator) validateTransitions(ctx context.Context) ([]string, error) {
+	var project struct {
+		ID string `json:"id"`
+	}
+	if err := v.get(ctx, fmt.Sprintf("rest/api/2/project/%s", url.PathEscape(v.cfg.JiraProject())), &project); err != nil {
+		return nil, err
+	}
+
+	var schemes struct {
+		Values []struct {
+			WorkflowScheme struct {
+				DefaultWorkflow   string            `json:"defaultWorkflow"`
+				IssueTypeMappings map[string]string `json:"issueTypeMappings"`
+			} `json:"workflowScheme"`
+		} `json:"values"`
+	}
+	if err := v.get(ctx, fmt.Sprintf("rest/api/2/workflowscheme/project?projectId=%s", project.ID), &schemes); err != nil {
+		// Jira Server/Data Center has no workflow scheme API
+		if errors.Is(err, models.ErrNotFound) {
+			return nil, errNotVerifiable
+		}
+		return nil, err
+	}
+	if len(schemes.Values) == 0 {
+		return nil, errNotVerifiable
+	}
+	scheme := schemes.Values[0].WorkflowScheme
+	workflowName := scheme.DefaultWorkflow
+	if mapped, ok := scheme.IssueTypeMappings[v.issueType.ID]; ok {
+		workflowName = mapped
+	}
+
+	var workflows struct {
+		Values []struct {
+			Transitions []struct {
+				ID   string `json:"id"`
+				Name string `json:"name"`
+			} `json:"transitions"`
+		} `json:"values"`
+	}
+	endpoint := fmt.Sprintf("rest/api/2/workflow/search?workflowName=%s&expand=transitions", url.QueryEscape(workflowName))
+	if err := v.get(ctx, endpoint, &workflows); err != nil {
+		if errors.Is(err, models.ErrNotFound) {
+			return nil, errNotVerifiable
+		}
+		return nil, err
+	}
+	if len(workflows.Values) == 0 {
+		return []string{fmt.Sprintf("workflow '%s' not found", workflowName)}, nil
+	}
+
+	transitions := map[string]bool{}
+	for _, transition := range workflows.Values[0].Transitions {
+		transitions[transition.ID] = true
+		transitions[strings.ToLower(transition.Name)] = true
+	}
+
+	var problems []string
+	for _, configured := range []struct {
+		key, transition string
+	}{
+		{"rejectedTransitionID", v.cfg.RejectedTransitionID()},
+		{"completedTransitionID", v.cfg.CompletedTransitionID()},
+		{"revokedTransitionID", v.cfg.RevokedTransitionID()},
+		{"expiredTransitionID", v.cfg.ExpiredTransitionID()},
+		{"extensionTransitionID", v.cfg.ExtensionTransitionID()},
+	} {
+		if configured.transition != "" && !transitions[configured.transition] && !transitions[strings.ToLower(configured.transition)] {
+			problems = append(problems, fmt.Sprintf("%s '%s' does not exist in workflow '%s'", configured.key, configured.transition, workflowName))
+		}
+	}
+	return problems, nil
+}
+
+// get calls a Jira endpoint and decodes the response
+func (v *jiraConfigValidator) get(ctx context.Context, endpoint string, result interface{}) error {
+	request, err := v.jiraClient.NewRequest(ctx, http.MethodGet, endpoint, "", nil)
+	if err != nil {
+		return err
+	}
+	response, err := v.jiraClient.Call(request, result)
+	if err != nil {
+		if response != nil {
+			return fmt.Errorf("%s: %w, response: %s", endpoint, err, response.Bytes.String())
+		}
+		return fmt.Errorf("%s: %w", endpoint, err)
+	}
+	return nil
+}
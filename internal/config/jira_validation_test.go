@@ -0,0 +1,190 @@
+package config
+
+import (
+	"net/http"
+	"net/http/httptest"
+
+	jira "github.com/ctreminiom/go-atlassian/v2/jira/v2"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	justintimev1 "jira-jit-rbac-operator/api/v1"
+	"jira-jit-rbac-operator/pkg/configuration"
+)
+
+var _ = Describe("validateJiraConfig", Label("unit", "jira-validation"), func() {
+
+	var server *httptest.Server
+	var jiraClient *jira.Client
+	var jitConfig *justintimev1.JustInTimeConfig
+	var cloud bool
+
+	// jira stub for the createmeta, request type field, field, project and workflow APIs
+	BeforeEach(func() {
+		cloud = true
+		mux := http.NewServeMux()
+		respond := func(path, body string) {
+			mux.HandleFunc(path, func(w http.ResponseWriter, r *http.Request) {
+				_, _ = w.Write([]byte(body))
+			})
+		}
+		respond("/rest/api/2/issue/createmeta/IAM/issuetypes", `{"values":[{"id":"10001","name":"Access Request"}]}`)
+		respond("/rest/api/2/issue/createmeta/IAM/issuetypes/10001", `{"values":[
+			{"fieldId":"customfield_10114","schema":{"type":"user"}},
+			{"fieldId":"customfield_10116","schema":{"type":"string"}},
+			{"fieldId":"customfield_10117","schema":{"type":"option"}},
+			{"fieldId":"customfield_10118","schema":{"type":"datetime"}},
+			{"fieldId":"customfield_10119","schema":{"type":"datetime"}}
+		]}`)
+		respond("/rest/servicedeskapi/servicedesk/1/requesttype/25/field", `{"requestTypeFields":[
+			{"fieldId":"summary","jiraSchema":{"type":"string"}},
+			{"fieldId":"customfield_10114","jiraSchema":{"type":"user"}},
+			{"fieldId":"customfield_10117","jiraSchema":{"type":"option"}},
+			{"fieldId":"customfield_10118","jiraSchema":{"type":"datetime"}},
+			{"fieldId":"customfield_10119","jiraSchema":{"type":"datetime"}}
+		]}`)
+		respond("/rest/api/2/field", `[
+			{"id":"customfield_10114"},{"id":"customfield_10115"},{"id":"customfield_10116"},
+			{"id":"customfield_10117"},{"id":"customfield_10118"},{"id":"customfield_10119"}
+		]`)
+		respond("/rest/api/2/project/IAM/statuses", `[{"name":"Access Request","statuses":[{"name":"Open"},{"name":"Approved"},{"name":"Rejected"}]}]`)
+		respond("/rest/api/2/project/IAM", `{"id":"10000"}`)
+		mux.HandleFunc("/rest/api/2/workflowscheme/project", func(w http.ResponseWriter, r *http.Request) {
+			if !cloud {
+				http.NotFound(w, r)
+				return
+			}
+			_, _ = w.Write([]byte(`{"values":[{"workflowScheme":{"defaultWorkflow":"JIT Workflow"}}]}`))
+		})
+		respond("/rest/api/2/workflow/search", `{"values":[{"transitions":[
+			{"id":"21","name":"Reject"},{"id":"41","name":"Complete"}
+		]}]}`)
+		server = httptest.NewServer(mux)
+
+		var err error
+		jiraClient, err = jira.New(nil, server.URL)
+		Expect(err).NotTo(HaveOccurred())
+
+		jitConfig = &justintimev1.JustInTimeConfig{
+			ObjectMeta: metav1.ObjectMeta{Name: "jira-validation-test", Generation: 2},
+			Spec: justintimev1.JustInTimeConfigSpec{
+				JiraProject:                "IAM",
+				JiraIssueType:              "Access Request",
+				JiraWorkflowApproveStatus:  "Approved",
+				JiraWorkflowRejectedStatus: "Rejected",
+				RejectedTransitionID:       "21",
+				CompletedTransitionID:      "complete",
+				CustomFields: map[string]justintimev1.CustomFieldSettings{
+					"Approver":      {Type: "user", JiraCustomField: "customfield_10114"},
+					"Justification": {Type: "text", JiraCustomField: "customfield_10116"},
+				},
+				RequiredFields: &justintimev1.RequiredFieldsSpec{
+					ClusterRole: justintimev1.CustomFieldSettings{Type: "select", JiraCustomField: "customfield_10117"},
+					StartTime:   justintimev1.CustomFieldSettings{Type: "datetime", JiraCustomField: "customfield_10118"},
+					EndTime:     justintimev1.CustomFieldSettings{Type: "datetime", JiraCustomField: "customfield_10119"},
+				},
+			},
+		}
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	validate := func() []metav1.Condition {
+		k8sClient := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(jitConfig).Build()
+		cfg := configuration.NewJitRbacOperatorConfiguration(ctx, k8sClient, jitConfig.Name)
+		return validateJiraConfig(ctx, jiraClient, cfg, jitConfig.Generation)
+	}
+
+	It("should set all conditions true for a valid config", func() {
+		conditions := validate()
+		for _, conditionType := range []string{justintimev1.ConditionJiraFieldsValid, justintimev1.ConditionJiraStatusesValid, justintimev1.ConditionJiraTransitionsValid} {
+			condition := meta.FindStatusCondition(conditions, conditionType)
+			Expect(condition).NotTo(BeNil())
+			Expect(condition.Status).To(Equal(metav1.ConditionTrue), condition.Message)
+			Expect(condition.ObservedGeneration).To(Equal(int64(2)))
+		}
+	})
+
+	It("should report unknown, missing and mismatched fields", func() {
+		jitConfig.Spec.CustomFields["Approver"] = justintimev1.CustomFieldSettings{Type: "usr", JiraCustomField: "customfield_10114"}
+		jitConfig.Spec.CustomFields["ProductOwner"] = justintimev1.CustomFieldSettings{Type: "user", JiraCustomField: "customfield_10115"}
+		jitConfig.Spec.CustomFields["Typo"] = justintimev1.CustomFieldSettings{Type: "text", JiraCustomField: "customfield_99999"}
+		jitConfig.Spec.RequiredFields.ClusterRole.Type = "text"
+
+		condition := meta.FindStatusCondition(validate(), justintimev1.ConditionJiraFieldsValid)
+		Expect(condition.Status).To(Equal(metav1.ConditionFalse))
+		Expect(condition.Reason).To(Equal(ReasonInvalid))
+		Expect(condition.Message).To(Equal("field 'Approver' has unknown type 'usr'; " +
+			"field 'ClusterRole' has type 'text' but jiraCustomField 'customfield_10117' is a 'option' field; " +
+			"field 'ProductOwner' jiraCustomField 'customfield_10115' is not on the create screen of 'Access Request'; " +
+			"field 'Typo' jiraCustomField 'customfield_99999' does not exist"))
+	})
+
+	It("should report fields missing from the request type of a service desk", func() {
+		jitConfig.Spec.ServiceDesk = &justintimev1.ServiceDeskSpec{ServiceDeskID: "1", RequestTypeID: "25"}
+
+		condition := meta.FindStatusCondition(validate(), justintimev1.ConditionJiraFieldsValid)
+		Expect(condition.Status).To(Equal(metav1.ConditionFalse))
+		Expect(condition.Message).To(Equal("field 'Justification' jiraCustomField 'customfield_10116' is not on request type '25' of service desk '1'"))
+	})
+
+	It("should report a missing status", func() {
+		jitConfig.Spec.JiraWorkflowApproveStatus = "Done"
+
+		condition := meta.FindStatusCondition(validate(), justintimev1.ConditionJiraStatusesValid)
+		Expect(condition.Status).To(Equal(metav1.ConditionFalse))
+		Expect(condition.Message).To(Equal("status 'Done' does not exist for 'Access Request'"))
+	})
+
+	It("should report a missing rejected status", func() {
+		jitConfig.Spec.JiraWorkflowRejectedStatuses = []string{"rejected", "Declined"}
+
+		condition := meta.FindStatusCondition(validate(), justintimev1.ConditionJiraStatusesValid)
+		Expect(condition.Status).To(Equal(metav1.ConditionFalse))
+		Expect(condition.Message).To(Equal("status 'Declined' does not exist for 'Access Request'"))
+	})
+
+	It("should report a missing transition", func() {
+		jitConfig.Spec.RevokedTransitionID = "Revoke"
+
+		condition := meta.FindStatusCondition(validate(), justintimev1.ConditionJiraTransitionsValid)
+		Expect(condition.Status).To(Equal(metav1.ConditionFalse))
+		Expect(condition.Message).To(Equal("revokedTransitionID 'Revoke' does not exist in workflow 'JIT Workflow'"))
+	})
+
+	It("should report transitions as unknown if the workflow API is not available", func() {
+		cloud = false
+
+		condition := meta.FindStatusCondition(validate(), justintimev1.ConditionJiraTransitionsValid)
+		Expect(condition.Status).To(Equal(metav1.ConditionUnknown))
+		Expect(condition.Reason).To(Equal(ReasonNotVerifiable))
+	})
+
+	It("should set all conditions unknown if Jira is unavailable", func() {
+		server.Close()
+
+		conditions := validate()
+		Expect(conditions).To(HaveLen(3))
+		for _, condition := range conditions {
+			Expect(condition.Status).To(Equal(metav1.ConditionUnknown))
+			Expect(condition.Reason).To(Equal(ReasonJiraUnavailable))
+		}
+	})
+
+	It("should set all conditions false if the issue type does not exist", func() {
+		jitConfig.Spec.JiraIssueType = "Task"
+
+		conditions := validate()
+		Expect(conditions).To(HaveLen(3))
+		for _, condition := range conditions {
+			Expect(condition.Status).To(Equal(metav1.ConditionFalse))
+			Expect(condition.Reason).To(Equal(ReasonIssueTypeNotFound))
+		}
+	})
+})
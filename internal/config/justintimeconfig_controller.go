@@ -22,9 +22,17 @@ import (
 	"fmt"
 	"os"
 	"regexp"
+	"slices"
+	"strings"
 	"sync"
+	"time"
 
+	jira "github.com/ctreminiom/go-atlassian/v2/jira/v2"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -33,8 +41,12 @@ import (
 
 	justintimev1 "jira-jit-rbac-operator/api/v1"
 	"jira-jit-rbac-operator/pkg/configuration"
+	"jira-jit-rbac-operator/pkg/templates"
 )
 
+// JiraValidationInterval is how often the config is validated against Jira, to pick up changes made in Jira
+const JiraValidationInterval = time.Hour
+
 var (
 	ConfigCacheFilePath   string
 	ConfigFile            = "config.json"
@@ -46,6 +58,8 @@ var (
 type JustInTimeConfigReconciler struct {
 	client.Client
 	Scheme *runtime.Scheme
+	// Optional Jira client to validate the config against Jira, reported as status conditions
+	JiraClient *jira.Client
 }
 
 // Reconcile is the main reconcile loop for a JustInTimeConfig
@@ -58,16 +72,34 @@ func (c *JustInTimeConfigReconciler) Reconcile(ctx context.Context, req ctrl.Req
 		"JustInTimeConfig",
 		"allowed cluster roles",
 		cfg.AllowedClusterRoles(),
+		"allowed cluster scoped roles",
+		cfg.AllowedClusterScopedRoles(),
 		"jira workflow approved name",
 		cfg.JiraWorkflowApproveStatus(),
+		"jira workflow rejected name",
+		cfg.JiraWorkflowRejectedStatus(),
+		"jira workflow rejected names",
+		cfg.JiraWorkflowRejectedStatuses(),
 		"jira reject transition id",
 		cfg.RejectedTransitionID(),
 		"jira project",
 		cfg.JiraProject(),
 		"jira issue type",
 		cfg.JiraIssueType(),
+		"jira linked issue projects",
+		cfg.LinkedIssueProjects(),
+		"jira issue link type",
+		cfg.IssueLinkType(),
+		"jira service desk",
+		cfg.ServiceDesk(),
 		"jira approve transition id",
 		cfg.CompletedTransitionID(),
+		"jira revoke transition id",
+		cfg.RevokedTransitionID(),
+		"jira expire transition id",
+		cfg.ExpiredTransitionID(),
+		"jira extension transition id",
+		cfg.ExtensionTransitionID(),
 		"jira custom fields",
 		cfg.CustomFields(),
 		"jira required fields",
@@ -82,17 +114,38 @@ func (c *JustInTimeConfigReconciler) Reconcile(ctx context.Context, req ctrl.Req
 		cfg.NamespaceAllowedRegex(),
 		"self approval enabled",
 		cfg.SelfApprovalEnabled(),
+		"retention period",
+		cfg.RetentionPeriod(),
+		"approval poll interval",
+		cfg.ApprovalPollInterval(),
+		"approval grace period",
+		cfg.ApprovalGracePeriod(),
+		"start on approval timeout",
+		cfg.StartOnApprovalTimeout(),
+		"approval mode",
+		cfg.ApprovalMode(),
+		"role policies",
+		cfg.RolePolicies(),
+		"timezone",
+		cfg.Timezone(),
+		"templates",
+		cfg.Templates(),
 	)
 
-	// validate regex and set for global use
-	namespaceRegex := cfg.NamespaceAllowedRegex()
-	if namespaceRegex != "" {
-		var err error
-		NamespaceAllowedRegex, err = regexp.Compile(namespaceRegex)
-		if err != nil {
-			l.Error(err, "regex is invalid for namespaceAllowedRegex")
-			return ctrl.Result{}, err
-		}
+	// validate the config and report it as a condition, an invalid config is not loaded
+	validationErr := validateConfig(cfg)
+	if err := c.updateConfigCondition(ctx, req.Name, validationErr); err != nil {
+		l.Error(err, "failed to update status conditions")
+		return ctrl.Result{}, err
+	}
+	if validationErr != nil {
+		l.Error(validationErr, "JustInTimeConfig is invalid")
+		return ctrl.Result{}, validationErr
+	}
+
+	// set regex for global use
+	if namespaceRegex := cfg.NamespaceAllowedRegex(); namespaceRegex != "" {
+		NamespaceAllowedRegex = regexp.MustCompile(namespaceRegex)
 	}
 
 	// cache config to file
@@ -101,11 +154,156 @@ func (c *JustInTimeConfigReconciler) Reconcile(ctx context.Context, req ctrl.Req
 		return ctrl.Result{}, err
 	}
 
+	// validate against jira and report as conditions
+	if c.JiraClient != nil {
+		if err := c.updateJiraConditions(ctx, req.Name, cfg); err != nil {
+			l.Error(err, "failed to update status conditions")
+			return ctrl.Result{}, err
+		}
+		l.Info("JustInTimeConfig reconciliation finished", "request.name", req.Name)
+		return ctrl.Result{RequeueAfter: JiraValidationInterval}, nil
+	}
+
 	l.Info("JustInTimeConfig reconciliation finished", "request.name", req.Name)
 
 	return ctrl.Result{}, nil
 }
 
+// validateConfig checks the regexes, approvals, field types, timezone and templates of a config
+func validateConfig(cfg configuration.Configuration) error {
+	if namespaceRegex := cfg.NamespaceAllowedRegex(); namespaceRegex != "" {
+		if _, err := regexp.Compile(namespaceRegex); err != nil {
+			return fmt.Errorf("regex is invalid for namespaceAllowedRegex: %w", err)
+		}
+	}
+
+	// role policy regexes are compiled when a JitRequest is validated
+	for clusterRole, policy := range cfg.RolePolicies() {
+		for _, namespaceRegex := range policy.NamespaceAllowedRegexes {
+			if _, err := regexp.Compile(namespaceRegex); err != nil {
+				return fmt.Errorf("regex is invalid for rolePolicies %s namespaceAllowedRegexes: %w", clusterRole, err)
+			}
+		}
+	}
+
+	// only approvers are counted so the approval mode must record them
+	if err := validateRequiredApprovals(cfg.ApprovalMode(), cfg.RolePolicies()); err != nil {
+		return err
+	}
+
+	// unknown field types are rejected before a ticket is created
+	if err := validateFieldTypes(cfg.CustomFields(), cfg.RequiredFields()); err != nil {
+		return err
+	}
+
+	// the timezone is loaded when a Jira ticket is created
+	if timezone := cfg.Timezone(); timezone != "" {
+		if _, err := time.LoadLocation(timezone); err != nil {
+			return fmt.Errorf("timezone '%s' is invalid: %w", timezone, err)
+		}
+	}
+
+	// templates are rendered when a Jira ticket is created or commented on
+	return templates.Validate(cfg.Templates())
+}
+
+// validateFieldTypes checks the custom and required fields have a supported type,
+// the start and end time must be a text, date or datetime field and the cluster role a text or select field
+func validateFieldTypes(customFields map[string]justintimev1.CustomFieldSettings, requiredFields *justintimev1.RequiredFieldsSpec) error {
+	for fieldName, settings := range customFields {
+		if !slices.Contains(justintimev1.FieldTypes, settings.Type) {
+			return fmt.Errorf("unknown type '%s' for custom field %s, must be one of: %s", settings.Type, fieldName, strings.Join(justintimev1.FieldTypes, ", "))
+		}
+	}
+	if requiredFields == nil {
+		return nil
+	}
+
+	timeTypes := []string{justintimev1.FieldTypeText, justintimev1.FieldTypeDate, justintimev1.FieldTypeDateTime}
+	roleTypes := []string{justintimev1.FieldTypeText, justintimev1.FieldTypeSelect}
+	for fieldName, required := range map[string]struct {
+		settings justintimev1.CustomFieldSettings
+		types    []string
+	}{
+		"ClusterRole": {requiredFields.ClusterRole, roleTypes},
+		"StartTime":   {requiredFields.StartTime, timeTypes},
+		"EndTime":     {requiredFields.EndTime, timeTypes},
+	} {
+		if !slices.Contains(required.types, required.settings.Type) {
+			return fmt.Errorf("invalid type '%s' for required field %s, must be one of: %s", required.settings.Type, fieldName, strings.Join(required.types, ", "))
+		}
+	}
+	return nil
+}
+
+// validateRequiredApprovals checks a role policy only requires more than one approval if the approval mode records approvers
+func validateRequiredApprovals(approvalMode string, rolePolicies map[string]justintimev1.RolePolicySpec) error {
+	if approvalMode != "" && approvalMode != justintimev1.ApprovalModeStatus {
+		return nil
+	}
+	for clusterRole, policy := range rolePolicies {
+		if policy.RequiredApprovals > 1 {
+			return fmt.Errorf("rolePolicies %s requires %d approvals, approvalMode must be %s or %s", clusterRole, policy.RequiredApprovals, justintimev1.ApprovalModeServiceDesk, justintimev1.ApprovalModeChangelog)
+		}
+	}
+	return nil
+}
+
+// updateConfigCondition reports the result of validating the config as the ConfigValid condition
+func (c *JustInTimeConfigReconciler) updateConfigCondition(ctx context.Context, name string, validationErr error) error {
+	return c.updateConditions(ctx, name, func(generation int64) []metav1.Condition {
+		condition := metav1.Condition{
+			Type:               justintimev1.ConditionConfigValid,
+			Status:             metav1.ConditionTrue,
+			ObservedGeneration: generation,
+			Reason:             ReasonValid,
+			Message:            "The regexes, approvals, field types, timezone and templates are valid",
+		}
+		if validationErr != nil {
+			condition.Status = metav1.ConditionFalse
+			condition.Reason = ReasonInvalid
+			condition.Message = validationErr.Error()
+		}
+		return []metav1.Condition{condition}
+	})
+}
+
+// updateJiraConditions validates the config against Jira and updates the status conditions if they changed
+func (c *JustInTimeConfigReconciler) updateJiraConditions(ctx context.Context, name string, cfg configuration.Configuration) error {
+	l := log.FromContext(ctx)
+
+	return c.updateConditions(ctx, name, func(generation int64) []metav1.Condition {
+		conditions := validateJiraConfig(ctx, c.JiraClient, cfg, generation)
+		for _, condition := range conditions {
+			if condition.Status != metav1.ConditionTrue {
+				l.Info("JustInTimeConfig is not valid for Jira", "condition", condition.Type, "reason", condition.Reason, "message", condition.Message)
+			}
+		}
+		return conditions
+	})
+}
+
+// updateConditions sets the conditions for the generation of the config and updates the status if they changed
+func (c *JustInTimeConfigReconciler) updateConditions(ctx context.Context, name string, newConditions func(generation int64) []metav1.Condition) error {
+	jitConfig := &justintimev1.JustInTimeConfig{}
+	if err := c.Get(ctx, types.NamespacedName{Name: name}, jitConfig); err != nil {
+		// the default config has no status
+		return client.IgnoreNotFound(err)
+	}
+
+	conditions := jitConfig.Status.DeepCopy().Conditions
+	for _, condition := range newConditions(jitConfig.Generation) {
+		meta.SetStatusCondition(&conditions, condition)
+	}
+
+	// only update on changes, status updates trigger a reconcile
+	if equality.Semantic.DeepEqual(conditions, jitConfig.Status.Conditions) {
+		return nil
+	}
+	jitConfig.Status.Conditions = conditions
+	return c.Status().Update(ctx, jitConfig)
+}
+
 // SaveConfigToFile saves configuration to a file
 func (c *JustInTimeConfigReconciler) SaveConfigToFile(ctx context.Context, cfg configuration.Configuration, filePath string, fileName string) error {
 	l := log.FromContext(ctx)
@@ -121,19 +319,36 @@ func (c *JustInTimeConfigReconciler) SaveConfigToFile(ctx context.Context, cfg c
 	defer ConfigLock.Unlock()
 
 	configData := justintimev1.JustInTimeConfigSpec{
-		AllowedClusterRoles:       cfg.AllowedClusterRoles(),
-		JiraWorkflowApproveStatus: cfg.JiraWorkflowApproveStatus(),
-		RejectedTransitionID:      cfg.RejectedTransitionID(),
-		JiraProject:               cfg.JiraProject(),
-		JiraIssueType:             cfg.JiraIssueType(),
-		CompletedTransitionID:     cfg.CompletedTransitionID(),
-		CustomFields:              cfg.CustomFields(),
-		RequiredFields:            cfg.RequiredFields(),
-		Environment:               cfg.Environment(),
-		Labels:                    cfg.Labels(),
-		AdditionalCommentText:     cfg.AdditionalCommentText(),
-		NamespaceAllowedRegex:     cfg.NamespaceAllowedRegex(),
-		SelfApprovalEnabled:       cfg.SelfApprovalEnabled(),
+		AllowedClusterRoles:          cfg.AllowedClusterRoles(),
+		AllowedClusterScopedRoles:    cfg.AllowedClusterScopedRoles(),
+		JiraWorkflowApproveStatus:    cfg.JiraWorkflowApproveStatus(),
+		JiraWorkflowRejectedStatus:   cfg.JiraWorkflowRejectedStatus(),
+		JiraWorkflowRejectedStatuses: cfg.JiraWorkflowRejectedStatuses(),
+		RejectedTransitionID:         cfg.RejectedTransitionID(),
+		JiraProject:                  cfg.JiraProject(),
+		JiraIssueType:                cfg.JiraIssueType(),
+		LinkedIssueProjects:          cfg.LinkedIssueProjects(),
+		IssueLinkType:                cfg.IssueLinkType(),
+		ServiceDesk:                  cfg.ServiceDesk(),
+		CompletedTransitionID:        cfg.CompletedTransitionID(),
+		RevokedTransitionID:          cfg.RevokedTransitionID(),
+		ExpiredTransitionID:          cfg.ExpiredTransitionID(),
+		ExtensionTransitionID:        cfg.ExtensionTransitionID(),
+		CustomFields:                 cfg.CustomFields(),
+		RequiredFields:               cfg.RequiredFields(),
+		Environment:                  cfg.Environment(),
+		Labels:                       cfg.Labels(),
+		AdditionalCommentText:        cfg.AdditionalCommentText(),
+		NamespaceAllowedRegex:        cfg.NamespaceAllowedRegex(),
+		SelfApprovalEnabled:          cfg.SelfApprovalEnabled(),
+		RetentionPeriod:              cfg.RetentionPeriod(),
+		ApprovalPollInterval:         cfg.ApprovalPollInterval(),
+		ApprovalGracePeriod:          cfg.ApprovalGracePeriod(),
+		StartOnApprovalTimeout:       cfg.StartOnApprovalTimeout(),
+		ApprovalMode:                 cfg.ApprovalMode(),
+		RolePolicies:                 cfg.RolePolicies(),
+		Timezone:                     cfg.Timezone(),
+		Templates:                    cfg.Templates(),
 	}
 
 	data, err := json.MarshalIndent(configData, "", "  ")
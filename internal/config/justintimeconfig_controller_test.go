@@ -21,17 +21,23 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
+	"time"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 
 	justintimev1 "jira-jit-rbac-operator/api/v1"
+	"jira-jit-rbac-operator/pkg/configuration"
 	"jira-jit-rbac-operator/test/utils"
 	"os/exec"
 
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/kubernetes/scheme"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/config"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 )
 
 const (
@@ -89,16 +95,24 @@ var _ = Describe("JustInTimeConfig Controller", Ordered, Label("integration"), f
 
 			By("Checking the config json file matches expected config")
 			expectedConfig := justintimev1.JustInTimeConfigSpec{
-				AllowedClusterRoles:       []string{"edit"},
-				JiraWorkflowApproveStatus: "Approved",
-				RejectedTransitionID:      "21",
-				JiraProject:               "IAM",
-				JiraIssueType:             "Access Request",
-				CompletedTransitionID:     "41",
+				AllowedClusterRoles:          []string{"edit"},
+				AllowedClusterScopedRoles:    []string{"view"},
+				JiraWorkflowApproveStatus:    "Approved",
+				JiraWorkflowRejectedStatus:   "rejected",
+				JiraWorkflowRejectedStatuses: []string{"declined"},
+				RejectedTransitionID:         "21",
+				JiraProject:                  "IAM",
+				JiraIssueType:                "Access Request",
+				LinkedIssueProjects:          []string{"IAM", "INC"},
+				IssueLinkType:                "Relates",
+				CompletedTransitionID:        "41",
+				RevokedTransitionID:          "51",
+				ExpiredTransitionID:          "71",
+				ExtensionTransitionID:        "61",
 				RequiredFields: &justintimev1.RequiredFieldsSpec{
-					StartTime:   justintimev1.CustomFieldSettings{Type: "date", JiraCustomField: "customfield_10118"},
-					EndTime:     justintimev1.CustomFieldSettings{Type: "date", JiraCustomField: "customfield_10119"},
-					ClusterRole: justintimev1.CustomFieldSettings{Type: "date", JiraCustomField: "customfield_10117"},
+					StartTime:   justintimev1.CustomFieldSettings{Type: "datetime", JiraCustomField: "customfield_10118"},
+					EndTime:     justintimev1.CustomFieldSettings{Type: "datetime", JiraCustomField: "customfield_10119"},
+					ClusterRole: justintimev1.CustomFieldSettings{Type: "text", JiraCustomField: "customfield_10117"},
 				},
 				CustomFields: map[string]justintimev1.CustomFieldSettings{
 					"Approver":      {Type: "user", JiraCustomField: "customfield_10114"},
@@ -115,6 +129,17 @@ var _ = Describe("JustInTimeConfig Controller", Ordered, Label("integration"), f
 				AdditionalCommentText: "config: default",
 				NamespaceAllowedRegex: ".*",
 				SelfApprovalEnabled:   false,
+				RetentionPeriod:       &metav1.Duration{Duration: 5 * time.Second},
+				ApprovalPollInterval:  &metav1.Duration{Duration: 5 * time.Second},
+				ApprovalGracePeriod:   &metav1.Duration{Duration: 10 * time.Second},
+				ApprovalMode:          justintimev1.ApprovalModeStatus,
+				RolePolicies: map[string]justintimev1.RolePolicySpec{
+					"view": {
+						MaxDuration:        &metav1.Duration{Duration: time.Hour},
+						RequiredJiraFields: []string{"Justification"},
+					},
+				},
+				Timezone: "UTC",
 			}
 
 			// Read the generated config file
@@ -129,4 +154,121 @@ var _ = Describe("JustInTimeConfig Controller", Ordered, Label("integration"), f
 			Expect(expectedConfig).To(Equal(generatedConfig))
 		})
 	})
+
+	Context("When validating field types", func() {
+		requiredFields := &justintimev1.RequiredFieldsSpec{
+			StartTime:   justintimev1.CustomFieldSettings{Type: "datetime", JiraCustomField: "customfield_10118"},
+			EndTime:     justintimev1.CustomFieldSettings{Type: "date", JiraCustomField: "customfield_10119"},
+			ClusterRole: justintimev1.CustomFieldSettings{Type: "select", JiraCustomField: "customfield_10117"},
+		}
+
+		It("should accept supported field types", func() {
+			customFields := map[string]justintimev1.CustomFieldSettings{
+				"Approvers": {Type: "multiuser", JiraCustomField: "customfield_10114"},
+				"Component": {Type: "cascadingselect", JiraCustomField: "customfield_10115"},
+			}
+			Expect(validateFieldTypes(customFields, requiredFields)).To(Succeed())
+		})
+
+		It("should reject an unknown custom field type", func() {
+			customFields := map[string]justintimev1.CustomFieldSettings{
+				"Approver": {Type: "usr", JiraCustomField: "customfield_10114"},
+			}
+			Expect(validateFieldTypes(customFields, requiredFields)).To(MatchError(ContainSubstring("unknown type 'usr' for custom field Approver")))
+		})
+
+		It("should reject a start time that is not a time field", func() {
+			invalidRequiredFields := requiredFields.DeepCopy()
+			invalidRequiredFields.StartTime.Type = "number"
+			Expect(validateFieldTypes(nil, invalidRequiredFields)).To(MatchError(ContainSubstring("invalid type 'number' for required field StartTime")))
+		})
+	})
+
+	Context("When validating required approvals", func() {
+		rolePolicies := map[string]justintimev1.RolePolicySpec{
+			"admin": {RequiredApprovals: 2},
+		}
+
+		It("should accept a quorum for an approval mode recording approvers", func() {
+			Expect(validateRequiredApprovals(justintimev1.ApprovalModeServiceDesk, rolePolicies)).To(Succeed())
+			Expect(validateRequiredApprovals(justintimev1.ApprovalModeChangelog, rolePolicies)).To(Succeed())
+		})
+
+		It("should reject a quorum for the status approval mode", func() {
+			Expect(validateRequiredApprovals("", rolePolicies)).To(MatchError(ContainSubstring("rolePolicies admin requires 2 approvals")))
+			Expect(validateRequiredApprovals(justintimev1.ApprovalModeStatus, rolePolicies)).To(HaveOccurred())
+		})
+	})
+})
+
+var _ = Describe("validateConfig", Label("unit", "config-validation"), func() {
+
+	var reconciler *JustInTimeConfigReconciler
+	var jitConfig *justintimev1.JustInTimeConfig
+
+	BeforeEach(func() {
+		jitConfig = &justintimev1.JustInTimeConfig{
+			ObjectMeta: metav1.ObjectMeta{Name: "config-validation-test", Generation: 3},
+			Spec: justintimev1.JustInTimeConfigSpec{
+				JiraProject:   "IAM",
+				JiraIssueType: "Access Request",
+				Timezone:      "Europe/London",
+				CustomFields: map[string]justintimev1.CustomFieldSettings{
+					"Justification": {Type: "text", JiraCustomField: "customfield_10116"},
+				},
+			},
+		}
+	})
+
+	validate := func() (*metav1.Condition, error) {
+		k8sClient := fake.NewClientBuilder().
+			WithScheme(scheme.Scheme).
+			WithObjects(jitConfig).
+			WithStatusSubresource(jitConfig).
+			Build()
+		reconciler = &JustInTimeConfigReconciler{Client: k8sClient, Scheme: scheme.Scheme}
+
+		cfg := configuration.NewJitRbacOperatorConfiguration(context.TODO(), k8sClient, jitConfig.Name)
+		validationErr := validateConfig(cfg)
+		Expect(reconciler.updateConfigCondition(context.TODO(), jitConfig.Name, validationErr)).To(Succeed())
+
+		updated := &justintimev1.JustInTimeConfig{}
+		Expect(k8sClient.Get(context.TODO(), types.NamespacedName{Name: jitConfig.Name}, updated)).To(Succeed())
+		return meta.FindStatusCondition(updated.Status.Conditions, justintimev1.ConditionConfigValid), validationErr
+	}
+
+	It("should set the condition true for a valid config", func() {
+		condition, err := validate()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(condition).NotTo(BeNil())
+		Expect(condition.Status).To(Equal(metav1.ConditionTrue))
+		Expect(condition.ObservedGeneration).To(Equal(int64(3)))
+	})
+
+	It("should report an invalid timezone", func() {
+		jitConfig.Spec.Timezone = "Reach/New Alexandria"
+
+		condition, err := validate()
+		Expect(err).To(MatchError(ContainSubstring("timezone 'Reach/New Alexandria' is invalid")))
+		Expect(condition.Status).To(Equal(metav1.ConditionFalse))
+		Expect(condition.Reason).To(Equal(ReasonInvalid))
+		Expect(condition.Message).To(Equal(err.Error()))
+	})
+
+	It("should report an unknown field type", func() {
+		jitConfig.Spec.CustomFields["Approver"] = justintimev1.CustomFieldSettings{Type: "usr", JiraCustomField: "customfield_10114"}
+
+		condition, err := validate()
+		Expect(err).To(MatchError(ContainSubstring("unknown type 'usr' for custom field Approver")))
+		Expect(condition.Status).To(Equal(metav1.ConditionFalse))
+	})
+
+	It("should report an invalid template", func() {
+		jitConfig.Spec.Templates = &justintimev1.JiraTemplatesSpec{Summary: "JIT for {{ .JitRequest.Spec.Reporter"}
+
+		condition, err := validate()
+		Expect(err).To(MatchError(ContainSubstring("invalid summary template")))
+		Expect(condition.Status).To(Equal(metav1.ConditionFalse))
+		Expect(condition.Message).To(ContainSubstring("invalid summary template"))
+	})
 })
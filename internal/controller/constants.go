@@ -1,9 +1,65 @@
 package controller
 
+import (
+	"time"
+
+	justintimev1 "jira-jit-rbac-operator/api/v1"
+)
+
 const (
-	StatusRejected        = "Rejected"
-	StatusPreApproved     = "Pre-Approved"
-	StatusSucceeded       = "Succeeded"
+	StatusRejected        = justintimev1.StateRejected
+	StatusPreApproved     = justintimev1.StatePreApproved
+	StatusSucceeded       = justintimev1.StateSucceeded
+	StatusRevoked         = justintimev1.StateRevoked
+	StatusExpired         = justintimev1.StateExpired
 	EventValidationFailed = "ValidationFailed"
-	Skipped               = "Skipped"
+	// EventFailedJiraTransition is raised when a configured transition is not available for a Jira ticket
+	EventFailedJiraTransition = "FailedJiraTransition"
+	Skipped                   = "Skipped"
+	ExpiryAnnotation          = "justintime.samir.io/expiry"
+	// Approval audit annotations of the role bindings
+	JiraTicketAnnotation = "justintime.samir.io/jira-ticket"
+	JiraStatusAnnotation = "justintime.samir.io/jira-status"
+	ApprovedByAnnotation = "justintime.samir.io/approved-by"
+	ApprovedAtAnnotation = "justintime.samir.io/approved-at"
+	// EventFailedApprovalAudit is raised when the approvers of a Jira ticket can't be read from its changelog
+	EventFailedApprovalAudit = "FailedApprovalAudit"
+	// EventFailedJiraLink is raised when a Jira ticket can't be linked to the linked issue of a JitRequest
+	EventFailedJiraLink = "FailedJiraLink"
+	// EventJiraUnavailable is raised when a JitRequest is requeued because Jira is unavailable
+	EventJiraUnavailable = "JiraUnavailable"
+	// DefaultIssueLinkType is used when issueLinkType is not set in the JustInTimeConfig
+	DefaultIssueLinkType = "Relates"
+	// DefaultApprovalPollInterval is used when approvalPollInterval is not set in the JustInTimeConfig
+	DefaultApprovalPollInterval = time.Minute
+	// DefaultStartOnApprovalTimeout is used when startOnApprovalTimeout is not set in the JustInTimeConfig
+	DefaultStartOnApprovalTimeout = 24 * time.Hour
+	// DefaultRetentionPeriod is used when retentionPeriod is not set in the JustInTimeConfig
+	DefaultRetentionPeriod = 7 * 24 * time.Hour
+)
+
+// Condition reasons
+const (
+	ReasonJiraTicketCreated   = "JiraTicketCreated"
+	ReasonMissingJiraField    = "MissingJiraField"
+	ReasonInvalidJiraField    = "InvalidJiraField"
+	ReasonValidationSucceeded = "ValidationSucceeded"
+	ReasonInvalidClusterRole  = "InvalidClusterRole"
+	ReasonInvalidNamespace    = "InvalidNamespace"
+	ReasonInvalidSubject      = "InvalidSubject"
+	ReasonInvalidTime         = "InvalidTime"
+	ReasonPolicyViolation     = "PolicyViolation"
+	ReasonInvalidLinkedIssue  = "InvalidLinkedIssue"
+	ReasonPendingApproval     = "PendingApproval"
+	ReasonJiraApproved        = "JiraApproved"
+	ReasonJiraNotApproved     = "JiraNotApproved"
+	ReasonJiraRejected        = "JiraRejected"
+	ReasonRoleBindingCreated  = "RoleBindingCreated"
+	ReasonAccessExtended      = "AccessExtended"
+	ReasonEndTimeReached      = "EndTimeReached"
+	ReasonAccessRevoked       = "AccessRevoked"
+	ReasonJiraAvailable       = "JiraAvailable"
+	ReasonJiraUnavailable     = "JiraUnavailable"
+	ReasonJiraTicketPending   = "JiraTicketPending"
+	ReasonJiraTicketClosed    = "JiraTicketClosed"
 )
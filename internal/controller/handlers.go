@@ -2,8 +2,11 @@ package controller
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	justintimev1 "jira-jit-rbac-operator/api/v1"
+	"jira-jit-rbac-operator/pkg/resilience"
+	"jira-jit-rbac-operator/pkg/templates"
 	"jira-jit-rbac-operator/pkg/utils"
 	"os"
 	"strings"
@@ -11,29 +14,110 @@ import (
 
 	"github.com/go-logr/logr"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	ctrl "sigs.k8s.io/controller-runtime"
 )
 
-// handleRejected rejects Jira ticket and deletes a JitRequest
-func (r *JitRequestReconciler) handleRejected(ctx context.Context, l logr.Logger, jitRequest *justintimev1.JitRequest, rejectedTransitionID string) (ctrl.Result, error) {
-	// Reject jira ticket
-	if jitRequest.Status.JiraTicket != Skipped {
-		if err := r.rejectJiraTicket(ctx, jitRequest, rejectedTransitionID); err != nil {
-			l.Error(err, "failed to reject jira ticket")
+// handleRejected rejects Jira ticket and keeps the JitRequest until the retention period has passed
+func (r *JitRequestReconciler) handleRejected(ctx context.Context, l logr.Logger, jitRequest *justintimev1.JitRequest, operatorConfig *justintimev1.JustInTimeConfigSpec) (ctrl.Result, error) {
+	if jitRequest.Status.CompletionTime == nil {
+		setJiraTicketPending(jitRequest)
+
+		// persist the state before the Jira ticket is rejected, so it is only commented once
+		if err := r.updateCompletedStatus(ctx, jitRequest, StatusRejected, jitRequest.Status.Message); err != nil {
+			l.Error(err, "failed to set completion time")
 			return ctrl.Result{}, err
 		}
 	}
-	// Delete JitRequest
-	if err := r.deleteJitRequest(ctx, jitRequest); err != nil {
-		l.Error(err, "failed to delete JitRequest")
+	return r.handleFinished(ctx, l, jitRequest, operatorConfig)
+}
+
+// handleRevoked removes role binding(s) for a revoked JitRequest, updates the Jira ticket and keeps the JitRequest
+func (r *JitRequestReconciler) handleRevoked(ctx context.Context, l logr.Logger, jitRequest *justintimev1.JitRequest, operatorConfig *justintimev1.JustInTimeConfigSpec) (ctrl.Result, error) {
+	revocation := jitRequest.Spec.Revocation
+	l.Info("Revoking JitRequest", "revokedBy", revocation.RevokedBy, "reason", revocation.Reason)
+
+	// Remove access
+	if err := r.deleteOwnedObjects(ctx, jitRequest); err != nil {
+		l.Error(err, "failed to delete owned objects")
 		return ctrl.Result{}, err
 	}
-	return ctrl.Result{}, nil
+
+	msg := fmt.Sprintf("Access revoked by %s | Reason: %s", revocation.RevokedBy, revocation.Reason)
+	r.raiseEvent(jitRequest, "Normal", StatusRevoked, msg)
+	setCondition(jitRequest, justintimev1.ConditionAccessGranted, metav1.ConditionFalse, ReasonAccessRevoked, msg)
+	setCondition(jitRequest, justintimev1.ConditionRevoked, metav1.ConditionTrue, ReasonAccessRevoked, msg)
+	setJiraTicketPending(jitRequest)
+
+	// persist the state before the Jira ticket is revoked, so it is only commented once
+	if err := r.updateCompletedStatus(ctx, jitRequest, StatusRevoked, msg); err != nil {
+		l.Error(err, "failed to update status to Revoked")
+		return ctrl.Result{}, err
+	}
+	return r.handleFinished(ctx, l, jitRequest, operatorConfig)
+}
+
+// setJiraTicketPending marks the Jira ticket of a rejected, revoked or expired JitRequest to be closed
+func setJiraTicketPending(jitRequest *justintimev1.JitRequest) {
+	jiraTicket := jitRequest.Status.JiraTicket
+	if jiraTicket == "" || jiraTicket == Skipped {
+		return
+	}
+	setCondition(jitRequest, justintimev1.ConditionJiraTicketClosed, metav1.ConditionFalse, ReasonJiraTicketPending,
+		fmt.Sprintf("Jira ticket %s is pending an update", jiraTicket))
+}
+
+// handleFinished closes the Jira ticket of a rejected, revoked or expired JitRequest if still pending,
+// then keeps the JitRequest until the retention period has passed
+func (r *JitRequestReconciler) handleFinished(ctx context.Context, l logr.Logger, jitRequest *justintimev1.JitRequest, operatorConfig *justintimev1.JustInTimeConfigSpec) (ctrl.Result, error) {
+	if meta.IsStatusConditionFalse(jitRequest.Status.Conditions, justintimev1.ConditionJiraTicketClosed) {
+		var err error
+		jiraTemplates := templates.New(operatorConfig)
+		switch jitRequest.Status.State {
+		case StatusRejected:
+			err = r.rejectJiraTicket(ctx, jitRequest, operatorConfig.RejectedTransitionID, jiraTemplates)
+		case StatusRevoked:
+			err = r.revokeJiraTicket(ctx, jitRequest, operatorConfig.RevokedTransitionID, jiraTemplates)
+		case StatusExpired:
+			err = r.expireJiraTicket(ctx, jitRequest, operatorConfig.ExpiredTransitionID, jiraTemplates)
+		}
+		if err != nil {
+			l.Error(err, "failed to update jira ticket", "state", jitRequest.Status.State)
+			return ctrl.Result{}, err
+		}
+
+		jiraTicket := jitRequest.Status.JiraTicket
+		setCondition(jitRequest, justintimev1.ConditionJiraTicketClosed, metav1.ConditionTrue, ReasonJiraTicketClosed,
+			fmt.Sprintf("Jira ticket %s updated", jiraTicket))
+		if err := r.updateStatus(ctx, jitRequest, jitRequest.Status.State, jitRequest.Status.Message, jiraTicket); err != nil {
+			l.Error(err, "failed to record the jira ticket update")
+			return ctrl.Result{}, err
+		}
+	}
+	return r.handleRetention(ctx, l, jitRequest, getRetentionPeriod(operatorConfig))
 }
 
 // handleNewRequest creates a new Jira ticket for new JitRequests and validates config
-func (r *JitRequestReconciler) handleNewRequest(ctx context.Context, l logr.Logger, jitRequest *justintimev1.JitRequest, allowedClusterRoles []string, jiraProject, jiraIssueType string, customFieldsConfig map[string]justintimev1.CustomFieldSettings, requiredFieldsConfig *justintimev1.RequiredFieldsSpec, ticketLabels []string, targetEnvironment *justintimev1.EnvironmentSpec, additionalComments string) (ctrl.Result, error) {
-	jiraIssueKey, err := r.createJiraTicket(ctx, jitRequest, jiraProject, jiraIssueType, customFieldsConfig, requiredFieldsConfig, ticketLabels, targetEnvironment)
+func (r *JitRequestReconciler) handleNewRequest(ctx context.Context, l logr.Logger, jitRequest *justintimev1.JitRequest, operatorConfig *justintimev1.JustInTimeConfigSpec) (ctrl.Result, error) {
+	// the timezone is validated by the config controller
+	location, err := utils.LoadTimezone(operatorConfig.Timezone)
+	if err != nil {
+		l.Error(err, "failed to load timezone")
+		return ctrl.Result{}, err
+	}
+
+	// check the linked issue before a ticket is created for it
+	fieldErr, err := utils.ValidateLinkedIssue(ctx, jitRequest, operatorConfig, r.JiraClient)
+	if err != nil {
+		l.Error(err, "failed to validate linked issue")
+		return ctrl.Result{}, err
+	}
+	if fieldErr != nil {
+		return r.rejectInvalidLinkedIssue(ctx, l, jitRequest, fieldErr.Error())
+	}
+
+	jiraIssueKey, created, err := r.ensureJiraTicket(ctx, jitRequest, operatorConfig, location)
 	if err != nil {
 		l.Error(err, "failed to createJiraTicket")
 		return ctrl.Result{}, err
@@ -43,71 +127,305 @@ func (r *JitRequestReconciler) handleNewRequest(ctx context.Context, l logr.Logg
 	if jiraIssueKey == Skipped {
 		return ctrl.Result{}, nil
 	}
+	setCondition(jitRequest, justintimev1.ConditionTicketCreated, metav1.ConditionTrue, ReasonJiraTicketCreated, fmt.Sprintf("Jira ticket %s created", jiraIssueKey))
+
+	// a failed link is not retried, a reused ticket has been linked already
+	if created && jitRequest.Spec.LinkedJiraTicket != "" {
+		if err := r.linkJiraTicket(ctx, jitRequest, jiraIssueKey, operatorConfig.IssueLinkType); err != nil {
+			l.Error(err, "failed to link jira ticket", "linkedJiraTicket", jitRequest.Spec.LinkedJiraTicket)
+			r.raiseEvent(jitRequest, "Warning", EventFailedJiraLink, fmt.Sprintf("Error: %s", err))
+		}
+	}
 
 	// check cluster role is allowed
-	if !utils.Contains(allowedClusterRoles, jitRequest.Spec.ClusterRole) {
+	if !utils.Contains(utils.AllowedClusterRoles(operatorConfig, jitRequest), jitRequest.Spec.ClusterRole) {
 		return r.rejectInvalidRole(ctx, l, jitRequest, jiraIssueKey)
 	}
 
-	// check namespace labels match namespace(s)
-	if os.Getenv("ENABLE_WEBHOOKS") != "true" { // ignore if handled by webnhook
-		ns, err := utils.ValidateNamespaceLabels(ctx, jitRequest, r.Client)
-		if err != nil {
-			return r.rejectInvalidNamespace(ctx, l, jitRequest, jiraIssueKey, strings.Join(ns, ", "), err.Error())
+	// check the request is within the policy for its cluster role, lead time is measured from creation
+	requestedAt := jitRequest.CreationTimestamp.Time
+	if requestedAt.IsZero() {
+		requestedAt = time.Now()
+	}
+	fieldErr, err = utils.ValidateRolePolicy(ctx, jitRequest, operatorConfig, r.Client, requestedAt)
+	if err != nil {
+		l.Error(err, "failed to validate role policy")
+		return ctrl.Result{}, err
+	}
+	if fieldErr != nil {
+		return r.rejectPolicyViolation(ctx, l, jitRequest, jiraIssueKey, fieldErr.Error())
+	}
+
+	// check typed subjects are valid
+	if os.Getenv("ENABLE_WEBHOOKS") != "true" { // ignore if handled by webhook
+		if fieldErr := utils.ValidateSubjects(jitRequest.Spec.Subjects); fieldErr != nil {
+			return r.rejectInvalidSubject(ctx, l, jitRequest, jiraIssueKey, fieldErr.Error())
 		}
 	}
 
-	// check namespaces match regex defined in config
-	nsRegex, err := utils.ValidateNamespaceRegex(jitRequest.Spec.Namespaces)
-	if err != nil {
-		return r.rejectInvalidNamespace(ctx, l, jitRequest, jiraIssueKey, nsRegex, err.Error())
+	// namespace validation does not apply to cluster scoped requests
+	if !jitRequest.Spec.ClusterScoped {
+		// check namespace labels match namespace(s)
+		if os.Getenv("ENABLE_WEBHOOKS") != "true" { // ignore if handled by webnhook
+			ns, err := utils.ValidateNamespaceLabels(ctx, jitRequest, r.Client)
+			if err != nil {
+				return r.rejectInvalidNamespace(ctx, l, jitRequest, jiraIssueKey, strings.Join(ns, ", "), err.Error())
+			}
+		}
+
+		// check namespaces match regex defined in config
+		nsRegex, err := utils.ValidateNamespaceRegex(jitRequest.Spec.Namespaces)
+		if err != nil {
+			return r.rejectInvalidNamespace(ctx, l, jitRequest, jiraIssueKey, nsRegex, err.Error())
+		}
 	}
 
-	return r.preApproveRequest(ctx, l, jitRequest, jiraIssueKey, additionalComments)
+	return r.preApproveRequest(ctx, l, jitRequest, jiraIssueKey, templates.New(operatorConfig), getApprovalPollInterval(operatorConfig))
 }
 
 // handlePreApproved creates the role binding for approved JitRequests if the Jira ticket is approved
-func (r *JitRequestReconciler) handlePreApproved(ctx context.Context, l logr.Logger, jitRequest *justintimev1.JitRequest, completedTransitionID, jiraWorkflowApproveStatus string) (ctrl.Result, error) {
-	// check if it needs to be re-queued
-	startTime := jitRequest.Status.StartTime.Time
-	if startTime.After(time.Now()) {
-		// requeue for start time
-		delay := time.Until(startTime)
-		l.Info("Start time not reached, requeuing", "requeueAfter", delay)
-		return ctrl.Result{RequeueAfter: delay}, nil
+func (r *JitRequestReconciler) handlePreApproved(ctx context.Context, l logr.Logger, jitRequest *justintimev1.JitRequest, operatorConfig *justintimev1.JustInTimeConfigSpec) (ctrl.Result, error) {
+	jiraTicket := jitRequest.Status.JiraTicket
+	jiraStatus := jitRequest.Status.JiraStatus
+	approvals := len(jitRequest.Status.Approvals)
+
+	err := r.getJiraApproval(ctx, jitRequest, operatorConfig)
+
+	// rejected, declined or closed in Jira, no need to wait for the deadline
+	if isJiraRejected(jitRequest, operatorConfig) {
+		return r.handleJiraRejected(ctx, l, jitRequest, getRetentionPeriod(operatorConfig))
 	}
 
-	jiraTicket := jitRequest.Status.JiraTicket
-	if err := r.getJiraApproval(ctx, jitRequest, jiraWorkflowApproveStatus); err != nil {
+	// the approval can't be checked, keep the request pending until Jira is available
+	if resilience.IsUnavailable(err) {
+		return ctrl.Result{}, err
+	}
+
+	if err != nil {
+		// keep polling until the start time plus grace period, or the approval deadline for startOnApproval requests
+		deadline := approvalDeadline(jitRequest, getApprovalGracePeriod(operatorConfig), getStartOnApprovalTimeout(operatorConfig))
+		if time.Now().Before(deadline) {
+			// an early approval was withdrawn, i.e. the ticket was reopened, it is recorded again once re-approved
+			if jitRequest.Status.ApprovedAt != nil {
+				jitRequest.Status.ApprovedAt = nil
+				jitRequest.Status.ApprovedJiraStatus = ""
+				jiraStatus = ""
+			}
+			if jitRequest.Status.JiraStatus != jiraStatus || len(jitRequest.Status.Approvals) != approvals {
+				msg := fmt.Sprintf("Jira ticket status is '%s'", jitRequest.Status.JiraStatus)
+				// approvals are only recorded when counting a quorum
+				if jitRequest.Status.Approvals != nil {
+					msg = fmt.Sprintf("%s, %s", msg, err)
+				}
+				setCondition(jitRequest, justintimev1.ConditionApproved, metav1.ConditionUnknown, ReasonPendingApproval, msg)
+				if err := r.updateStatus(ctx, jitRequest, jitRequest.Status.State, jitRequest.Status.Message, jiraTicket); err != nil {
+					l.Error(err, "failed to update jira status")
+					return ctrl.Result{}, err
+				}
+			}
+			delay := requeueDelay(getApprovalPollInterval(operatorConfig), deadline)
+			l.Info("Jira ticket not approved yet, requeuing", "jira ticket", jiraTicket, "jira status", jitRequest.Status.JiraStatus, "requeueAfter", delay)
+			return ctrl.Result{RequeueAfter: delay}, nil
+		}
 		l.Error(err, StatusRejected, "jira ticket", jiraTicket)
 		r.raiseEvent(jitRequest, "Warning", "JiraNotApproved", fmt.Sprintf("Error: %s", err))
+		setCondition(jitRequest, justintimev1.ConditionApproved, metav1.ConditionFalse, ReasonJiraNotApproved, err.Error())
 		if err := r.updateStatus(ctx, jitRequest, StatusRejected, "Jira ticket has not been approved", jiraTicket); err != nil {
 			return ctrl.Result{}, err
 		}
 		return ctrl.Result{}, nil
 	}
 
-	l.Info("Creating role binding")
+	r.recordJiraApproval(ctx, jitRequest, operatorConfig)
+
+	// approved before start time, wait for start time
+	startTime := jitRequest.Status.StartTime.Time
+	if !jitRequest.Spec.StartOnApproval && startTime.After(time.Now()) {
+		if !meta.IsStatusConditionTrue(jitRequest.Status.Conditions, justintimev1.ConditionApproved) {
+			r.raiseEvent(jitRequest, "Normal", "JiraApproved", fmt.Sprintf("Jira ticket %s approved, access will be granted at start time", jiraTicket))
+			setCondition(jitRequest, justintimev1.ConditionApproved, metav1.ConditionTrue, ReasonJiraApproved, fmt.Sprintf("Jira ticket %s has been approved", jiraTicket))
+			if err := r.updateStatus(ctx, jitRequest, jitRequest.Status.State, jitRequest.Status.Message, jiraTicket); err != nil {
+				l.Error(err, "failed to update jira status")
+				return ctrl.Result{}, err
+			}
+		}
+		// keep polling to cancel the request if the ticket is rejected or reopened before start time
+		delay := requeueDelay(getApprovalPollInterval(operatorConfig), startTime)
+		l.Info("Start time not reached, requeuing", "jira ticket", jiraTicket, "jira status", jitRequest.Status.JiraStatus, "requeueAfter", delay)
+		return ctrl.Result{RequeueAfter: delay}, nil
+	}
+
+	// the clock starts now for startOnApproval requests
+	setAccessTimes(jitRequest, time.Now())
+
+	l.Info("Creating role binding", "startTime", jitRequest.Status.StartTime, "endTime", jitRequest.Status.EndTime)
 	if err := r.createRoleBinding(ctx, jitRequest); err != nil {
 		l.Error(err, "failed to create rbac for JIT request")
 		r.raiseEvent(jitRequest, "Warning", "FailedRBAC", fmt.Sprintf("Error: %s", err))
 		return ctrl.Result{}, err
 	}
 
-	if err := r.completeJiraTicket(ctx, jitRequest, completedTransitionID); err != nil {
+	if err := r.completeJiraTicket(ctx, jitRequest, operatorConfig.CompletedTransitionID, templates.New(operatorConfig)); err != nil {
 		return ctrl.Result{}, err
 	}
 
+	setCondition(jitRequest, justintimev1.ConditionApproved, metav1.ConditionTrue, ReasonJiraApproved, fmt.Sprintf("Jira ticket %s has been approved", jiraTicket))
+	setCondition(jitRequest, justintimev1.ConditionAccessGranted, metav1.ConditionTrue, ReasonRoleBindingCreated, "Access granted until end time")
 	if err := r.updateStatus(ctx, jitRequest, StatusSucceeded, "Access granted until end time", jiraTicket); err != nil {
 		return ctrl.Result{}, err
 	}
 
-	// Queue for deletion at end time
-	return r.handleCleanup(ctx, l, jitRequest)
+	// Queue for expiry at end time
+	return r.handleCleanup(ctx, l, jitRequest, operatorConfig)
+}
+
+// handleJiraRejected rejects a pending JitRequest as soon as its Jira ticket has been rejected
+func (r *JitRequestReconciler) handleJiraRejected(ctx context.Context, l logr.Logger, jitRequest *justintimev1.JitRequest, retentionPeriod time.Duration) (ctrl.Result, error) {
+	jiraTicket := jitRequest.Status.JiraTicket
+	msg := fmt.Sprintf("Jira ticket %s has been rejected", jiraTicket)
+	l.Info("Jira ticket rejected", "jira ticket", jiraTicket, "jira status", jitRequest.Status.JiraStatus)
+	r.raiseEvent(jitRequest, "Warning", "JiraRejected", fmt.Sprintf("%s with status '%s', create a new JitRequest to request access again", msg, jitRequest.Status.JiraStatus))
+	setCondition(jitRequest, justintimev1.ConditionApproved, metav1.ConditionFalse, ReasonJiraRejected, msg)
+
+	// the ticket is already rejected and not marked pending, so it is not transitioned again by handleRejected
+	if err := r.updateCompletedStatus(ctx, jitRequest, StatusRejected, msg); err != nil {
+		l.Error(err, "failed to update status to Rejected")
+		return ctrl.Result{}, err
+	}
+	return r.handleRetention(ctx, l, jitRequest, retentionPeriod)
+}
+
+// handleSucceeded handles extension requests for Succeeded JitRequests and re-queues for clean-up
+func (r *JitRequestReconciler) handleSucceeded(ctx context.Context, l logr.Logger, jitRequest *justintimev1.JitRequest, operatorConfig *justintimev1.JustInTimeConfigSpec) (ctrl.Result, error) {
+	extension := jitRequest.Spec.Extension
+	if extension == nil {
+		return r.handleCleanup(ctx, l, jitRequest, operatorConfig)
+	}
+
+	// new extension request
+	extensionStatus := jitRequest.Status.Extension
+	if extensionStatus == nil || !extensionStatus.EndTime.Equal(&extension.EndTime) {
+		return r.handleNewExtension(ctx, l, jitRequest, operatorConfig)
+	}
+
+	if extensionStatus.State == justintimev1.ExtensionPending {
+		return r.handlePendingExtension(ctx, l, jitRequest, operatorConfig)
+	}
+
+	return r.handleCleanup(ctx, l, jitRequest, operatorConfig)
 }
 
-// handleCleanup cleans up and re-queue succeeded and unknown JitRequests for deletion
-func (r *JitRequestReconciler) handleCleanup(ctx context.Context, l logr.Logger, jitRequest *justintimev1.JitRequest) (ctrl.Result, error) {
+// handleNewExtension validates an extension request and sends it back through Jira approval
+func (r *JitRequestReconciler) handleNewExtension(ctx context.Context, l logr.Logger, jitRequest *justintimev1.JitRequest, operatorConfig *justintimev1.JustInTimeConfigSpec) (ctrl.Result, error) {
+	extension := jitRequest.Spec.Extension
+	endTime := jitRequest.Status.EndTime.Time
+	l.Info("Extension requested", "endTime", extension.EndTime, "reason", extension.Reason)
+
+	// validate extension, policy limits still apply
+	var errMsg string
+	switch {
+	case !extension.EndTime.After(endTime):
+		errMsg = fmt.Sprintf("extension end time must be after current end time '%s'", endTime)
+	case !time.Now().Before(endTime):
+		errMsg = "access has already expired"
+	case !utils.Contains(utils.AllowedClusterRoles(operatorConfig, jitRequest), jitRequest.Spec.ClusterRole):
+		errMsg = fmt.Sprintf("ClusterRole '%s' is no longer allowed", jitRequest.Spec.ClusterRole)
+	default:
+		if fieldErr := utils.ValidateExtensionPolicy(operatorConfig, jitRequest); fieldErr != nil {
+			errMsg = fieldErr.Error()
+		}
+	}
+	if errMsg != "" {
+		r.raiseEvent(jitRequest, "Warning", EventValidationFailed, fmt.Sprintf("Extension not validated | Error: %s", errMsg))
+		if err := r.updateExtensionStatus(ctx, jitRequest, justintimev1.ExtensionRejected, errMsg); err != nil {
+			l.Error(err, "failed to update extension status to Rejected")
+			return ctrl.Result{}, err
+		}
+		return r.handleCleanup(ctx, l, jitRequest, operatorConfig)
+	}
+
+	// send back for approval on the existing ticket
+	if err := r.requestJiraExtension(ctx, jitRequest, operatorConfig.ExtensionTransitionID, templates.New(operatorConfig)); err != nil {
+		l.Error(err, "failed to request extension on jira ticket")
+		return ctrl.Result{}, err
+	}
+
+	msg := fmt.Sprintf("Extension until %s pending human approval(s)", extension.EndTime.Time.Format(time.RFC3339))
+	r.raiseEvent(jitRequest, "Normal", "ExtensionRequested", fmt.Sprintf("%s\nJira: %s", msg, jitRequest.Status.JiraTicket))
+	if err := r.updateExtensionStatus(ctx, jitRequest, justintimev1.ExtensionPending, msg); err != nil {
+		l.Error(err, "failed to update extension status to Pending")
+		return ctrl.Result{}, err
+	}
+
+	delay := requeueDelay(getApprovalPollInterval(operatorConfig), jitRequest.Status.EndTime.Time)
+	l.Info("Extension pending approval, re-queuing", "requeueAfter", delay)
+	return ctrl.Result{RequeueAfter: delay}, nil
+}
+
+// handlePendingExtension extends access if the Jira ticket is re-approved before the current end time
+func (r *JitRequestReconciler) handlePendingExtension(ctx context.Context, l logr.Logger, jitRequest *justintimev1.JitRequest, operatorConfig *justintimev1.JustInTimeConfigSpec) (ctrl.Result, error) {
+	extensionEndTime := jitRequest.Status.Extension.EndTime
+	approvals := len(jitRequest.Status.Extension.Approvals)
+
+	if err := r.getJiraApproval(ctx, jitRequest, operatorConfig); err != nil {
+		// the approval can't be checked, keep the extension pending until Jira is available or access expires
+		if resilience.IsUnavailable(err) && time.Now().Before(jitRequest.Status.EndTime.Time) {
+			return ctrl.Result{}, err
+		}
+		// reject extension if rejected in Jira or not approved before access expires
+		if isJiraRejected(jitRequest, operatorConfig) || !time.Now().Before(jitRequest.Status.EndTime.Time) {
+			msg := "Jira ticket has not been approved before end time"
+			if isJiraRejected(jitRequest, operatorConfig) {
+				msg = "Jira ticket has been rejected"
+			}
+			r.raiseEvent(jitRequest, "Warning", "JiraNotApproved", fmt.Sprintf("Extension rejected | Error: %s", msg))
+			if err := r.updateExtensionStatus(ctx, jitRequest, justintimev1.ExtensionRejected, msg); err != nil {
+				l.Error(err, "failed to update extension status to Rejected")
+				return ctrl.Result{}, err
+			}
+			return r.handleCleanup(ctx, l, jitRequest, operatorConfig)
+		}
+
+		// record new approvers of the quorum
+		if len(jitRequest.Status.Extension.Approvals) != approvals {
+			if err := r.updateStatus(ctx, jitRequest, jitRequest.Status.State, jitRequest.Status.Message, jitRequest.Status.JiraTicket); err != nil {
+				l.Error(err, "failed to update extension approvals")
+				return ctrl.Result{}, err
+			}
+		}
+
+		delay := requeueDelay(getApprovalPollInterval(operatorConfig), jitRequest.Status.EndTime.Time)
+		l.Info("Extension not approved, re-queuing", "requeueAfter", delay)
+		return ctrl.Result{RequeueAfter: delay}, nil
+	}
+
+	l.Info("Extending role binding expiry", "endTime", extensionEndTime)
+	if err := r.updateRoleBindingExpiry(ctx, jitRequest, extensionEndTime); err != nil {
+		l.Error(err, "failed to extend rbac for JIT request")
+		r.raiseEvent(jitRequest, "Warning", "FailedRBAC", fmt.Sprintf("Error: %s", err))
+		return ctrl.Result{}, err
+	}
+
+	if err := r.completeJiraExtension(ctx, jitRequest, operatorConfig.CompletedTransitionID, templates.New(operatorConfig)); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	// move end time only after approval
+	jitRequest.Status.EndTime = extensionEndTime
+	msg := fmt.Sprintf("Access extended until %s", extensionEndTime.Time.Format(time.RFC3339))
+	r.raiseEvent(jitRequest, "Normal", "ExtensionApproved", msg)
+	setCondition(jitRequest, justintimev1.ConditionAccessGranted, metav1.ConditionTrue, ReasonAccessExtended, msg)
+	if err := r.updateExtensionStatus(ctx, jitRequest, justintimev1.ExtensionApproved, msg); err != nil {
+		l.Error(err, "failed to update extension status to Approved")
+		return ctrl.Result{}, err
+	}
+
+	// Queue for expiry at new end time
+	return r.handleCleanup(ctx, l, jitRequest, operatorConfig)
+}
+
+// handleCleanup cleans up and re-queue succeeded and unknown JitRequests for expiry
+func (r *JitRequestReconciler) handleCleanup(ctx context.Context, l logr.Logger, jitRequest *justintimev1.JitRequest, operatorConfig *justintimev1.JustInTimeConfigSpec) (ctrl.Result, error) {
 	endTime := jitRequest.Status.EndTime.Time
 	if endTime.After(time.Now()) {
 		delay := time.Until(endTime)
@@ -115,13 +433,75 @@ func (r *JitRequestReconciler) handleCleanup(ctx context.Context, l logr.Logger,
 		return ctrl.Result{RequeueAfter: delay}, nil
 	}
 
-	l.Info("End time reached, deleting JitRequest")
+	l.Info("End time reached, removing access")
+	if err := r.deleteOwnedObjects(ctx, jitRequest); err != nil {
+		l.Error(err, "failed to delete owned objects")
+		return ctrl.Result{}, err
+	}
+
+	msg := "Access expired at end time"
+	r.raiseEvent(jitRequest, "Normal", StatusExpired, msg)
+	setCondition(jitRequest, justintimev1.ConditionAccessGranted, metav1.ConditionFalse, ReasonEndTimeReached, msg)
+	setCondition(jitRequest, justintimev1.ConditionExpired, metav1.ConditionTrue, ReasonEndTimeReached, msg)
+	setJiraTicketPending(jitRequest)
+
+	// persist the state before the Jira ticket is expired, so it is only commented once.
+	// The completion time is the actual revocation time for the comment
+	if err := r.updateCompletedStatus(ctx, jitRequest, StatusExpired, msg); err != nil {
+		l.Error(err, "failed to update status to Expired")
+		return ctrl.Result{}, err
+	}
+	return r.handleFinished(ctx, l, jitRequest, operatorConfig)
+}
+
+// handleRetention keeps finished JitRequests as history and deletes them after the retention period
+func (r *JitRequestReconciler) handleRetention(ctx context.Context, l logr.Logger, jitRequest *justintimev1.JitRequest, retentionPeriod time.Duration) (ctrl.Result, error) {
+	// set for JitRequests finished before retention was supported
+	if jitRequest.Status.CompletionTime == nil {
+		if err := r.updateCompletedStatus(ctx, jitRequest, jitRequest.Status.State, jitRequest.Status.Message); err != nil {
+			l.Error(err, "failed to set completion time")
+			return ctrl.Result{}, err
+		}
+	}
+
+	expiry := jitRequest.Status.CompletionTime.Add(retentionPeriod)
+	if expiry.After(time.Now()) {
+		delay := time.Until(expiry)
+		l.Info("Retention period not reached, re-queuing", "requeueAfter", delay)
+		return ctrl.Result{RequeueAfter: delay}, nil
+	}
+
+	l.Info("Retention period reached, deleting JitRequest")
 	if err := r.deleteJitRequest(ctx, jitRequest); err != nil {
 		return ctrl.Result{}, err
 	}
 	return ctrl.Result{}, nil
 }
 
+// handleJiraAvailability requeues a JitRequest after the circuit breaker cooldown while Jira is unavailable,
+// and reports it in the JiraAvailable condition until a reconcile succeeds
+func (r *JitRequestReconciler) handleJiraAvailability(ctx context.Context, l logr.Logger, jitRequest *justintimev1.JitRequest, result ctrl.Result, err error) (ctrl.Result, error) {
+	var unavailableErr *resilience.UnavailableError
+	if errors.As(err, &unavailableErr) {
+		delay := max(unavailableErr.RetryAfter, time.Second)
+		l.Info("Jira unavailable, re-queuing", "error", err.Error(), "requeueAfter", delay)
+		if !meta.IsStatusConditionFalse(jitRequest.Status.Conditions, justintimev1.ConditionJiraAvailable) {
+			r.raiseEvent(jitRequest, "Warning", EventJiraUnavailable, fmt.Sprintf("Retrying in %s | Error: %s", delay, err))
+			if err := r.updateJiraCondition(ctx, jitRequest, metav1.ConditionFalse, ReasonJiraUnavailable, err.Error()); err != nil {
+				return ctrl.Result{}, err
+			}
+		}
+		return ctrl.Result{RequeueAfter: delay}, nil
+	}
+
+	if err == nil && meta.IsStatusConditionFalse(jitRequest.Status.Conditions, justintimev1.ConditionJiraAvailable) {
+		if err := r.updateJiraCondition(ctx, jitRequest, metav1.ConditionTrue, ReasonJiraAvailable, "Jira is available"); err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+	return result, err
+}
+
 // handleFetchError cleans-up owned objects (role bindings) on deleted JitRequests
 func (r *JitRequestReconciler) handleFetchError(ctx context.Context, l logr.Logger, err error, jitRequest *justintimev1.JitRequest) (ctrl.Result, error) {
 	if apierrors.IsNotFound(err) {
@@ -5,6 +5,8 @@ import (
 	"fmt"
 	v1 "jira-jit-rbac-operator/api/v1"
 	"jira-jit-rbac-operator/internal/config"
+	"jira-jit-rbac-operator/pkg/resilience"
+	"jira-jit-rbac-operator/pkg/utils"
 	testUtils "jira-jit-rbac-operator/test/utils"
 	"os/exec"
 	"regexp"
@@ -14,6 +16,7 @@ import (
 
 	rbacv1 "k8s.io/api/rbac/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	"k8s.io/apimachinery/pkg/types"
 
 	. "github.com/onsi/ginkgo/v2"
@@ -40,18 +43,19 @@ var _ = Describe("JitRequestReconciler handlers Unit Tests", Ordered, Label("uni
 
 		By("setting a jitConfig")
 		jitConfig = &v1.JustInTimeConfigSpec{
-			AllowedClusterRoles: []string{"edit"},
-			JiraProject:         "IAM",
-			JiraIssueType:       "Access Request",
+			AllowedClusterRoles:       []string{"edit"},
+			AllowedClusterScopedRoles: []string{"view"},
+			JiraProject:               "IAM",
+			JiraIssueType:             "Access Request",
 			CustomFields: map[string]v1.CustomFieldSettings{
 				"Approver":      {Type: "user", JiraCustomField: "customfield_10114"},
 				"ProductOwner":  {Type: "user", JiraCustomField: "customfield_10115"},
 				"Justification": {Type: "text", JiraCustomField: "customfield_10116"},
 			},
 			RequiredFields: &v1.RequiredFieldsSpec{
-				StartTime:   v1.CustomFieldSettings{Type: "date", JiraCustomField: "customfield_10118"},
-				EndTime:     v1.CustomFieldSettings{Type: "date", JiraCustomField: "customfield_10119"},
-				ClusterRole: v1.CustomFieldSettings{Type: "date", JiraCustomField: "customfield_10117"},
+				StartTime:   v1.CustomFieldSettings{Type: "datetime", JiraCustomField: "customfield_10118"},
+				EndTime:     v1.CustomFieldSettings{Type: "datetime", JiraCustomField: "customfield_10119"},
+				ClusterRole: v1.CustomFieldSettings{Type: "text", JiraCustomField: "customfield_10117"},
 			},
 			Labels: []string{"label1", "label2"},
 			Environment: &v1.EnvironmentSpec{
@@ -117,7 +121,7 @@ var _ = Describe("JitRequestReconciler handlers Unit Tests", Ordered, Label("uni
 			Expect(err).NotTo(HaveOccurred())
 
 			By("Checking the jitRequest is re-queued for startTime")
-			result, err := reconciler.handleNewRequest(ctx, l, jitRequest, jitConfig.AllowedClusterRoles, jitConfig.JiraProject, jitConfig.JiraIssueType, jitConfig.CustomFields, jitConfig.RequiredFields, jitConfig.Labels, jitConfig.Environment, jitConfig.AdditionalCommentText)
+			result, err := reconciler.handleNewRequest(ctx, l, jitRequest, jitConfig)
 			Expect(err).NotTo(HaveOccurred())
 			Expect(result).NotTo(BeNil())
 			Expect(result.IsZero()).To(BeFalse())
@@ -131,6 +135,47 @@ var _ = Describe("JitRequestReconciler handlers Unit Tests", Ordered, Label("uni
 			Expect(jitRequest.Status.State).To(Equal(StatusPreApproved))
 			Expect(jitRequest.Status.Message).To(Equal("Pre-approval - Access will be granted at start time pending human approval(s)"))
 			Expect(jitRequest.Status.JiraTicket).To(Equal(JiraTicket))
+
+			By("Checking the jitRequest conditions")
+			Expect(meta.IsStatusConditionTrue(jitRequest.Status.Conditions, v1.ConditionTicketCreated)).To(BeTrue())
+			Expect(meta.IsStatusConditionTrue(jitRequest.Status.Conditions, v1.ConditionValidated)).To(BeTrue())
+			approved := meta.FindStatusCondition(jitRequest.Status.Conditions, v1.ConditionApproved)
+			Expect(approved).NotTo(BeNil())
+			Expect(approved.Status).To(Equal(metav1.ConditionUnknown))
+			Expect(approved.Reason).To(Equal(ReasonPendingApproval))
+			Expect(approved.ObservedGeneration).To(Equal(jitRequest.Generation))
+		})
+
+		It("should reuse the Jira ticket created by a previous reconcile", func() {
+			// Create JitRequest
+			jitRequest, err := testUtils.CreateJitRequest(ctx, reconciler.Client, 10, testUtils.ValidClusterRole, TestNamespace)
+			Expect(err).NotTo(HaveOccurred())
+			created := testUtils.CreatedIssues
+
+			By("Creating the Jira ticket and recording it in the status")
+			jiraTicket, ticketCreated, err := reconciler.ensureJiraTicket(ctx, jitRequest, jitConfig, time.UTC)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(ticketCreated).To(BeTrue())
+			Expect(jiraTicket).To(Equal(JiraTicket))
+			Expect(testUtils.CreatedIssues).To(Equal(created + 1))
+			err = reconciler.Get(ctx, types.NamespacedName{Name: JitRequestName}, jitRequest)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(jitRequest.Status.JiraTicket).To(Equal(JiraTicket))
+			Expect(meta.IsStatusConditionTrue(jitRequest.Status.Conditions, v1.ConditionTicketCreated)).To(BeTrue())
+
+			By("Reusing the Jira ticket recorded in the status")
+			jiraTicket, ticketCreated, err = reconciler.ensureJiraTicket(ctx, jitRequest, jitConfig, time.UTC)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(ticketCreated).To(BeFalse())
+			Expect(jiraTicket).To(Equal(JiraTicket))
+
+			By("Finding the Jira ticket by its label if the status was not updated")
+			jitRequest.Status.JiraTicket = ""
+			jiraTicket, ticketCreated, err = reconciler.ensureJiraTicket(ctx, jitRequest, jitConfig, time.UTC)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(ticketCreated).To(BeFalse())
+			Expect(jiraTicket).To(Equal(JiraTicket))
+			Expect(testUtils.CreatedIssues).To(Equal(created + 1))
 		})
 
 		It("should return if missing jira field", func() {
@@ -139,10 +184,10 @@ var _ = Describe("JitRequestReconciler handlers Unit Tests", Ordered, Label("uni
 			Expect(err).NotTo(HaveOccurred())
 
 			By("Checking controller returns with no error")
-			missingCustomFieldsConfig := map[string]v1.CustomFieldSettings{
+			jitConfig.CustomFields = map[string]v1.CustomFieldSettings{
 				"MissingField": {Type: "user", JiraCustomField: "customfield_10114"},
 			}
-			result, err := reconciler.handleNewRequest(ctx, l, jitRequest, jitConfig.AllowedClusterRoles, jitConfig.JiraProject, jitConfig.JiraIssueType, missingCustomFieldsConfig, jitConfig.RequiredFields, jitConfig.Labels, jitConfig.Environment, jitConfig.AdditionalCommentText)
+			result, err := reconciler.handleNewRequest(ctx, l, jitRequest, jitConfig)
 			Expect(err).NotTo(HaveOccurred())
 			Expect(result).NotTo(BeNil())
 			Expect(result.IsZero()).To(BeTrue())
@@ -158,13 +203,55 @@ var _ = Describe("JitRequestReconciler handlers Unit Tests", Ordered, Label("uni
 			Expect(jitRequest.Status.JiraTicket).To(Equal(Skipped))
 		})
 
+		It("should link the Jira ticket to the linked issue", func() {
+			// Create JitRequest
+			jitRequest, err := testUtils.CreateJitRequest(ctx, reconciler.Client, 10, testUtils.ValidClusterRole, TestNamespace)
+			Expect(err).NotTo(HaveOccurred())
+
+			By("Linking the JitRequest to an open incident")
+			jitRequest.Spec.LinkedJiraTicket = testUtils.LinkedIssue
+			jitConfig.LinkedIssueProjects = []string{"INC"}
+			result, err := reconciler.handleNewRequest(ctx, l, jitRequest, jitConfig)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.IsZero()).To(BeFalse())
+
+			By("Checking the Jira ticket is linked and pending approval")
+			Expect(jitRequest.Status.State).To(Equal(StatusPreApproved))
+			Expect(testUtils.LastIssueLink.Type.Name).To(Equal(DefaultIssueLinkType))
+			Expect(testUtils.LastIssueLink.InwardIssue.Key).To(Equal(JiraTicket))
+			Expect(testUtils.LastIssueLink.OutwardIssue.Key).To(Equal(testUtils.LinkedIssue))
+		})
+
+		It("should reject a linked issue that is closed before creating a Jira ticket", func() {
+			// Create JitRequest
+			jitRequest, err := testUtils.CreateJitRequest(ctx, reconciler.Client, 10, testUtils.ValidClusterRole, TestNamespace)
+			Expect(err).NotTo(HaveOccurred())
+
+			By("Linking the JitRequest to a resolved incident")
+			jitRequest.Spec.LinkedJiraTicket = testUtils.ClosedLinkedIssue
+			jitConfig.LinkedIssueProjects = []string{"INC"}
+			result, err := reconciler.handleNewRequest(ctx, l, jitRequest, jitConfig)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.IsZero()).To(BeTrue())
+
+			By("Checking the jitRequest status is rejected")
+			err = reconciler.Get(ctx, types.NamespacedName{Name: JitRequestName}, jitRequest)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(jitRequest.Status.State).To(Equal(StatusRejected))
+			Expect(jitRequest.Status.Message).To(ContainSubstring("linked issue must be open, it is in status 'Resolved'"))
+			Expect(jitRequest.Status.JiraTicket).To(Equal(Skipped))
+			validated := meta.FindStatusCondition(jitRequest.Status.Conditions, v1.ConditionValidated)
+			Expect(validated).NotTo(BeNil())
+			Expect(validated.Reason).To(Equal(ReasonInvalidLinkedIssue))
+		})
+
 		It("should return rejectInvalidRole if invalid cluster role", func() {
 			// Create JitRequest
 			jitRequest, err := testUtils.CreateJitRequest(ctx, reconciler.Client, 10, testUtils.InvalidClusterRole, TestNamespace)
 			Expect(err).NotTo(HaveOccurred())
 
 			By("Checking controller returns with no error")
-			result, err := reconciler.handleNewRequest(ctx, l, jitRequest, jitConfig.AllowedClusterRoles, jitConfig.JiraProject, jitConfig.JiraIssueType, jitConfig.CustomFields, jitConfig.RequiredFields, jitConfig.Labels, jitConfig.Environment, jitConfig.AdditionalCommentText)
+			result, err := reconciler.handleNewRequest(ctx, l, jitRequest, jitConfig)
 			Expect(err).NotTo(HaveOccurred())
 			Expect(result).NotTo(BeNil())
 			Expect(result.IsZero()).To(BeTrue())
@@ -180,13 +267,131 @@ var _ = Describe("JitRequestReconciler handlers Unit Tests", Ordered, Label("uni
 			Expect(jitRequest.Status.JiraTicket).To(Equal(JiraTicket))
 		})
 
+		It("should return rejectInvalidRole if cluster role is not allowed cluster-wide", func() {
+			// Create cluster scoped JitRequest
+			jitRequest, err := testUtils.CreateClusterScopedJitRequest(ctx, reconciler.Client, 10, testUtils.ValidClusterRole)
+			Expect(err).NotTo(HaveOccurred())
+
+			By("Checking controller returns with no error")
+			result, err := reconciler.handleNewRequest(ctx, l, jitRequest, jitConfig)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result).NotTo(BeNil())
+			Expect(result.IsZero()).To(BeTrue())
+
+			By("Checking the jitRequest status is rejected")
+			namespacedName := types.NamespacedName{
+				Name: "e2e-jit-test",
+			}
+			err = reconciler.Get(ctx, namespacedName, jitRequest)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(jitRequest.Status.State).To(Equal(StatusRejected))
+			Expect(jitRequest.Status.Message).To(Equal("ClusterRole 'edit' is not allowed cluster-wide"))
+			Expect(jitRequest.Status.JiraTicket).To(Equal(JiraTicket))
+		})
+
+		It("should return rejectInvalidSubject if a ServiceAccount subject has no namespace", func() {
+			// Create JitRequest
+			jitRequest, err := testUtils.CreateJitRequest(ctx, reconciler.Client, 10, testUtils.ValidClusterRole, TestNamespace)
+			Expect(err).NotTo(HaveOccurred())
+			jitRequest.Spec.Subjects = []v1.SubjectSpec{
+				{Kind: "ServiceAccount", Name: "deployer"},
+			}
+
+			By("Checking controller returns with no error")
+			result, err := reconciler.handleNewRequest(ctx, l, jitRequest, jitConfig)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result).NotTo(BeNil())
+			Expect(result.IsZero()).To(BeTrue())
+
+			By("Checking the jitRequest status is rejected")
+			namespacedName := types.NamespacedName{
+				Name: "e2e-jit-test",
+			}
+			err = reconciler.Get(ctx, namespacedName, jitRequest)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(jitRequest.Status.State).To(Equal(StatusRejected))
+			Expect(jitRequest.Status.Message).To(ContainSubstring("namespace is required for ServiceAccount subjects"))
+			Expect(jitRequest.Status.JiraTicket).To(Equal(JiraTicket))
+		})
+
+		It("should handle and pre-approve a new valid cluster scoped JitRequest", func() {
+			// Create cluster scoped JitRequest
+			jitRequest, err := testUtils.CreateClusterScopedJitRequest(ctx, reconciler.Client, 10, testUtils.ValidClusterScopedRole)
+			Expect(err).NotTo(HaveOccurred())
+
+			By("Checking the jitRequest is re-queued for startTime")
+			result, err := reconciler.handleNewRequest(ctx, l, jitRequest, jitConfig)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result).NotTo(BeNil())
+			Expect(result.IsZero()).To(BeFalse())
+
+			By("Checking the jitRequest status is pre-approved")
+			namespacedName := types.NamespacedName{
+				Name: "e2e-jit-test",
+			}
+			err = reconciler.Get(ctx, namespacedName, jitRequest)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(jitRequest.Status.State).To(Equal(StatusPreApproved))
+			Expect(jitRequest.Status.JiraTicket).To(Equal(JiraTicket))
+		})
+
+		It("should return rejectPolicyViolation if the duration exceeds the role policy", func() {
+			jitConfig.RolePolicies = map[string]v1.RolePolicySpec{
+				testUtils.ValidClusterRole: {MaxDuration: &metav1.Duration{Duration: 5 * time.Second}},
+			}
+
+			// Create JitRequest
+			jitRequest, err := testUtils.CreateJitRequest(ctx, reconciler.Client, 10, testUtils.ValidClusterRole, TestNamespace)
+			Expect(err).NotTo(HaveOccurred())
+
+			By("Checking controller returns with no error")
+			result, err := reconciler.handleNewRequest(ctx, l, jitRequest, jitConfig)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result).NotTo(BeNil())
+			Expect(result.IsZero()).To(BeTrue())
+
+			By("Checking the jitRequest status is rejected")
+			err = reconciler.Get(ctx, types.NamespacedName{Name: JitRequestName}, jitRequest)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(jitRequest.Status.State).To(Equal(StatusRejected))
+			Expect(jitRequest.Status.Message).To(ContainSubstring("ClusterRole 'edit' policy not met"))
+			Expect(jitRequest.Status.Message).To(ContainSubstring("duration must not exceed '5s'"))
+			Expect(jitRequest.Status.JiraTicket).To(Equal(JiraTicket))
+			validated := meta.FindStatusCondition(jitRequest.Status.Conditions, v1.ConditionValidated)
+			Expect(validated).NotTo(BeNil())
+			Expect(validated.Status).To(Equal(metav1.ConditionFalse))
+			Expect(validated.Reason).To(Equal(ReasonPolicyViolation))
+		})
+
+		It("should return rejectPolicyViolation if a jira field required by the role policy is empty", func() {
+			jitConfig.RolePolicies = map[string]v1.RolePolicySpec{
+				testUtils.ValidClusterRole: {RequiredJiraFields: []string{"Justification"}},
+			}
+
+			// Create JitRequest
+			jitRequest, err := testUtils.CreateJitRequest(ctx, reconciler.Client, 10, testUtils.ValidClusterRole, TestNamespace)
+			Expect(err).NotTo(HaveOccurred())
+			jitRequest.Spec.JiraFields["Justification"] = ""
+
+			By("Checking controller returns with no error")
+			result, err := reconciler.handleNewRequest(ctx, l, jitRequest, jitConfig)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.IsZero()).To(BeTrue())
+
+			By("Checking the jitRequest status is rejected")
+			err = reconciler.Get(ctx, types.NamespacedName{Name: JitRequestName}, jitRequest)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(jitRequest.Status.State).To(Equal(StatusRejected))
+			Expect(jitRequest.Status.Message).To(ContainSubstring("jira field 'Justification' is required for clusterRole 'edit'"))
+		})
+
 		It("should return rejectInvalidNamespace if invalid namespace labels", func() {
 			// Create JitRequest
 			jitRequest, err := testUtils.CreateJitRequest(ctx, reconciler.Client, 10, testUtils.ValidClusterRole, TestNamespace, "bar")
 			Expect(err).NotTo(HaveOccurred())
 
 			By("Checking controller returns with no error")
-			result, err := reconciler.handleNewRequest(ctx, l, jitRequest, jitConfig.AllowedClusterRoles, jitConfig.JiraProject, jitConfig.JiraIssueType, jitConfig.CustomFields, jitConfig.RequiredFields, jitConfig.Labels, jitConfig.Environment, jitConfig.AdditionalCommentText)
+			result, err := reconciler.handleNewRequest(ctx, l, jitRequest, jitConfig)
 			Expect(err).NotTo(HaveOccurred())
 			Expect(result).NotTo(BeNil())
 			Expect(result.IsZero()).To(BeTrue())
@@ -211,7 +416,7 @@ var _ = Describe("JitRequestReconciler handlers Unit Tests", Ordered, Label("uni
 			Expect(err).NotTo(HaveOccurred())
 
 			By("Checking controller returns with no error")
-			result, err := reconciler.handleNewRequest(ctx, l, jitRequest, jitConfig.AllowedClusterRoles, jitConfig.JiraProject, jitConfig.JiraIssueType, jitConfig.CustomFields, jitConfig.RequiredFields, jitConfig.Labels, jitConfig.Environment, jitConfig.AdditionalCommentText)
+			result, err := reconciler.handleNewRequest(ctx, l, jitRequest, jitConfig)
 			Expect(err).NotTo(HaveOccurred())
 			Expect(result).NotTo(BeNil())
 			Expect(result.IsZero()).To(BeTrue())
@@ -238,7 +443,7 @@ var _ = Describe("JitRequestReconciler handlers Unit Tests", Ordered, Label("uni
 
 			By("Checking the jitRequest is re-queued for startTime")
 			jitRequest.Status.StartTime.Time = jitRequest.Spec.StartTime.Time
-			result, err := reconciler.handlePreApproved(ctx, l, jitRequest, "", "")
+			result, err := reconciler.handlePreApproved(ctx, l, jitRequest, jitConfig)
 			Expect(err).NotTo(HaveOccurred())
 			Expect(result).NotTo(BeNil())
 			Expect(result.IsZero()).To(BeFalse())
@@ -250,7 +455,7 @@ var _ = Describe("JitRequestReconciler handlers Unit Tests", Ordered, Label("uni
 			Expect(err).NotTo(HaveOccurred())
 
 			By("Checking controller returns with no error")
-			result, err := reconciler.handlePreApproved(ctx, l, jitRequest, "", "")
+			result, err := reconciler.handlePreApproved(ctx, l, jitRequest, jitConfig)
 			Expect(err).NotTo(HaveOccurred())
 			Expect(result).NotTo(BeNil())
 			Expect(result.IsZero()).To(BeTrue())
@@ -279,7 +484,9 @@ var _ = Describe("JitRequestReconciler handlers Unit Tests", Ordered, Label("uni
 			testUtils.IssueStatus = jiraWorkflowApproved
 
 			By("Checking the jitRequest is re-queued for clean-up")
-			result, err := reconciler.handlePreApproved(ctx, l, jitRequest, "10", jiraWorkflowApproved)
+			jitConfig.CompletedTransitionID = "10"
+			jitConfig.JiraWorkflowApproveStatus = jiraWorkflowApproved
+			result, err := reconciler.handlePreApproved(ctx, l, jitRequest, jitConfig)
 			Expect(err).NotTo(HaveOccurred())
 			Expect(result).NotTo(BeNil())
 			Expect(result.IsZero()).To(BeFalse())
@@ -294,6 +501,8 @@ var _ = Describe("JitRequestReconciler handlers Unit Tests", Ordered, Label("uni
 			Expect(jitRequest.Status.State).To(Equal(StatusSucceeded))
 			Expect(jitRequest.Status.Message).To(Equal(message))
 			Expect(jitRequest.Status.JiraTicket).To(Equal(JiraTicket))
+			Expect(meta.IsStatusConditionTrue(jitRequest.Status.Conditions, v1.ConditionApproved)).To(BeTrue())
+			Expect(meta.IsStatusConditionTrue(jitRequest.Status.Conditions, v1.ConditionAccessGranted)).To(BeTrue())
 
 			By("checking role binding exists")
 			rbName := fmt.Sprintf("%s-jit", jitRequest.Name)
@@ -304,6 +513,239 @@ var _ = Describe("JitRequestReconciler handlers Unit Tests", Ordered, Label("uni
 			}
 			err = reconciler.Get(ctx, rbNamespacedName, rb)
 			Expect(err).NotTo(HaveOccurred())
+
+			By("checking the approval audit trail is recorded")
+			Expect(jitRequest.Status.Approvals).To(HaveLen(1))
+			Expect(jitRequest.Status.Approvals[0].Approver).To(Equal(testUtils.IssueApprover))
+			Expect(jitRequest.Status.ApprovedAt).NotTo(BeNil())
+			Expect(jitRequest.Status.ApprovedJiraStatus).To(Equal(jiraWorkflowApproved))
+			Expect(rb.Annotations).To(HaveKeyWithValue(ApprovedByAnnotation, testUtils.IssueApprover))
+			Expect(rb.Annotations).To(HaveKeyWithValue(ApprovedAtAnnotation, "2025-01-20T21:01:46Z"))
+			Expect(rb.Annotations).To(HaveKeyWithValue(JiraStatusAnnotation, jiraWorkflowApproved))
+			Expect(rb.Annotations).To(HaveKeyWithValue(JiraTicketAnnotation, JiraTicket))
+		})
+
+		It("should record an early approval and re-queue for startTime", func() {
+			// Create JitRequest
+			jitRequest, err := testUtils.CreateJitRequest(ctx, reconciler.Client, 100, testUtils.ValidClusterRole, TestNamespace)
+			Expect(err).NotTo(HaveOccurred())
+
+			By("Approving the JitRequest before startTime")
+			jitRequest.Status.State = StatusPreApproved
+			jitRequest.Status.StartTime.Time = jitRequest.Spec.StartTime.Time
+			jitRequest.Status.JiraTicket = JiraTicket
+			jiraWorkflowApproved := "Approved"
+			testUtils.IssueStatus = jiraWorkflowApproved
+			jitConfig.JiraWorkflowApproveStatus = jiraWorkflowApproved
+
+			result, err := reconciler.handlePreApproved(ctx, l, jitRequest, jitConfig)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.RequeueAfter).To(Equal(DefaultApprovalPollInterval))
+
+			By("Checking the approval is recorded without granting access")
+			err = reconciler.Get(ctx, types.NamespacedName{Name: JitRequestName}, jitRequest)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(jitRequest.Status.State).To(Equal(StatusPreApproved))
+			Expect(jitRequest.Status.JiraStatus).To(Equal(jiraWorkflowApproved))
+			Expect(jitRequest.Status.ApprovedAt).NotTo(BeNil())
+			Expect(meta.IsStatusConditionTrue(jitRequest.Status.Conditions, v1.ConditionApproved)).To(BeTrue())
+
+		})
+
+		It("should reject an approved JitRequest as soon as the Jira ticket is declined before startTime", func() {
+			// Create JitRequest
+			jitRequest, err := testUtils.CreateJitRequest(ctx, reconciler.Client, 100, testUtils.ValidClusterRole, TestNamespace)
+			Expect(err).NotTo(HaveOccurred())
+
+			By("Approving the JitRequest before startTime")
+			jitRequest.Status.State = StatusPreApproved
+			jitRequest.Status.StartTime.Time = jitRequest.Spec.StartTime.Time
+			jitRequest.Status.JiraTicket = JiraTicket
+			testUtils.IssueStatus = "Approved"
+			jitConfig.JiraWorkflowApproveStatus = "Approved"
+			jitConfig.JiraWorkflowRejectedStatuses = []string{"Declined"}
+			_, err = reconciler.handlePreApproved(ctx, l, jitRequest, jitConfig)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(jitRequest.Status.ApprovedAt).NotTo(BeNil())
+
+			By("Declining the Jira ticket before startTime")
+			testUtils.IssueStatus = "Declined"
+			_, err = reconciler.handlePreApproved(ctx, l, jitRequest, jitConfig)
+			Expect(err).NotTo(HaveOccurred())
+
+			By("Checking the jitRequest is rejected without waiting for startTime")
+			err = reconciler.Get(ctx, types.NamespacedName{Name: JitRequestName}, jitRequest)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(jitRequest.Status.State).To(Equal(StatusRejected))
+			Expect(jitRequest.Status.JiraStatus).To(Equal("Declined"))
+			Expect(meta.FindStatusCondition(jitRequest.Status.Conditions, v1.ConditionApproved).Reason).To(Equal(ReasonJiraRejected))
+		})
+
+		It("should withdraw an early approval once the Jira ticket is reopened", func() {
+			// Create JitRequest
+			jitRequest, err := testUtils.CreateJitRequest(ctx, reconciler.Client, 100, testUtils.ValidClusterRole, TestNamespace)
+			Expect(err).NotTo(HaveOccurred())
+
+			By("Approving the JitRequest before startTime")
+			jitRequest.Status.State = StatusPreApproved
+			jitRequest.Status.StartTime.Time = jitRequest.Spec.StartTime.Time
+			jitRequest.Status.JiraTicket = JiraTicket
+			testUtils.IssueStatus = "Approved"
+			jitConfig.JiraWorkflowApproveStatus = "Approved"
+			_, err = reconciler.handlePreApproved(ctx, l, jitRequest, jitConfig)
+			Expect(err).NotTo(HaveOccurred())
+
+			By("Reopening the Jira ticket before startTime")
+			testUtils.IssueStatus = testUtils.TestJiraWorkflowToDoStatus
+			result, err := reconciler.handlePreApproved(ctx, l, jitRequest, jitConfig)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.RequeueAfter).To(Equal(DefaultApprovalPollInterval))
+
+			By("Checking the approval is withdrawn")
+			err = reconciler.Get(ctx, types.NamespacedName{Name: JitRequestName}, jitRequest)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(jitRequest.Status.State).To(Equal(StatusPreApproved))
+			Expect(jitRequest.Status.ApprovedAt).To(BeNil())
+			Expect(meta.FindStatusCondition(jitRequest.Status.Conditions, v1.ConditionApproved).Status).To(Equal(metav1.ConditionUnknown))
+		})
+
+		It("should reject a JitRequest as soon as the Jira ticket is rejected", func() {
+			// Create JitRequest
+			jitRequest, err := testUtils.CreateJitRequest(ctx, reconciler.Client, 100, testUtils.ValidClusterRole, TestNamespace)
+			Expect(err).NotTo(HaveOccurred())
+
+			By("Rejecting the Jira ticket before startTime")
+			jitRequest.Status.State = StatusPreApproved
+			jitRequest.Status.StartTime.Time = jitRequest.Spec.StartTime.Time
+			jitRequest.Status.JiraTicket = JiraTicket
+			testUtils.IssueStatus = "rejected"
+			jitConfig.JiraWorkflowApproveStatus = "Approved"
+			jitConfig.JiraWorkflowRejectedStatus = "rejected"
+			jitConfig.RetentionPeriod = &metav1.Duration{Duration: time.Hour}
+
+			result, err := reconciler.handlePreApproved(ctx, l, jitRequest, jitConfig)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.RequeueAfter).To(BeNumerically("~", time.Hour, time.Minute))
+
+			By("Checking the jitRequest is rejected without waiting for startTime")
+			err = reconciler.Get(ctx, types.NamespacedName{Name: JitRequestName}, jitRequest)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(jitRequest.Status.State).To(Equal(StatusRejected))
+			Expect(jitRequest.Status.Message).To(Equal(fmt.Sprintf("Jira ticket %s has been rejected", JiraTicket)))
+			Expect(jitRequest.Status.CompletionTime).NotTo(BeNil())
+			approved := meta.FindStatusCondition(jitRequest.Status.Conditions, v1.ConditionApproved)
+			Expect(approved).NotTo(BeNil())
+			Expect(approved.Status).To(Equal(metav1.ConditionFalse))
+			Expect(approved.Reason).To(Equal(ReasonJiraRejected))
+		})
+
+		It("should cancel an approved JitRequest waiting for startTime once the Jira ticket is closed", func() {
+			// Create JitRequest
+			jitRequest, err := testUtils.CreateJitRequest(ctx, reconciler.Client, 100, testUtils.ValidClusterRole, TestNamespace)
+			Expect(err).NotTo(HaveOccurred())
+
+			By("Approving the JitRequest before startTime")
+			jitRequest.Status.State = StatusPreApproved
+			jitRequest.Status.StartTime.Time = jitRequest.Spec.StartTime.Time
+			jitRequest.Status.JiraTicket = JiraTicket
+			testUtils.IssueStatus = "Approved"
+			jitConfig.JiraWorkflowApproveStatus = "Approved"
+			jitConfig.JiraWorkflowRejectedStatuses = []string{"Declined", "Closed"}
+
+			By("Checking the ticket is polled until startTime")
+			result, err := reconciler.handlePreApproved(ctx, l, jitRequest, jitConfig)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.RequeueAfter).To(Equal(DefaultApprovalPollInterval))
+			Expect(meta.IsStatusConditionTrue(jitRequest.Status.Conditions, v1.ConditionApproved)).To(BeTrue())
+
+			By("Closing the Jira ticket after the approval, before startTime")
+			testUtils.IssueStatus = "closed"
+			_, err = reconciler.handlePreApproved(ctx, l, jitRequest, jitConfig)
+			Expect(err).NotTo(HaveOccurred())
+
+			By("Checking the jitRequest is rejected without waiting for startTime")
+			err = reconciler.Get(ctx, types.NamespacedName{Name: JitRequestName}, jitRequest)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(jitRequest.Status.State).To(Equal(StatusRejected))
+			Expect(jitRequest.Status.JiraStatus).To(Equal("closed"))
+			Expect(meta.FindStatusCondition(jitRequest.Status.Conditions, v1.ConditionApproved).Reason).To(Equal(ReasonJiraRejected))
+		})
+
+		It("should keep polling after startTime within the approval grace period", func() {
+			// Create JitRequest
+			jitRequest, err := testUtils.CreateJitRequest(ctx, reconciler.Client, 10, testUtils.ValidClusterRole, TestNamespace)
+			Expect(err).NotTo(HaveOccurred())
+
+			By("Simulating a pending JitRequest past its startTime")
+			jitRequest.Status.State = StatusPreApproved
+			jitRequest.Status.StartTime = metav1.NewTime(metav1.Now().Add(-1 * time.Second))
+			jitRequest.Status.EndTime = metav1.NewTime(metav1.Now().Add(time.Hour))
+			jitRequest.Status.JiraTicket = JiraTicket
+			testUtils.IssueStatus = testUtils.TestJiraWorkflowToDoStatus
+			jitConfig.JiraWorkflowApproveStatus = "Approved"
+			jitConfig.ApprovalPollInterval = &metav1.Duration{Duration: 5 * time.Second}
+			jitConfig.ApprovalGracePeriod = &metav1.Duration{Duration: time.Minute}
+
+			result, err := reconciler.handlePreApproved(ctx, l, jitRequest, jitConfig)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.RequeueAfter).To(Equal(5 * time.Second))
+
+			By("Checking the jitRequest is still pending")
+			err = reconciler.Get(ctx, types.NamespacedName{Name: JitRequestName}, jitRequest)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(jitRequest.Status.State).To(Equal(StatusPreApproved))
+			Expect(jitRequest.Status.JiraStatus).To(Equal(testUtils.TestJiraWorkflowToDoStatus))
+		})
+
+		It("should keep polling a startOnApproval JitRequest until approved", func() {
+			// Create JitRequest
+			jitRequest, err := testUtils.CreateJitRequest(ctx, reconciler.Client, 10, testUtils.ValidClusterRole, TestNamespace)
+			Expect(err).NotTo(HaveOccurred())
+
+			By("Simulating a startOnApproval JitRequest pending approval")
+			jitRequest.Spec.StartOnApproval = true
+			jitRequest.Status.JiraTicket = JiraTicket
+			testUtils.IssueStatus = testUtils.TestJiraWorkflowToDoStatus
+
+			jitConfig.JiraWorkflowApproveStatus = "Approved"
+			result, err := reconciler.handlePreApproved(ctx, l, jitRequest, jitConfig)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.RequeueAfter).To(BeNumerically(">", 0))
+			Expect(result.RequeueAfter).To(BeNumerically("<=", DefaultApprovalPollInterval))
+
+			By("Checking the current Jira status is recorded")
+			err = reconciler.Get(ctx, types.NamespacedName{Name: JitRequestName}, jitRequest)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(jitRequest.Status.JiraStatus).To(Equal(testUtils.TestJiraWorkflowToDoStatus))
+		})
+
+		It("should start access on approval for a startOnApproval JitRequest", func() {
+			// Create JitRequest
+			jitRequest, err := testUtils.CreateJitRequest(ctx, reconciler.Client, 10, testUtils.ValidClusterRole, TestNamespace)
+			Expect(err).NotTo(HaveOccurred())
+
+			By("Approving a startOnApproval JitRequest with a duration")
+			jitRequest.Spec.StartOnApproval = true
+			jitRequest.Spec.StartTime = metav1.Time{}
+			jitRequest.Spec.EndTime = metav1.Time{}
+			jitRequest.Spec.Duration = &metav1.Duration{Duration: time.Hour}
+			jitRequest.Status.JiraTicket = JiraTicket
+			jiraWorkflowApproved := "Approved"
+			testUtils.IssueStatus = jiraWorkflowApproved
+
+			grantTime := time.Now()
+			jitConfig.CompletedTransitionID = "10"
+			jitConfig.JiraWorkflowApproveStatus = jiraWorkflowApproved
+			result, err := reconciler.handlePreApproved(ctx, l, jitRequest, jitConfig)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.RequeueAfter).To(BeNumerically(">", 0))
+
+			By("Checking the start and end time are set at grant time")
+			err = reconciler.Get(ctx, types.NamespacedName{Name: JitRequestName}, jitRequest)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(jitRequest.Status.State).To(Equal(StatusSucceeded))
+			Expect(jitRequest.Status.StartTime.Time).To(BeTemporally("~", grantTime, 5*time.Second))
+			Expect(jitRequest.Status.EndTime.Time).To(Equal(jitRequest.Status.StartTime.Add(time.Hour)))
 		})
 	})
 
@@ -317,10 +759,18 @@ var _ = Describe("JitRequestReconciler handlers Unit Tests", Ordered, Label("uni
 			jitRequest.Status.State = "Rejected"
 			jitRequest.Status.JiraTicket = "IAM-BAD"
 
-			result, err := reconciler.handleRejected(ctx, l, jitRequest, "1")
+			jitConfig.RejectedTransitionID = "1"
+			result, err := reconciler.handleRejected(ctx, l, jitRequest, jitConfig)
 			Expect(err).To(HaveOccurred())
 			Expect(err.Error()).To(ContainSubstring("no atlassian resource found"))
 			Expect(result.IsZero()).To(BeTrue())
+
+			By("Checking the rejection is persisted before the Jira ticket is updated")
+			err = reconciler.Get(ctx, types.NamespacedName{Name: JitRequestName}, jitRequest)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(jitRequest.Status.State).To(Equal(StatusRejected))
+			Expect(jitRequest.Status.CompletionTime).NotTo(BeNil())
+			Expect(meta.IsStatusConditionFalse(jitRequest.Status.Conditions, v1.ConditionJiraTicketClosed)).To(BeTrue())
 		})
 
 		It("should handle a rejected JitRequest", func() {
@@ -331,7 +781,9 @@ var _ = Describe("JitRequestReconciler handlers Unit Tests", Ordered, Label("uni
 			By("Rejecting the JitRequest")
 			jitRequest.Status.State = "Rejected"
 			jitRequest.Status.JiraTicket = JiraTicket
-			result, err := reconciler.handleRejected(ctx, l, jitRequest, "1")
+			jitConfig.RejectedTransitionID = "1"
+			jitConfig.RetentionPeriod = &metav1.Duration{}
+			result, err := reconciler.handleRejected(ctx, l, jitRequest, jitConfig)
 			Expect(err).NotTo(HaveOccurred())
 			Expect(result).NotTo(BeNil())
 			Expect(result.IsZero()).To(BeTrue())
@@ -340,6 +792,313 @@ var _ = Describe("JitRequestReconciler handlers Unit Tests", Ordered, Label("uni
 			err = testUtils.CheckJitRemoved(ctx, k8sClient, JitRequestName)
 			Expect(err).NotTo(HaveOccurred())
 		})
+
+		It("should keep a rejected JitRequest until the retention period has passed", func() {
+			// Create JitRequest
+			jitRequest, err := testUtils.CreateJitRequest(ctx, reconciler.Client, 10, testUtils.ValidClusterRole, TestNamespace)
+			Expect(err).NotTo(HaveOccurred())
+
+			By("Rejecting the JitRequest")
+			jitRequest.Status.State = StatusRejected
+			jitRequest.Status.Message = "Jira ticket has not been approved"
+			jitRequest.Status.JiraTicket = JiraTicket
+			jitConfig.RejectedTransitionID = "1"
+			jitConfig.RetentionPeriod = &metav1.Duration{Duration: time.Hour}
+			result, err := reconciler.handleRejected(ctx, l, jitRequest, jitConfig)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.RequeueAfter).To(BeNumerically(">", 0))
+
+			By("Checking the JitRequest is kept with a completion time")
+			err = reconciler.Get(ctx, types.NamespacedName{Name: JitRequestName}, jitRequest)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(jitRequest.Status.State).To(Equal(StatusRejected))
+			Expect(jitRequest.Status.Message).To(Equal("Jira ticket has not been approved"))
+			Expect(jitRequest.Status.CompletionTime).NotTo(BeNil())
+			Expect(meta.IsStatusConditionTrue(jitRequest.Status.Conditions, v1.ConditionJiraTicketClosed)).To(BeTrue())
+		})
+	})
+
+	Describe("handleRevoked", func() {
+
+		It("should remove role bindings and keep a revoked JitRequest", func() {
+			// Create JitRequest
+			jitRequest, err := testUtils.CreateJitRequest(ctx, reconciler.Client, 10, testUtils.ValidClusterRole, TestNamespace)
+			Expect(err).NotTo(HaveOccurred())
+
+			By("Creating the role binding")
+			err = reconciler.createRoleBinding(ctx, jitRequest)
+			Expect(err).NotTo(HaveOccurred())
+
+			By("Revoking the JitRequest")
+			jitRequest.Status.State = StatusSucceeded
+			jitRequest.Status.JiraTicket = JiraTicket
+			jitRequest.Spec.Revocation = &v1.RevocationSpec{
+				RevokedBy: "cpt-keyes@unsc.com",
+				Reason:    "incident resolved",
+			}
+			jitConfig.RetentionPeriod = &metav1.Duration{Duration: time.Hour}
+			result, err := reconciler.handleRevoked(ctx, l, jitRequest, jitConfig)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.RequeueAfter).To(BeNumerically(">", 0))
+
+			By("Checking the role binding is removed")
+			rb := &rbacv1.RoleBinding{}
+			rbNamespacedName := types.NamespacedName{
+				Namespace: TestNamespace,
+				Name:      fmt.Sprintf("%s-jit", jitRequest.Name),
+			}
+			err = reconciler.Get(ctx, rbNamespacedName, rb)
+			Expect(apierrors.IsNotFound(err)).To(BeTrue())
+
+			By("Checking the JitRequest is kept with a revoked status")
+			err = reconciler.Get(ctx, types.NamespacedName{Name: JitRequestName}, jitRequest)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(jitRequest.Status.State).To(Equal(StatusRevoked))
+			Expect(jitRequest.Status.Message).To(Equal("Access revoked by cpt-keyes@unsc.com | Reason: incident resolved"))
+			Expect(jitRequest.Status.CompletionTime).NotTo(BeNil())
+			Expect(meta.IsStatusConditionTrue(jitRequest.Status.Conditions, v1.ConditionRevoked)).To(BeTrue())
+			Expect(meta.IsStatusConditionFalse(jitRequest.Status.Conditions, v1.ConditionAccessGranted)).To(BeTrue())
+			Expect(meta.IsStatusConditionTrue(jitRequest.Status.Conditions, v1.ConditionJiraTicketClosed)).To(BeTrue())
+
+			By("Checking the Jira ticket is not commented again")
+			comments := testUtils.GetIssueComments(JiraTicket)
+			_, err = reconciler.handleFinished(ctx, l, jitRequest, jitConfig)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(testUtils.GetIssueComments(JiraTicket)).To(HaveLen(len(comments)))
+		})
+
+		It("should fail to revoke an invalid Jira Ticket", func() {
+			// Create JitRequest
+			jitRequest, err := testUtils.CreateJitRequest(ctx, reconciler.Client, 10, testUtils.ValidClusterRole, TestNamespace)
+			Expect(err).NotTo(HaveOccurred())
+
+			jitRequest.Status.JiraTicket = "IAM-BAD"
+			jitRequest.Spec.Revocation = &v1.RevocationSpec{
+				RevokedBy: "cpt-keyes@unsc.com",
+				Reason:    "incident resolved",
+			}
+			jitConfig.RetentionPeriod = &metav1.Duration{}
+			result, err := reconciler.handleRevoked(ctx, l, jitRequest, jitConfig)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("no atlassian resource found"))
+			Expect(result.IsZero()).To(BeTrue())
+
+			By("Checking the revoked state is kept with the Jira ticket pending")
+			err = reconciler.Get(ctx, types.NamespacedName{Name: JitRequestName}, jitRequest)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(jitRequest.Status.State).To(Equal(StatusRevoked))
+			Expect(meta.IsStatusConditionFalse(jitRequest.Status.Conditions, v1.ConditionJiraTicketClosed)).To(BeTrue())
+		})
+	})
+
+	Describe("handleSucceeded", func() {
+
+		It("should requeue a Succeeded JitRequest without an extension for clean-up", func() {
+			// Create JitRequest
+			jitRequest, err := testUtils.CreateJitRequest(ctx, reconciler.Client, 10, testUtils.ValidClusterRole, TestNamespace)
+			Expect(err).NotTo(HaveOccurred())
+
+			jitRequest.Status.EndTime = metav1.NewTime(metav1.Now().Add(10 * time.Second))
+			result, err := reconciler.handleSucceeded(ctx, l, jitRequest, jitConfig)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.IsZero()).To(BeFalse())
+		})
+
+		It("should send a new extension back for approval", func() {
+			// Create JitRequest
+			jitRequest, err := testUtils.CreateJitRequest(ctx, reconciler.Client, 10, testUtils.ValidClusterRole, TestNamespace)
+			Expect(err).NotTo(HaveOccurred())
+
+			By("Requesting an extension")
+			endTime := metav1.NewTime(metav1.Now().Add(30 * time.Second))
+			jitRequest.Status.State = StatusSucceeded
+			jitRequest.Status.JiraTicket = JiraTicket
+			jitRequest.Status.EndTime = metav1.NewTime(metav1.Now().Add(20 * time.Second))
+			jitRequest.Spec.Extension = &v1.ExtensionSpec{
+				EndTime: endTime,
+				Reason:  "incident ongoing",
+			}
+			result, err := reconciler.handleSucceeded(ctx, l, jitRequest, jitConfig)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.RequeueAfter).To(BeNumerically(">", 0))
+			Expect(result.RequeueAfter).To(BeNumerically("<=", DefaultApprovalPollInterval))
+
+			By("Checking the extension is pending approval")
+			err = reconciler.Get(ctx, types.NamespacedName{Name: JitRequestName}, jitRequest)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(jitRequest.Status.State).To(Equal(StatusSucceeded))
+			Expect(jitRequest.Status.Extension).NotTo(BeNil())
+			Expect(jitRequest.Status.Extension.State).To(Equal(v1.ExtensionPending))
+		})
+
+		It("should reject an extension that does not move the end time", func() {
+			// Create JitRequest
+			jitRequest, err := testUtils.CreateJitRequest(ctx, reconciler.Client, 10, testUtils.ValidClusterRole, TestNamespace)
+			Expect(err).NotTo(HaveOccurred())
+
+			By("Requesting an extension before the current end time")
+			jitRequest.Status.State = StatusSucceeded
+			jitRequest.Status.JiraTicket = JiraTicket
+			jitRequest.Status.EndTime = metav1.NewTime(metav1.Now().Add(20 * time.Second))
+			jitRequest.Spec.Extension = &v1.ExtensionSpec{
+				EndTime: metav1.NewTime(metav1.Now().Add(10 * time.Second)),
+				Reason:  "incident ongoing",
+			}
+			_, err = reconciler.handleSucceeded(ctx, l, jitRequest, jitConfig)
+			Expect(err).NotTo(HaveOccurred())
+
+			By("Checking the extension is rejected")
+			err = reconciler.Get(ctx, types.NamespacedName{Name: JitRequestName}, jitRequest)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(jitRequest.Status.Extension.State).To(Equal(v1.ExtensionRejected))
+			Expect(jitRequest.Status.Extension.Message).To(ContainSubstring("extension end time must be after current end time"))
+		})
+
+		It("should reject an extension beyond the role policy max duration", func() {
+			jitConfig.RolePolicies = map[string]v1.RolePolicySpec{
+				testUtils.ValidClusterRole: {MaxDuration: &metav1.Duration{Duration: time.Minute}},
+			}
+
+			// Create JitRequest
+			jitRequest, err := testUtils.CreateJitRequest(ctx, reconciler.Client, 10, testUtils.ValidClusterRole, TestNamespace)
+			Expect(err).NotTo(HaveOccurred())
+
+			By("Requesting an extension past the max duration from the start time")
+			jitRequest.Status.State = StatusSucceeded
+			jitRequest.Status.JiraTicket = JiraTicket
+			jitRequest.Status.StartTime = metav1.Now()
+			jitRequest.Status.EndTime = metav1.NewTime(metav1.Now().Add(20 * time.Second))
+			jitRequest.Spec.Extension = &v1.ExtensionSpec{
+				EndTime: metav1.NewTime(metav1.Now().Add(time.Hour)),
+				Reason:  "incident ongoing",
+			}
+			_, err = reconciler.handleSucceeded(ctx, l, jitRequest, jitConfig)
+			Expect(err).NotTo(HaveOccurred())
+
+			By("Checking the extension is rejected")
+			err = reconciler.Get(ctx, types.NamespacedName{Name: JitRequestName}, jitRequest)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(jitRequest.Status.Extension.State).To(Equal(v1.ExtensionRejected))
+			Expect(jitRequest.Status.Extension.Message).To(ContainSubstring("total duration must not exceed '1m0s'"))
+		})
+
+		It("should extend access once the extension is approved", func() {
+			// Create JitRequest
+			jitRequest, err := testUtils.CreateJitRequest(ctx, reconciler.Client, 10, testUtils.ValidClusterRole, TestNamespace)
+			Expect(err).NotTo(HaveOccurred())
+
+			By("Creating the role binding")
+			err = reconciler.createRoleBinding(ctx, jitRequest)
+			Expect(err).NotTo(HaveOccurred())
+
+			By("Simulating an approved pending extension")
+			endTime := metav1.NewTime(metav1.Now().Add(30 * time.Second).Truncate(time.Second))
+			jitRequest.Status.State = StatusSucceeded
+			jitRequest.Status.JiraTicket = JiraTicket
+			jitRequest.Status.EndTime = metav1.NewTime(metav1.Now().Add(20 * time.Second))
+			jitRequest.Spec.Extension = &v1.ExtensionSpec{
+				EndTime: endTime,
+				Reason:  "incident ongoing",
+			}
+			requestedAt := metav1.NewTime(metav1.Now().Add(-time.Minute))
+			jitRequest.Status.Extension = &v1.ExtensionStatus{
+				State:       v1.ExtensionPending,
+				EndTime:     endTime,
+				RequestedAt: &requestedAt,
+			}
+			jiraWorkflowApproved := "Approved"
+			jitConfig.JiraWorkflowApproveStatus = jiraWorkflowApproved
+			jitConfig.CompletedTransitionID = "10"
+			testUtils.IssueStatus = jiraWorkflowApproved
+			testUtils.IssueApprovedAt = time.Now().Format(utils.JiraDateTimeLayout)
+			DeferCleanup(func() { testUtils.IssueApprovedAt = "2025-01-20T21:01:46.000+0000" })
+
+			result, err := reconciler.handleSucceeded(ctx, l, jitRequest, jitConfig)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.IsZero()).To(BeFalse())
+
+			By("Checking the end time and role binding expiry have moved")
+			err = reconciler.Get(ctx, types.NamespacedName{Name: JitRequestName}, jitRequest)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(jitRequest.Status.Extension.State).To(Equal(v1.ExtensionApproved))
+			Expect(jitRequest.Status.EndTime.Time).To(BeTemporally("==", endTime.Time))
+
+			rb := &rbacv1.RoleBinding{}
+			rbNamespacedName := types.NamespacedName{
+				Namespace: TestNamespace,
+				Name:      fmt.Sprintf("%s-jit", jitRequest.Name),
+			}
+			err = reconciler.Get(ctx, rbNamespacedName, rb)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(rb.Annotations[ExpiryAnnotation]).To(Equal(endTime.Time.Format(time.RFC3339)))
+		})
+
+		It("should not approve an extension while the ticket is still approved from the original request", func() {
+			// Create JitRequest
+			jitRequest, err := testUtils.CreateJitRequest(ctx, reconciler.Client, 10, testUtils.ValidClusterRole, TestNamespace)
+			Expect(err).NotTo(HaveOccurred())
+
+			By("Simulating a pending extension on a ticket approved before it was requested")
+			endTime := metav1.NewTime(metav1.Now().Add(30 * time.Second).Truncate(time.Second))
+			previousEndTime := metav1.NewTime(metav1.Now().Add(20 * time.Second).Truncate(time.Second))
+			jitRequest.Status.State = StatusSucceeded
+			jitRequest.Status.JiraTicket = JiraTicket
+			jitRequest.Status.EndTime = previousEndTime
+			jitRequest.Spec.Extension = &v1.ExtensionSpec{
+				EndTime: endTime,
+				Reason:  "incident ongoing",
+			}
+			requestedAt := metav1.Now()
+			jitRequest.Status.Extension = &v1.ExtensionStatus{
+				State:       v1.ExtensionPending,
+				EndTime:     endTime,
+				RequestedAt: &requestedAt,
+			}
+			jiraWorkflowApproved := "Approved"
+			jitConfig.JiraWorkflowApproveStatus = jiraWorkflowApproved
+			testUtils.IssueStatus = jiraWorkflowApproved
+
+			result, err := reconciler.handleSucceeded(ctx, l, jitRequest, jitConfig)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.RequeueAfter).To(BeNumerically(">", 0))
+
+			By("Checking the extension is still pending and the end time has not moved")
+			err = reconciler.Get(ctx, types.NamespacedName{Name: JitRequestName}, jitRequest)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(jitRequest.Status.Extension.State).To(Equal(v1.ExtensionPending))
+			Expect(jitRequest.Status.EndTime.Time).To(BeTemporally("==", previousEndTime.Time))
+		})
+
+		It("should reject a pending extension not approved before end time", func() {
+			// Create JitRequest
+			jitRequest, err := testUtils.CreateJitRequest(ctx, reconciler.Client, 10, testUtils.ValidClusterRole, TestNamespace)
+			Expect(err).NotTo(HaveOccurred())
+
+			By("Simulating an expired pending extension")
+			endTime := metav1.NewTime(metav1.Now().Add(30 * time.Second))
+			jitRequest.Status.State = StatusSucceeded
+			jitRequest.Status.JiraTicket = JiraTicket
+			jitRequest.Status.EndTime = metav1.NewTime(metav1.Now().Add(-1 * time.Second))
+			jitRequest.Spec.Extension = &v1.ExtensionSpec{
+				EndTime: endTime,
+				Reason:  "incident ongoing",
+			}
+			jitRequest.Status.Extension = &v1.ExtensionStatus{
+				State:   v1.ExtensionPending,
+				EndTime: endTime,
+			}
+			testUtils.IssueStatus = testUtils.TestJiraWorkflowToDoStatus
+
+			result, err := reconciler.handleSucceeded(ctx, l, jitRequest, jitConfig)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.RequeueAfter).To(BeNumerically(">", 0))
+
+			By("Checking the JitRequest is kept as expired")
+			err = reconciler.Get(ctx, types.NamespacedName{Name: JitRequestName}, jitRequest)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(jitRequest.Status.State).To(Equal(StatusExpired))
+			Expect(jitRequest.Status.Extension.State).To(Equal(v1.ExtensionRejected))
+		})
 	})
 
 	Describe("handleCleanup", func() {
@@ -350,7 +1109,7 @@ var _ = Describe("JitRequestReconciler handlers Unit Tests", Ordered, Label("uni
 			Expect(err).NotTo(HaveOccurred())
 
 			jitRequest.Status.EndTime = metav1.NewTime(metav1.Now().Add(10 * time.Second))
-			result, err := reconciler.handleCleanup(ctx, l, jitRequest)
+			result, err := reconciler.handleCleanup(ctx, l, jitRequest, jitConfig)
 			Expect(err).NotTo(HaveOccurred())
 			Expect(result).NotTo(BeNil())
 			Expect(result.IsZero()).To(BeFalse())
@@ -363,7 +1122,8 @@ var _ = Describe("JitRequestReconciler handlers Unit Tests", Ordered, Label("uni
 
 			By("Simulating an expired JitRequest")
 			jitRequest.Status.EndTime = metav1.NewTime(metav1.Now().Add(-1 * time.Second))
-			result, err := reconciler.handleCleanup(ctx, l, jitRequest)
+			jitConfig.RetentionPeriod = &metav1.Duration{}
+			result, err := reconciler.handleCleanup(ctx, l, jitRequest, jitConfig)
 			Expect(err).NotTo(HaveOccurred())
 			Expect(result).NotTo(BeNil())
 			Expect(result.IsZero()).To(BeTrue())
@@ -372,7 +1132,149 @@ var _ = Describe("JitRequestReconciler handlers Unit Tests", Ordered, Label("uni
 			err = testUtils.CheckJitRemoved(ctx, k8sClient, JitRequestName)
 			Expect(err).NotTo(HaveOccurred())
 		})
+
+		It("should keep an expired JitRequest until the retention period has passed", func() {
+			// Create JitRequest
+			jitRequest, err := testUtils.CreateJitRequest(ctx, reconciler.Client, 10, testUtils.ValidClusterRole, TestNamespace)
+			Expect(err).NotTo(HaveOccurred())
+
+			By("Simulating an expired JitRequest")
+			jitRequest.Status.State = StatusSucceeded
+			jitRequest.Status.JiraTicket = JiraTicket
+			jitRequest.Status.EndTime = metav1.NewTime(metav1.Now().Add(-1 * time.Second))
+			jitConfig.RetentionPeriod = &metav1.Duration{Duration: time.Hour}
+			result, err := reconciler.handleCleanup(ctx, l, jitRequest, jitConfig)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.RequeueAfter).To(BeNumerically(">", 0))
+
+			By("Checking the JitRequest is kept as expired")
+			err = reconciler.Get(ctx, types.NamespacedName{Name: JitRequestName}, jitRequest)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(jitRequest.Status.State).To(Equal(StatusExpired))
+			Expect(jitRequest.Status.Message).To(Equal("Access expired at end time"))
+			Expect(jitRequest.Status.CompletionTime).NotTo(BeNil())
+			Expect(meta.IsStatusConditionTrue(jitRequest.Status.Conditions, v1.ConditionExpired)).To(BeTrue())
+			Expect(meta.IsStatusConditionFalse(jitRequest.Status.Conditions, v1.ConditionAccessGranted)).To(BeTrue())
+
+			By("Deleting the JitRequest once the retention period has passed")
+			result, err = reconciler.handleRetention(ctx, l, jitRequest, 0)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.IsZero()).To(BeTrue())
+			err = testUtils.CheckJitRemoved(ctx, k8sClient, JitRequestName)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("should comment and transition the Jira ticket when access expires", func() {
+			// Create JitRequest
+			jitRequest, err := testUtils.CreateJitRequest(ctx, reconciler.Client, 10, testUtils.ValidClusterRole, TestNamespace)
+			Expect(err).NotTo(HaveOccurred())
+
+			By("Simulating an expired JitRequest")
+			jitRequest.Status.State = StatusSucceeded
+			jitRequest.Status.JiraTicket = JiraTicket
+			jitRequest.Status.EndTime = metav1.NewTime(metav1.Now().Add(-1 * time.Second))
+			jitConfig.ExpiredTransitionID = "Expire"
+			jitConfig.RetentionPeriod = &metav1.Duration{Duration: time.Hour}
+			result, err := reconciler.handleCleanup(ctx, l, jitRequest, jitConfig)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.RequeueAfter).To(BeNumerically(">", 0))
+
+			By("Checking the Jira ticket is commented with the revocation time")
+			err = reconciler.Get(ctx, types.NamespacedName{Name: JitRequestName}, jitRequest)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(jitRequest.Status.State).To(Equal(StatusExpired))
+			comments := testUtils.GetIssueComments(JiraTicket)
+			Expect(comments).NotTo(BeEmpty())
+			Expect(comments[len(comments)-1]).To(ContainSubstring("Expired - Access has been revoked at end time"))
+			Expect(comments[len(comments)-1]).To(ContainSubstring(fmt.Sprintf("|*Revoked At*|%s|", jitRequest.Status.CompletionTime.Format(time.RFC3339))))
+			Expect(meta.IsStatusConditionTrue(jitRequest.Status.Conditions, v1.ConditionJiraTicketClosed)).To(BeTrue())
+
+			By("Checking the Jira ticket is not commented again on the next reconcile")
+			_, err = reconciler.handleFinished(ctx, l, jitRequest, jitConfig)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(testUtils.GetIssueComments(JiraTicket)).To(HaveLen(len(comments)))
+		})
+
+		It("should retry the Jira update of an expired JitRequest that is still pending", func() {
+			// Create JitRequest
+			jitRequest, err := testUtils.CreateJitRequest(ctx, reconciler.Client, 10, testUtils.ValidClusterRole, TestNamespace)
+			Expect(err).NotTo(HaveOccurred())
+
+			By("Simulating an expired JitRequest whose Jira update failed")
+			now := metav1.Now()
+			jitRequest.Status.State = StatusExpired
+			jitRequest.Status.JiraTicket = JiraTicket
+			jitRequest.Status.CompletionTime = &now
+			meta.SetStatusCondition(&jitRequest.Status.Conditions, metav1.Condition{
+				Type:   v1.ConditionJiraTicketClosed,
+				Status: metav1.ConditionFalse,
+				Reason: ReasonJiraTicketPending,
+			})
+			comments := testUtils.GetIssueComments(JiraTicket)
+			jitConfig.RetentionPeriod = &metav1.Duration{Duration: time.Hour}
+			result, err := reconciler.handleFinished(ctx, l, jitRequest, jitConfig)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.RequeueAfter).To(BeNumerically(">", 0))
+
+			By("Checking the Jira ticket is commented once")
+			Expect(testUtils.GetIssueComments(JiraTicket)).To(HaveLen(len(comments) + 1))
+			err = reconciler.Get(ctx, types.NamespacedName{Name: JitRequestName}, jitRequest)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(meta.IsStatusConditionTrue(jitRequest.Status.Conditions, v1.ConditionJiraTicketClosed)).To(BeTrue())
+		})
+
+		It("should fail to expire an invalid Jira Ticket", func() {
+			// Create JitRequest
+			jitRequest, err := testUtils.CreateJitRequest(ctx, reconciler.Client, 10, testUtils.ValidClusterRole, TestNamespace)
+			Expect(err).NotTo(HaveOccurred())
+
+			jitRequest.Status.JiraTicket = "IAM-BAD"
+			jitRequest.Status.EndTime = metav1.NewTime(metav1.Now().Add(-1 * time.Second))
+			result, err := reconciler.handleCleanup(ctx, l, jitRequest, jitConfig)
+			Expect(err).To(HaveOccurred())
+			Expect(result.IsZero()).To(BeTrue())
+		})
+	})
+	Describe("handleJiraAvailability", func() {
+
+		It("should requeue a JitRequest while Jira is unavailable", func() {
+			// Create JitRequest
+			jitRequest, err := testUtils.CreateJitRequest(ctx, reconciler.Client, 10, testUtils.ValidClusterRole, TestNamespace)
+			Expect(err).NotTo(HaveOccurred())
+
+			By("Simulating an open circuit breaker")
+			unavailableErr := &resilience.UnavailableError{RetryAfter: 30 * time.Second}
+			result, err := reconciler.handleJiraAvailability(ctx, l, jitRequest, ctrl.Result{}, unavailableErr)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.RequeueAfter).To(Equal(30 * time.Second))
+			Expect(fakeRecorder.Events).To(Receive(ContainSubstring(EventJiraUnavailable)))
+
+			By("Checking the JitRequest reports Jira as unavailable")
+			err = reconciler.Get(ctx, types.NamespacedName{Name: JitRequestName}, jitRequest)
+			Expect(err).NotTo(HaveOccurred())
+			condition := meta.FindStatusCondition(jitRequest.Status.Conditions, v1.ConditionJiraAvailable)
+			Expect(condition).NotTo(BeNil())
+			Expect(condition.Status).To(Equal(metav1.ConditionFalse))
+			Expect(condition.Reason).To(Equal(ReasonJiraUnavailable))
+
+			By("Checking the condition is cleared once a reconcile succeeds")
+			result, err = reconciler.handleJiraAvailability(ctx, l, jitRequest, ctrl.Result{RequeueAfter: time.Minute}, nil)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.RequeueAfter).To(Equal(time.Minute))
+			err = reconciler.Get(ctx, types.NamespacedName{Name: JitRequestName}, jitRequest)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(meta.IsStatusConditionTrue(jitRequest.Status.Conditions, v1.ConditionJiraAvailable)).To(BeTrue())
+		})
+
+		It("should return other errors", func() {
+			jitRequest := &v1.JitRequest{}
+			otherErr := errors.New("some other error")
+			result, err := reconciler.handleJiraAvailability(ctx, l, jitRequest, ctrl.Result{}, otherErr)
+			Expect(err).To(Equal(otherErr))
+			Expect(result).To(Equal(ctrl.Result{}))
+		})
 	})
+
 	Describe("handleFetchError", func() {
 		jitRequest := &v1.JitRequest{}
 		notFoundErr := apierrors.NewNotFound(schema.GroupResource{Group: "justintimev1", Resource: "JitRequest"}, "test")
@@ -7,10 +7,12 @@ import (
 	"time"
 
 	justintimev1 "jira-jit-rbac-operator/api/v1"
+	"jira-jit-rbac-operator/pkg/templates"
 	"jira-jit-rbac-operator/pkg/utils"
 
 	"github.com/ctreminiom/go-atlassian/v2/pkg/infra/models"
 	"github.com/go-logr/logr"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 )
@@ -25,7 +27,7 @@ func (r *JitRequestReconciler) updateJiraTicket(ctx context.Context, jiraTicket,
 	payload := &models.CommentPayloadSchemeV2{
 		Body: comment,
 	}
-	_, response, err := r.JiraClient.Issue.Comment.Add(context.Background(), jiraTicket, payload, nil)
+	_, response, err := r.JiraClient.Issue.Comment.Add(ctx, jiraTicket, payload, nil)
 	if err != nil {
 		if response != nil {
 			body := response.Bytes.String()
@@ -40,45 +42,30 @@ func (r *JitRequestReconciler) updateJiraTicket(ctx context.Context, jiraTicket,
 }
 
 // completeJiraTicket completes a jira ticket with a comment
-func (r *JitRequestReconciler) completeJiraTicket(ctx context.Context, jitRequest *justintimev1.JitRequest, completedTransitionID string) error {
+func (r *JitRequestReconciler) completeJiraTicket(ctx context.Context, jitRequest *justintimev1.JitRequest, completedTransition string, jiraTemplates *templates.Templates) error {
 	l := log.FromContext(ctx)
 
 	// Add a comment to the Jira issue
 	jiraTicket := jitRequest.Status.JiraTicket
-	comment := "{color:#00875a}*Completed - Access granted until end time*{color}"
+	comment, err := jiraTemplates.Render(templates.CompletedComment, jitRequest, "")
+	if err != nil {
+		return err
+	}
 	l.Info("Completing Jira ticket", "jiraTicket", jiraTicket)
 	if err := r.updateJiraTicket(ctx, jiraTicket, comment); err != nil {
 		return err
 	}
 
 	// Complete ticket
-	options := &models.IssueMoveOptionsV2{
-		Fields: &models.IssueSchemeV2{
-			Fields: &models.IssueFieldsSchemeV2{
-				Resolution: &models.ResolutionScheme{},
-			},
-		},
-	}
-	response, err := r.JiraClient.Issue.Move(context.Background(), jiraTicket, completedTransitionID, options)
-	if err != nil {
-		if response != nil {
-			body := response.Bytes.String()
-			l.Error(err, "failed to transition jira ticket to completed", "response", body)
-		} else {
-			l.Error(err, "failed to transition jira ticket to completed", "jiraTicket", jiraTicket, "response", "nil response")
-		}
-		return err
-	}
-
-	return nil
+	return r.transitionJiraTicket(ctx, jitRequest, completedTransition, resolutionOptions())
 }
 
-// getJiraApproval checks a Jira ticket is approved
-func (r *JitRequestReconciler) getJiraApproval(ctx context.Context, jitRequest *justintimev1.JitRequest, jiraWorkflowApproveStatus string) error {
+// getJiraApproval checks a Jira ticket is approved, by its status or by a quorum of distinct approvers for the approval mode
+func (r *JitRequestReconciler) getJiraApproval(ctx context.Context, jitRequest *justintimev1.JitRequest, operatorConfig *justintimev1.JustInTimeConfigSpec) error {
 	l := log.FromContext(ctx)
-	l.Info("Checking Jira ticket approval", "jit request", jitRequest)
-
 	jiraIssueKey := jitRequest.Status.JiraTicket
+	jiraWorkflowApproveStatus := operatorConfig.JiraWorkflowApproveStatus
+	l.Info("Checking Jira ticket approval", "jira ticket", jiraIssueKey, "jira status", jitRequest.Status.JiraStatus)
 
 	// Fetch the Jira issue details
 	issue, response, err := r.JiraClient.Issue.Get(ctx, jiraIssueKey, nil, nil)
@@ -92,42 +79,150 @@ func (r *JitRequestReconciler) getJiraApproval(ctx context.Context, jitRequest *
 		return err
 	}
 
-	// Check if the issue status is Approved
-	if issue.Fields.Status.Name == jiraWorkflowApproveStatus {
-		l.Info("Jira ticket is approved", "jiraTicket", jiraIssueKey)
+	// Record the current status, persisted with the next status update
+	jitRequest.Status.JiraStatus = issue.Fields.Status.Name
+
+	extension := jitRequest.Status.Extension
+	extensionPending := extension != nil && extension.State == justintimev1.ExtensionPending
+	required := utils.RequiredApprovals(operatorConfig, jitRequest.Spec.ClusterRole)
+
+	var approvals []utils.JiraApproval
+	switch operatorConfig.ApprovalMode {
+	case justintimev1.ApprovalModeServiceDesk:
+		approvals, err = utils.GetServiceDeskApprovals(ctx, jiraIssueKey, r.JiraClient, r.JiraFlavour)
+	case justintimev1.ApprovalModeChangelog:
+		// the ticket must still be approved, approvers are who moved it to the approved status
+		if issue.Fields.Status.Name != jiraWorkflowApproveStatus {
+			return fmt.Errorf("failed on jira approval")
+		}
+		approvals, err = utils.GetChangelogApprovals(ctx, jiraIssueKey, jiraWorkflowApproveStatus, r.JiraClient, r.JiraFlavour)
+	default:
+		// Check if the issue status is Approved
+		if issue.Fields.Status.Name != jiraWorkflowApproveStatus {
+			return fmt.Errorf("failed on jira approval")
+		}
+		if !extensionPending && operatorConfig.SelfApprovalEnabled {
+			l.Info("Jira ticket is approved", "jiraTicket", jiraIssueKey)
+			return nil
+		}
+		// the approver is who last moved the ticket to the approved status, which can't be the reporter unless self-approval
+		// is enabled. The status may be left over from the original approval, an extension needs the ticket moved to the approved status again.
+		approvals, err = utils.GetChangelogApprovals(ctx, jiraIssueKey, jiraWorkflowApproveStatus, r.JiraClient, r.JiraFlavour)
+		if !extensionPending && len(approvals) > 0 {
+			approvals = approvals[len(approvals)-1:]
+		}
+		required = 1
+	}
+	if err != nil {
+		l.Error(err, "failed to fetch Jira ticket approvals", "jiraTicket", jiraIssueKey)
+		return err
+	}
+
+	// the reporter can't approve their own request
+	var excluded []string
+	if !operatorConfig.SelfApprovalEnabled {
+		excluded = append(excluded, jitRequest.Spec.Reporter)
+		reporterName, err := utils.GetNameByEmail(ctx, jitRequest.Spec.Reporter, r.JiraClient, r.JiraFlavour)
+		if err != nil {
+			l.Error(err, "failed to get reporter jira user", "reporter", jitRequest.Spec.Reporter)
+			return err
+		}
+		excluded = append(excluded, reporterName)
+	}
+
+	// Record the approvers, persisted with the next status update. Extensions only count approvals after they were requested.
+	var distinct []justintimev1.Approval
+	if extensionPending {
+		var since time.Time
+		if extension.RequestedAt != nil {
+			since = extension.RequestedAt.Time
+		}
+		distinct = utils.DistinctApprovals(approvals, extension.Approvals, excluded, since, time.Now())
+		extension.Approvals = distinct
+	} else {
+		distinct = utils.DistinctApprovals(approvals, jitRequest.Status.Approvals, excluded, time.Time{}, time.Now())
+		jitRequest.Status.Approvals = distinct
+	}
+
+	if len(distinct) >= required {
+		l.Info("Jira ticket is approved", "jiraTicket", jiraIssueKey, "approvals", len(distinct))
 		return nil
 	}
 
-	return fmt.Errorf("failed on jira approval")
+	return fmt.Errorf("failed on jira approval, %d of %d required approvals", len(distinct), required)
 }
 
-// addCustomField is a helper function for createJiraTicket to build custom fields in jira ticket payload
-func addCustomField(ctx context.Context, customFields *models.CustomFields, fieldType, jiraCustomField, value string) {
+// recordJiraApproval records who approved a Jira ticket, when and its status for auditing, persisted with the next status update.
+// The status approval mode only checks the status, so the approver is who last moved the ticket to the approved status in its changelog.
+func (r *JitRequestReconciler) recordJiraApproval(ctx context.Context, jitRequest *justintimev1.JitRequest, operatorConfig *justintimev1.JustInTimeConfigSpec) {
 	l := log.FromContext(ctx)
 
-	switch fieldType {
-	case "text", "date":
-		if err := customFields.Text(jiraCustomField, value); err != nil {
-			l.Error(err, "failed to add custom field", "field", jiraCustomField)
-		}
-	case "select":
-		if err := customFields.Select(jiraCustomField, value); err != nil {
-			l.Error(err, "failed to add custom field", "field", jiraCustomField)
+	if jitRequest.Status.ApprovedAt != nil {
+		return
+	}
+
+	now := time.Now()
+	if operatorConfig.ApprovalMode == "" || operatorConfig.ApprovalMode == justintimev1.ApprovalModeStatus {
+		jiraIssueKey := jitRequest.Status.JiraTicket
+		approvals, err := utils.GetChangelogApprovals(ctx, jiraIssueKey, operatorConfig.JiraWorkflowApproveStatus, r.JiraClient, r.JiraFlavour)
+		if err != nil {
+			// the audit trail is best effort, access is not held back for it
+			l.Error(err, "failed to get approvers from the jira changelog", "jiraTicket", jiraIssueKey)
+			r.raiseEvent(jitRequest, "Warning", EventFailedApprovalAudit, fmt.Sprintf("Error: %s", err))
+		} else if len(approvals) > 0 {
+			jitRequest.Status.Approvals = utils.DistinctApprovals(approvals[len(approvals)-1:], nil, nil, time.Time{}, now)
 		}
-	case "user":
-		userField := map[string]interface{}{
-			"name": value,
+	}
+
+	// approved once the last approval needed was given, or now if the approvers are not known
+	var approvedAt *metav1.Time
+	for i := range jitRequest.Status.Approvals {
+		if approval := &jitRequest.Status.Approvals[i]; approvedAt == nil || approvedAt.Before(&approval.ApprovedAt) {
+			approvedAt = approval.ApprovedAt.DeepCopy()
 		}
-		if err := customFields.Raw(jiraCustomField, userField); err != nil {
-			l.Error(err, "failed to add custom field", "field", jiraCustomField)
+	}
+	if approvedAt == nil {
+		approvedAt = &metav1.Time{Time: now}
+	}
+	jitRequest.Status.ApprovedAt = approvedAt
+	jitRequest.Status.ApprovedJiraStatus = jitRequest.Status.JiraStatus
+}
+
+// addCustomField is a helper function for createJiraTicket to build custom fields in jira ticket payload
+func addCustomField(customFields *models.CustomFields, flavour utils.JiraFlavour, fieldType, jiraCustomField, value string) error {
+	fieldValue, err := utils.JiraFieldValue(flavour, fieldType, value)
+	if err != nil {
+		return err
+	}
+	return customFields.Raw(jiraCustomField, fieldValue)
+}
+
+// jiraAccountIDs returns the accountIds of the comma separated user emails of a field for Jira Cloud
+func (r *JitRequestReconciler) jiraAccountIDs(ctx context.Context, value string) (string, error) {
+	accountIds := []string{}
+	for _, email := range utils.SplitFieldValues(value) {
+		accountId, err := utils.GetNameByEmail(ctx, email, r.JiraClient, r.JiraFlavour)
+		if err != nil {
+			return "", err
 		}
-	default:
-		l.Error(fmt.Errorf("unknown custom field type"), jiraCustomField, "type", fieldType)
+		accountIds = append(accountIds, accountId)
 	}
+	return strings.Join(accountIds, ","), nil
 }
 
-// createJiraTicket creates a jira ticket for a JitRequest
-func (r *JitRequestReconciler) createJiraTicket(ctx context.Context, jitRequest *justintimev1.JitRequest, jiraProject, jiraIssueType string, customFieldsConfig map[string]justintimev1.CustomFieldSettings, requiredFieldsConfig *justintimev1.RequiredFieldsSpec, ticketLabels []string, targetEnvironment *justintimev1.EnvironmentSpec) (string, error) {
+// rejectJiraField rejects a JitRequest with a missing or invalid jira field before a ticket is created
+func (r *JitRequestReconciler) rejectJiraField(ctx context.Context, jitRequest *justintimev1.JitRequest, reason, msg string) (string, error) {
+	l := log.FromContext(ctx)
+
+	setCondition(jitRequest, justintimev1.ConditionTicketCreated, metav1.ConditionFalse, reason, msg)
+	if err := r.updateStatus(ctx, jitRequest, StatusRejected, msg, Skipped); err != nil {
+		l.Error(err, "failed to update status to Rejected")
+	}
+	return Skipped, nil
+}
+
+// createJiraTicket creates a jira ticket for a JitRequest, with dates formatted in the location
+func (r *JitRequestReconciler) createJiraTicket(ctx context.Context, jitRequest *justintimev1.JitRequest, jiraProject, jiraIssueType string, serviceDesk *justintimev1.ServiceDeskSpec, customFieldsConfig map[string]justintimev1.CustomFieldSettings, requiredFieldsConfig *justintimev1.RequiredFieldsSpec, ticketLabels []string, targetEnvironment *justintimev1.EnvironmentSpec, location *time.Location, jiraTemplates *templates.Templates) (string, error) {
 	l := log.FromContext(ctx)
 
 	l.Info("Creating Jira ticket", "jiraTicket", jitRequest)
@@ -139,20 +234,31 @@ func (r *JitRequestReconciler) createJiraTicket(ctx context.Context, jitRequest
 		value, exists := jitRequest.Spec.JiraFields[fieldName]
 		if !exists {
 			// missing field, reject
-			errMsg := fmt.Errorf("missing custom field: %s", fieldName)
-			if err := r.updateStatus(ctx, jitRequest, StatusRejected, errMsg.Error(), Skipped); err != nil {
-				l.Error(err, "failed to update status to Rejected")
-				return Skipped, nil
+			errMsg := fmt.Sprintf("missing custom field: %s", fieldName)
+			return r.rejectJiraField(ctx, jitRequest, ReasonMissingJiraField, errMsg)
+		}
+		// Jira Cloud references users by accountId, not the email
+		if (settings.Type == justintimev1.FieldTypeUser || settings.Type == justintimev1.FieldTypeMultiUser) && r.JiraFlavour == utils.JiraCloud {
+			accountIds, err := r.jiraAccountIDs(ctx, value)
+			if err != nil {
+				l.Error(err, "failed to create Jira ticket", "field", fieldName)
+				return "", err
 			}
-			return Skipped, nil
+			value = accountIds
+		}
+		if err := addCustomField(&customFields, r.JiraFlavour, settings.Type, settings.JiraCustomField, value); err != nil {
+			// invalid field, reject
+			errMsg := fmt.Sprintf("invalid custom field %s: %s", fieldName, err)
+			return r.rejectJiraField(ctx, jitRequest, ReasonInvalidJiraField, errMsg)
 		}
-		addCustomField(ctx, &customFields, settings.Type, settings.JiraCustomField, value)
 	}
 
 	// Add required fields for StartTime, EndTime, ClusterRole
+	// startOnApproval requests use the current time as an estimate of the start time
+	startTime, endTime := utils.RequestedTimes(jitRequest, time.Now())
 	requiredFields := map[string]string{
-		"StartTime":   jitRequest.Spec.StartTime.Format("2006-01-02T15:04:05.000-0700"),
-		"EndTime":     jitRequest.Spec.EndTime.Format("2006-01-02T15:04:05.000-0700"),
+		"StartTime":   utils.FormatJiraTime(startTime, requiredFieldsConfig.StartTime.Type, location),
+		"EndTime":     utils.FormatJiraTime(endTime, requiredFieldsConfig.EndTime.Type, location),
 		"ClusterRole": jitRequest.Spec.ClusterRole,
 	}
 
@@ -169,11 +275,15 @@ func (r *JitRequestReconciler) createJiraTicket(ctx context.Context, jitRequest
 			l.Error(fmt.Errorf("unknown required field"), "field", fieldName)
 			continue
 		}
-		addCustomField(ctx, &customFields, settings.Type, settings.JiraCustomField, value)
+		if err := addCustomField(&customFields, r.JiraFlavour, settings.Type, settings.JiraCustomField, value); err != nil {
+			// invalid field, reject
+			errMsg := fmt.Sprintf("invalid required field %s: %s", fieldName, err)
+			return r.rejectJiraField(ctx, jitRequest, ReasonInvalidJiraField, errMsg)
+		}
 	}
 
 	// Get Jira account ID from reporter email
-	reporterAccountName, err := utils.GetNameByEmail(jitRequest.Spec.Reporter, r.JiraClient)
+	reporterAccountName, err := utils.GetNameByEmail(ctx, jitRequest.Spec.Reporter, r.JiraClient, r.JiraFlavour)
 	if err != nil {
 		l.Error(err, "failed to create Jira ticket")
 		return "", err
@@ -188,11 +298,32 @@ func (r *JitRequestReconciler) createJiraTicket(ctx context.Context, jitRequest
 		targetCluster,
 		targetEnv,
 	)
+	// tag the ticket with the JitRequest to find it again if the status can't be updated
+	if label := utils.JiraTicketLabel(jitRequest); label != "" {
+		combinedLabels = append(combinedLabels, label)
+	}
+
+	summary, err := jiraTemplates.Render(templates.Summary, jitRequest, "")
+	if err != nil {
+		l.Error(err, "failed to create Jira ticket")
+		return "", err
+	}
+	description, err := jiraTemplates.Render(templates.Description, jitRequest, "")
+	if err != nil {
+		l.Error(err, "failed to create Jira ticket")
+		return "", err
+	}
+
+	// create as a customer request through the service desk portal
+	if serviceDesk != nil {
+		return r.createJiraCustomerRequest(ctx, serviceDesk, reporterAccountName, summary, description, combinedLabels, &customFields)
+	}
 
 	// payload for new jira ticket
 	payload := models.IssueSchemeV2{
 		Fields: &models.IssueFieldsSchemeV2{
-			Summary: fmt.Sprintf("Automated JIT request for %s", jitRequest.Spec.Reporter),
+			Summary:     summary,
+			Description: description,
 			Project: &models.ProjectScheme{
 				Key: jiraProject,
 			},
@@ -200,10 +331,8 @@ func (r *JitRequestReconciler) createJiraTicket(ctx context.Context, jitRequest
 				Name: jiraIssueType,
 			},
 			// Set reporter as per userID
-			Reporter: &models.UserScheme{
-				Name: reporterAccountName,
-			},
-			Labels: combinedLabels,
+			Reporter: utils.JiraUser(r.JiraFlavour, reporterAccountName),
+			Labels:   combinedLabels,
 		},
 	}
 
@@ -211,7 +340,7 @@ func (r *JitRequestReconciler) createJiraTicket(ctx context.Context, jitRequest
 	// l.Info("Jira Issue Payload", "payload", payload)
 	// l.Info("Custom Fields Data", "customFields", customFields)
 
-	createdIssue, response, err := r.JiraClient.Issue.Create(context.Background(), &payload, &customFields)
+	createdIssue, response, err := r.JiraClient.Issue.Create(ctx, &payload, &customFields)
 	if err != nil {
 		if response != nil {
 			body := response.Bytes.String()
@@ -226,33 +355,250 @@ func (r *JitRequestReconciler) createJiraTicket(ctx context.Context, jitRequest
 	return createdIssue.Key, nil
 }
 
+// ensureJiraTicket returns the Jira ticket of a new JitRequest, creating it only if a previous reconcile did not,
+// returns true if the ticket was created
+func (r *JitRequestReconciler) ensureJiraTicket(ctx context.Context, jitRequest *justintimev1.JitRequest, operatorConfig *justintimev1.JustInTimeConfigSpec, location *time.Location) (string, bool, error) {
+	l := log.FromContext(ctx)
+
+	// recorded once created
+	if jiraTicket := jitRequest.Status.JiraTicket; jiraTicket != "" && jiraTicket != Skipped {
+		l.Info("Reusing Jira ticket", "jiraTicket", jiraTicket)
+		return jiraTicket, false, nil
+	}
+
+	// created, but the status update failed
+	jiraTicket, err := utils.FindJiraTicket(ctx, utils.JiraTicketLabel(jitRequest), r.JiraClient, r.JiraFlavour)
+	if err != nil {
+		l.Error(err, "failed to search for an existing Jira ticket")
+		return "", false, err
+	}
+	if jiraTicket != "" {
+		l.Info("Reusing Jira ticket found by label", "jiraTicket", jiraTicket, "label", utils.JiraTicketLabel(jitRequest))
+		return jiraTicket, false, nil
+	}
+
+	jiraTicket, err = r.createJiraTicket(ctx, jitRequest, operatorConfig.JiraProject, operatorConfig.JiraIssueType, operatorConfig.ServiceDesk, operatorConfig.CustomFields, operatorConfig.RequiredFields, operatorConfig.Labels, operatorConfig.Environment, location, templates.New(operatorConfig))
+	if err != nil || jiraTicket == Skipped {
+		return jiraTicket, false, err
+	}
+
+	// record the ticket before anything else can fail, so a retry reuses it
+	setCondition(jitRequest, justintimev1.ConditionTicketCreated, metav1.ConditionTrue, ReasonJiraTicketCreated, fmt.Sprintf("Jira ticket %s created", jiraTicket))
+	if err := r.updateStatus(ctx, jitRequest, jitRequest.Status.State, jitRequest.Status.Message, jiraTicket); err != nil {
+		l.Error(err, "failed to record Jira ticket, it is found by its label on retry", "jiraTicket", jiraTicket)
+		return "", false, err
+	}
+	return jiraTicket, true, nil
+}
+
+// createJiraCustomerRequest creates a Jira Service Management customer request on behalf of the reporter with the same fields as a ticket
+func (r *JitRequestReconciler) createJiraCustomerRequest(ctx context.Context, serviceDesk *justintimev1.ServiceDeskSpec, reporterAccountName, summary, description string, labels []string, customFields *models.CustomFields) (string, error) {
+	l := log.FromContext(ctx)
+
+	// request field values are keyed by field ID like issue fields
+	fieldValues := map[string]interface{}{
+		"summary": summary,
+	}
+	if description != "" {
+		fieldValues["description"] = description
+	}
+	for _, field := range customFields.Fields {
+		for _, values := range field {
+			if values, ok := values.(map[string]interface{}); ok {
+				for fieldID, value := range values {
+					fieldValues[fieldID] = value
+				}
+			}
+		}
+	}
+
+	jiraIssueKey, err := utils.CreateCustomerRequest(ctx, serviceDesk, reporterAccountName, fieldValues, r.JiraClient)
+	if err != nil {
+		l.Error(err, "failed to create Jira customer request", "serviceDeskID", serviceDesk.ServiceDeskID, "requestTypeID", serviceDesk.RequestTypeID, "fieldValues", fieldValues)
+		return "", err
+	}
+
+	// request types rarely have a labels field, set them on the created ticket instead.
+	// The request exists at this point, so a failure is only logged to not create it again.
+	payload := &models.IssueSchemeV2{
+		Fields: &models.IssueFieldsSchemeV2{
+			Labels: labels,
+		},
+	}
+	if response, err := r.JiraClient.Issue.Update(ctx, jiraIssueKey, true, payload, nil, nil); err != nil {
+		if response != nil {
+			l.Error(err, "failed to add labels to Jira customer request", "jiraTicket", jiraIssueKey, "response", response.Bytes.String())
+		} else {
+			l.Error(err, "failed to add labels to Jira customer request", "jiraTicket", jiraIssueKey, "response", "nil response")
+		}
+	}
+
+	l.Info("Jira customer request created successfully", "jiraTicket", jiraIssueKey)
+	return jiraIssueKey, nil
+}
+
 // rejectJiraTicket rejects a jira ticket with comment
-func (r *JitRequestReconciler) rejectJiraTicket(ctx context.Context, jitRequest *justintimev1.JitRequest, rejectedTransitionID string) error {
+func (r *JitRequestReconciler) rejectJiraTicket(ctx context.Context, jitRequest *justintimev1.JitRequest, rejectedTransition string, jiraTemplates *templates.Templates) error {
 	l := log.FromContext(ctx)
 
 	// Add a comment to the Jira issue
 	jiraTicket := jitRequest.Status.JiraTicket
-	comment := fmt.Sprintf("{color:#de350b}*Rejected - %s*{color}", jitRequest.Status.Message)
+	comment, err := jiraTemplates.Render(templates.RejectedComment, jitRequest, jitRequest.Status.Message)
+	if err != nil {
+		return err
+	}
 	l.Info("Rejecting Jira ticket", "jiraTicket", jiraTicket)
 	if err := r.updateJiraTicket(ctx, jiraTicket, comment); err != nil {
 		return err
 	}
 
 	// reject ticket
-	options := &models.IssueMoveOptionsV2{
+	return r.transitionJiraTicket(ctx, jitRequest, rejectedTransition, resolutionOptions())
+}
+
+// revokeJiraTicket comments on a jira ticket with who revoked access and why, and transitions it if configured
+func (r *JitRequestReconciler) revokeJiraTicket(ctx context.Context, jitRequest *justintimev1.JitRequest, revokedTransition string, jiraTemplates *templates.Templates) error {
+	l := log.FromContext(ctx)
+
+	// Add a comment to the Jira issue
+	jiraTicket := jitRequest.Status.JiraTicket
+	comment, err := jiraTemplates.Render(templates.RevokedComment, jitRequest, "")
+	if err != nil {
+		return err
+	}
+	l.Info("Revoking Jira ticket", "jiraTicket", jiraTicket)
+	if err := r.updateJiraTicket(ctx, jiraTicket, comment); err != nil {
+		return err
+	}
+
+	// transition is optional
+	if revokedTransition == "" {
+		return nil
+	}
+
+	return r.transitionJiraTicket(ctx, jitRequest, revokedTransition, resolutionOptions())
+}
+
+// linkJiraTicket links the jira ticket of a JitRequest to its existing linked issue
+func (r *JitRequestReconciler) linkJiraTicket(ctx context.Context, jitRequest *justintimev1.JitRequest, jiraTicket, linkType string) error {
+	l := log.FromContext(ctx)
+
+	if linkType == "" {
+		linkType = DefaultIssueLinkType
+	}
+	linkedJiraTicket := jitRequest.Spec.LinkedJiraTicket
+	payload := &models.LinkPayloadSchemeV2{
+		Type:         &models.LinkTypeScheme{Name: linkType},
+		InwardIssue:  &models.LinkedIssueScheme{Key: jiraTicket},
+		OutwardIssue: &models.LinkedIssueScheme{Key: linkedJiraTicket},
+	}
+
+	l.Info("Linking Jira ticket", "jiraTicket", jiraTicket, "linkedJiraTicket", linkedJiraTicket, "linkType", linkType)
+	response, err := r.JiraClient.Issue.Link.Create(ctx, payload)
+	if err != nil {
+		if response != nil {
+			return fmt.Errorf("%w, response: %s", err, response.Bytes.String())
+		}
+		return err
+	}
+	return nil
+}
+
+// expireJiraTicket comments on a jira ticket that access has been revoked at end time, and transitions it if configured
+func (r *JitRequestReconciler) expireJiraTicket(ctx context.Context, jitRequest *justintimev1.JitRequest, expiredTransition string, jiraTemplates *templates.Templates) error {
+	l := log.FromContext(ctx)
+
+	// Add a comment to the Jira issue
+	jiraTicket := jitRequest.Status.JiraTicket
+	comment, err := jiraTemplates.Render(templates.ExpiredComment, jitRequest, "")
+	if err != nil {
+		return err
+	}
+	l.Info("Expiring Jira ticket", "jiraTicket", jiraTicket)
+	if err := r.updateJiraTicket(ctx, jiraTicket, comment); err != nil {
+		return err
+	}
+
+	// transition is optional
+	if expiredTransition == "" {
+		return nil
+	}
+
+	return r.transitionJiraTicket(ctx, jitRequest, expiredTransition, resolutionOptions())
+}
+
+// requestJiraExtension comments on a jira ticket with the extension request, and transitions it back for approval if configured
+func (r *JitRequestReconciler) requestJiraExtension(ctx context.Context, jitRequest *justintimev1.JitRequest, extensionTransition string, jiraTemplates *templates.Templates) error {
+	l := log.FromContext(ctx)
+
+	// Add a comment to the Jira issue
+	jiraTicket := jitRequest.Status.JiraTicket
+	comment, err := jiraTemplates.Render(templates.ExtensionRequestedComment, jitRequest, "")
+	if err != nil {
+		return err
+	}
+	l.Info("Requesting extension on Jira ticket", "jiraTicket", jiraTicket)
+	if err := r.updateJiraTicket(ctx, jiraTicket, comment); err != nil {
+		return err
+	}
+
+	// transition is optional
+	if extensionTransition == "" {
+		return nil
+	}
+
+	// re-opened for approval, so no resolution is set
+	return r.transitionJiraTicket(ctx, jitRequest, extensionTransition, nil)
+}
+
+// completeJiraExtension completes a jira ticket for an approved extension with a comment
+func (r *JitRequestReconciler) completeJiraExtension(ctx context.Context, jitRequest *justintimev1.JitRequest, completedTransition string, jiraTemplates *templates.Templates) error {
+	l := log.FromContext(ctx)
+
+	// Add a comment to the Jira issue
+	jiraTicket := jitRequest.Status.JiraTicket
+	comment, err := jiraTemplates.Render(templates.ExtensionCompletedComment, jitRequest, "")
+	if err != nil {
+		return err
+	}
+	l.Info("Completing Jira ticket extension", "jiraTicket", jiraTicket)
+	if err := r.updateJiraTicket(ctx, jiraTicket, comment); err != nil {
+		return err
+	}
+
+	return r.transitionJiraTicket(ctx, jitRequest, completedTransition, resolutionOptions())
+}
+
+// resolutionOptions returns the move options to resolve a jira ticket on transition
+func resolutionOptions() *models.IssueMoveOptionsV2 {
+	return &models.IssueMoveOptionsV2{
 		Fields: &models.IssueSchemeV2{
 			Fields: &models.IssueFieldsSchemeV2{
 				Resolution: &models.ResolutionScheme{},
 			},
 		},
 	}
-	response, err := r.JiraClient.Issue.Move(context.Background(), jiraTicket, rejectedTransitionID, options)
+}
+
+// transitionJiraTicket transitions the jira ticket of a JitRequest with a workflow transition ID or name
+func (r *JitRequestReconciler) transitionJiraTicket(ctx context.Context, jitRequest *justintimev1.JitRequest, transition string, options *models.IssueMoveOptionsV2) error {
+	l := log.FromContext(ctx)
+
+	jiraTicket := jitRequest.Status.JiraTicket
+	transitionID, err := r.resolveJiraTransition(ctx, jiraTicket, transition)
+	if err != nil {
+		l.Error(err, "failed to resolve jira transition", "jiraTicket", jiraTicket, "transition", transition)
+		r.raiseEvent(jitRequest, "Warning", EventFailedJiraTransition, fmt.Sprintf("Error: %s", err))
+		return err
+	}
+
+	response, err := r.JiraClient.Issue.Move(ctx, jiraTicket, transitionID, options)
 	if err != nil {
 		if response != nil {
 			body := response.Bytes.String()
-			l.Error(err, "failed to transition jira ticket", "response", body)
+			l.Error(err, "failed to transition jira ticket", "jiraTicket", jiraTicket, "transitionID", transitionID, "response", body)
 		} else {
-			l.Error(err, "failed to transition jira ticket", "response", "nil response")
+			l.Error(err, "failed to transition jira ticket", "jiraTicket", jiraTicket, "transitionID", transitionID, "response", "nil response")
 		}
 		return err
 	}
@@ -260,33 +606,44 @@ func (r *JitRequestReconciler) rejectJiraTicket(ctx context.Context, jitRequest
 	return nil
 }
 
+// resolveJiraTransition returns the ID of a transition available from the current status of a jira ticket, matched by ID or name
+func (r *JitRequestReconciler) resolveJiraTransition(ctx context.Context, jiraTicket, transition string) (string, error) {
+	l := log.FromContext(ctx)
+
+	transitions, response, err := r.JiraClient.Issue.Transitions(ctx, jiraTicket)
+	if err != nil {
+		if response != nil {
+			body := response.Bytes.String()
+			l.Error(err, "failed to get jira ticket transitions", "jiraTicket", jiraTicket, "response", body)
+		} else {
+			l.Error(err, "failed to get jira ticket transitions", "jiraTicket", jiraTicket, "response", "nil response")
+		}
+		return "", err
+	}
+
+	return utils.MatchJiraTransition(transitions.Transitions, transition)
+}
+
 // preApproveRequest pre-approves a JitRequest, updates the Jira ticket and re-queues for start time
-func (r *JitRequestReconciler) preApproveRequest(ctx context.Context, l logr.Logger, jitRequest *justintimev1.JitRequest, jiraIssueKey, additionalComments string) (ctrl.Result, error) {
-	startTime := jitRequest.Spec.StartTime.Time
+func (r *JitRequestReconciler) preApproveRequest(ctx context.Context, l logr.Logger, jitRequest *justintimev1.JitRequest, jiraIssueKey string, jiraTemplates *templates.Templates, approvalPollInterval time.Duration) (ctrl.Result, error) {
+	fieldErr := utils.ValidateTimes(jitRequest, time.Now())
 
-	if startTime.After(time.Now()) {
+	if fieldErr == nil {
 
 		// record event
 		r.raiseEvent(jitRequest, "Normal", StatusPreApproved, fmt.Sprintf("ClusterRole '%s' is allowed\nJira: %s", jitRequest.Spec.ClusterRole, jiraIssueKey))
 
 		// msg for status and comment
 		jitRequestStatusMsg := "Pre-approval - Access will be granted at start time pending human approval(s)"
-
-		// build comment
-		jiraMessage := fmt.Sprintf("{color:#00875a}*%s*{color}", jitRequestStatusMsg)
-		namespaces := strings.Join(jitRequest.Spec.Namespaces, "\n")
-		comment := jiraMessage + "\n|*Namespace(s)*|" + namespaces + "|\n|*User*|" + jitRequest.Spec.Reporter + "|"
-
-		// check if additionalUsers defined and add to comment
-		additionalUsers := jitRequest.Spec.AdditionUserEmails
-		if len(additionalUsers) > 0 {
-			additionalUsersStr := strings.Join(additionalUsers, "\n")
-			comment += "\n|*Additional Users*|" + additionalUsersStr + "|"
+		if jitRequest.Spec.StartOnApproval {
+			jitRequestStatusMsg = "Pre-approval - Access will be granted as soon as human approval(s) are given"
 		}
 
-		// add additional comments if exists
-		if additionalComments != "" {
-			comment += "\n\n*Additional Info:*\n" + additionalComments
+		// build comment
+		comment, err := jiraTemplates.Render(templates.PreApprovedComment, jitRequest, jitRequestStatusMsg)
+		if err != nil {
+			l.Error(err, "failed to render pre-approval comment")
+			return ctrl.Result{}, err
 		}
 
 		// add comment
@@ -295,26 +652,32 @@ func (r *JitRequestReconciler) preApproveRequest(ctx context.Context, l logr.Log
 		}
 
 		// update jitRequest status
+		setCondition(jitRequest, justintimev1.ConditionValidated, metav1.ConditionTrue, ReasonValidationSucceeded, fmt.Sprintf("ClusterRole '%s' is allowed", jitRequest.Spec.ClusterRole))
+		setCondition(jitRequest, justintimev1.ConditionApproved, metav1.ConditionUnknown, ReasonPendingApproval, jitRequestStatusMsg)
 		if err := r.updateStatus(ctx, jitRequest, StatusPreApproved, jitRequestStatusMsg, jiraIssueKey); err != nil {
 			l.Error(err, "failed to update status to Pre-Approved")
 			return ctrl.Result{}, err
 		}
 
-		// requeue for start time
-		delay := time.Until(startTime)
-		l.Info("Start time not reached, requeuing", "requeueAfter", delay)
+		// poll for approval, at the latest at start time
+		delay := approvalPollInterval
+		if !jitRequest.Spec.StartOnApproval {
+			delay = requeueDelay(approvalPollInterval, jitRequest.Status.StartTime.Time)
+		}
+		l.Info("Waiting for approval, requeuing", "requeueAfter", delay)
 		return ctrl.Result{RequeueAfter: delay}, nil
 	}
 
-	// invalid start time, reject
-	errMsg := fmt.Errorf("start time %s must be after current time", jitRequest.Spec.StartTime.Time)
-	l.Error(errMsg, "start time validation failed")
+	// invalid start time, end time or duration, reject
+	errMsg := fieldErr.Error()
+	l.Error(fieldErr, "time validation failed")
 
 	// record event
-	r.raiseEvent(jitRequest, "Warning", EventValidationFailed, errMsg.Error())
+	r.raiseEvent(jitRequest, "Warning", EventValidationFailed, errMsg)
 
 	// update jitRequest status
-	if err := r.updateStatus(ctx, jitRequest, StatusRejected, errMsg.Error(), jiraIssueKey); err != nil {
+	setCondition(jitRequest, justintimev1.ConditionValidated, metav1.ConditionFalse, ReasonInvalidTime, errMsg)
+	if err := r.updateStatus(ctx, jitRequest, StatusRejected, errMsg, jiraIssueKey); err != nil {
 		l.Error(err, "failed to update status to Rejected")
 		return ctrl.Result{}, err
 	}
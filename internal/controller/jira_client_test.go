@@ -2,12 +2,17 @@ package controller
 
 import (
 	v1 "jira-jit-rbac-operator/api/v1"
+	"jira-jit-rbac-operator/pkg/templates"
+	"jira-jit-rbac-operator/pkg/utils"
 	testUtils "jira-jit-rbac-operator/test/utils"
 	"os/exec"
 
+	"time"
+
 	"github.com/go-logr/logr"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/kubernetes/scheme"
 	"k8s.io/client-go/tools/record"
@@ -35,9 +40,9 @@ var _ = Describe("JitRequestReconciler jira_client Unit Tests", Ordered, Label("
 				"Justification": {Type: "text", JiraCustomField: "customfield_10116"},
 			},
 			RequiredFields: &v1.RequiredFieldsSpec{
-				StartTime:   v1.CustomFieldSettings{Type: "date", JiraCustomField: "customfield_10118"},
-				EndTime:     v1.CustomFieldSettings{Type: "date", JiraCustomField: "customfield_10119"},
-				ClusterRole: v1.CustomFieldSettings{Type: "date", JiraCustomField: "customfield_10117"},
+				StartTime:   v1.CustomFieldSettings{Type: "datetime", JiraCustomField: "customfield_10118"},
+				EndTime:     v1.CustomFieldSettings{Type: "datetime", JiraCustomField: "customfield_10119"},
+				ClusterRole: v1.CustomFieldSettings{Type: "text", JiraCustomField: "customfield_10117"},
 			},
 			Labels: []string{"label1", "label2"},
 			Environment: &v1.EnvironmentSpec{
@@ -104,7 +109,7 @@ var _ = Describe("JitRequestReconciler jira_client Unit Tests", Ordered, Label("
 			Expect(err).NotTo(HaveOccurred())
 
 			By("Attempting to pre-approve with invlaid start time")
-			result, err := reconciler.preApproveRequest(ctx, l, jitRequest, JiraTicket, jitConfig.AdditionalCommentText)
+			result, err := reconciler.preApproveRequest(ctx, l, jitRequest, JiraTicket, templates.New(jitConfig), DefaultApprovalPollInterval)
 			Expect(err).NotTo(HaveOccurred())
 			Expect(result).NotTo(BeNil())
 			Expect(result.IsZero()).To(BeTrue())
@@ -127,11 +132,11 @@ var _ = Describe("JitRequestReconciler jira_client Unit Tests", Ordered, Label("
 			Expect(err).NotTo(HaveOccurred())
 
 			By("Creating a Jira ticket")
-			ticket, err := reconciler.createJiraTicket(ctx, jitRequest, jitConfig.JiraProject, jitConfig.JiraIssueType, jitConfig.CustomFields, jitConfig.RequiredFields, jitConfig.Labels, jitConfig.Environment)
+			ticket, err := reconciler.createJiraTicket(ctx, jitRequest, jitConfig.JiraProject, jitConfig.JiraIssueType, jitConfig.ServiceDesk, jitConfig.CustomFields, jitConfig.RequiredFields, jitConfig.Labels, jitConfig.Environment, time.UTC, templates.New(jitConfig))
 			Expect(err).NotTo(HaveOccurred())
 
 			By("Attempting to pre-approve a valid JitRequest")
-			result, err := reconciler.preApproveRequest(ctx, l, jitRequest, ticket, jitConfig.AdditionalCommentText)
+			result, err := reconciler.preApproveRequest(ctx, l, jitRequest, ticket, templates.New(jitConfig), DefaultApprovalPollInterval)
 			Expect(err).NotTo(HaveOccurred())
 			Expect(result).NotTo(BeNil())
 			Expect(result.IsZero()).To(BeFalse())
@@ -147,6 +152,28 @@ var _ = Describe("JitRequestReconciler jira_client Unit Tests", Ordered, Label("
 			Expect(jitRequest.Status.Message).To(ContainSubstring(message))
 			Expect(jitRequest.Status.JiraTicket).To(Equal(JiraTicket))
 		})
+
+		It("should pre-approve and poll for approval of startOnApproval JitRequests", func() {
+			By("Simulating a startOnApproval JitRequest")
+			jitRequest, err := testUtils.CreateJitRequest(ctx, reconciler.Client, 10, testUtils.ValidClusterRole, TestNamespace)
+			Expect(err).NotTo(HaveOccurred())
+			jitRequest.Spec.StartOnApproval = true
+			jitRequest.Spec.StartTime = metav1.Time{}
+			jitRequest.Spec.EndTime = metav1.Time{}
+			jitRequest.Spec.Duration = &metav1.Duration{Duration: time.Hour}
+
+			By("Attempting to pre-approve the JitRequest")
+			result, err := reconciler.preApproveRequest(ctx, l, jitRequest, JiraTicket, templates.New(jitConfig), DefaultApprovalPollInterval)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.RequeueAfter).To(Equal(DefaultApprovalPollInterval))
+
+			By("Checking the jitRequest status is pre-approved without a start time")
+			err = reconciler.Get(ctx, types.NamespacedName{Name: JitRequestName}, jitRequest)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(jitRequest.Status.State).To(Equal(StatusPreApproved))
+			Expect(jitRequest.Status.Message).To(Equal("Pre-approval - Access will be granted as soon as human approval(s) are given"))
+			Expect(jitRequest.Status.StartTime.IsZero()).To(BeTrue())
+		})
 	})
 
 	Describe("createJiraTicket", func() {
@@ -157,11 +184,47 @@ var _ = Describe("JitRequestReconciler jira_client Unit Tests", Ordered, Label("
 			Expect(err).NotTo(HaveOccurred())
 
 			By("Creating a Jira ticket")
-			result, err := reconciler.createJiraTicket(ctx, jitRequest, jitConfig.JiraProject, jitConfig.JiraIssueType, jitConfig.CustomFields, jitConfig.RequiredFields, jitConfig.Labels, jitConfig.Environment)
+			result, err := reconciler.createJiraTicket(ctx, jitRequest, jitConfig.JiraProject, jitConfig.JiraIssueType, jitConfig.ServiceDesk, jitConfig.CustomFields, jitConfig.RequiredFields, jitConfig.Labels, jitConfig.Environment, time.UTC, templates.New(jitConfig))
 			Expect(err).NotTo(HaveOccurred())
 			Expect(result).To(Equal(JiraTicket))
 		})
 
+		It("should create a Jira Ticket on Jira Cloud", func() {
+			By("Simulating a valid JitRequest")
+			jitRequest, err := testUtils.CreateJitRequest(ctx, reconciler.Client, 10, testUtils.ValidClusterRole, TestNamespace)
+			Expect(err).NotTo(HaveOccurred())
+
+			By("Creating a Jira ticket with users referenced by accountId")
+			reconciler.JiraFlavour = utils.JiraCloud
+			result, err := reconciler.createJiraTicket(ctx, jitRequest, jitConfig.JiraProject, jitConfig.JiraIssueType, jitConfig.ServiceDesk, jitConfig.CustomFields, jitConfig.RequiredFields, jitConfig.Labels, jitConfig.Environment, time.UTC, templates.New(jitConfig))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result).To(Equal(JiraTicket))
+		})
+
+		It("should create a Jira Service Management customer request on behalf of the reporter", func() {
+			By("Simulating a valid JitRequest")
+			jitRequest, err := testUtils.CreateJitRequest(ctx, reconciler.Client, 10, testUtils.ValidClusterRole, TestNamespace)
+			Expect(err).NotTo(HaveOccurred())
+
+			By("Creating a customer request")
+			jitConfig.ServiceDesk = &v1.ServiceDeskSpec{ServiceDeskID: "1", RequestTypeID: "25"}
+			result, err := reconciler.createJiraTicket(ctx, jitRequest, jitConfig.JiraProject, jitConfig.JiraIssueType, jitConfig.ServiceDesk, jitConfig.CustomFields, jitConfig.RequiredFields, jitConfig.Labels, jitConfig.Environment, time.UTC, templates.New(jitConfig))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result).To(Equal(JiraTicket))
+
+			By("Checking the request has the fields and reporter of a ticket")
+			request := testUtils.LastCustomerRequest
+			Expect(request.ServiceDeskID).To(Equal("1"))
+			Expect(request.RequestTypeID).To(Equal("25"))
+			Expect(request.RaiseOnBehalfOf).To(Equal("john117"))
+			Expect(request.RequestFieldValues).To(HaveKeyWithValue("summary", "Automated JIT request for "+jitRequest.Spec.Reporter))
+			Expect(request.RequestFieldValues).To(HaveKeyWithValue("customfield_10116", jitRequest.Spec.JiraFields["Justification"]))
+			Expect(request.RequestFieldValues).To(HaveKey("customfield_10117"))
+
+			By("Checking the labels are set on the created ticket")
+			Expect(testUtils.GetIssueLabels(result)).To(ContainElements("label1", "jira-jit-rbac-operator", "minikube"))
+		})
+
 		It("should return Skipped if missing jira field", func() {
 			By("Simulating a valid JitRequest")
 			jitRequest, err := testUtils.CreateJitRequest(ctx, reconciler.Client, 10, testUtils.ValidClusterRole, TestNamespace)
@@ -171,7 +234,7 @@ var _ = Describe("JitRequestReconciler jira_client Unit Tests", Ordered, Label("
 			missingCustomFieldsConfig := map[string]v1.CustomFieldSettings{
 				"MissingField": {Type: "user", JiraCustomField: "customfield_10114"},
 			}
-			result, err := reconciler.createJiraTicket(ctx, jitRequest, jitConfig.JiraProject, jitConfig.JiraIssueType, missingCustomFieldsConfig, jitConfig.RequiredFields, jitConfig.Labels, jitConfig.Environment)
+			result, err := reconciler.createJiraTicket(ctx, jitRequest, jitConfig.JiraProject, jitConfig.JiraIssueType, jitConfig.ServiceDesk, missingCustomFieldsConfig, jitConfig.RequiredFields, jitConfig.Labels, jitConfig.Environment, time.UTC, templates.New(jitConfig))
 			Expect(err).NotTo(HaveOccurred())
 			Expect(result).To(Equal("Skipped"))
 
@@ -185,6 +248,30 @@ var _ = Describe("JitRequestReconciler jira_client Unit Tests", Ordered, Label("
 			Expect(jitRequest.Status.Message).To(Equal("missing custom field: MissingField"))
 			Expect(jitRequest.Status.JiraTicket).To(Equal(Skipped))
 		})
+
+		It("should return Skipped if a jira field is invalid for its type", func() {
+			By("Simulating a valid JitRequest")
+			jitRequest, err := testUtils.CreateJitRequest(ctx, reconciler.Client, 10, testUtils.ValidClusterRole, TestNamespace)
+			Expect(err).NotTo(HaveOccurred())
+
+			By("Checking a number field with a text value and creating a jira ticket")
+			invalidCustomFieldsConfig := map[string]v1.CustomFieldSettings{
+				"Justification": {Type: "number", JiraCustomField: "customfield_10116"},
+			}
+			result, err := reconciler.createJiraTicket(ctx, jitRequest, jitConfig.JiraProject, jitConfig.JiraIssueType, jitConfig.ServiceDesk, invalidCustomFieldsConfig, jitConfig.RequiredFields, jitConfig.Labels, jitConfig.Environment, time.UTC, templates.New(jitConfig))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result).To(Equal("Skipped"))
+
+			By("Checking the jitRequest status is rejected")
+			namespacedName := types.NamespacedName{
+				Name: "e2e-jit-test",
+			}
+			err = reconciler.Get(ctx, namespacedName, jitRequest)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(jitRequest.Status.State).To(Equal(StatusRejected))
+			Expect(jitRequest.Status.Message).To(HavePrefix("invalid custom field Justification: invalid number"))
+			Expect(jitRequest.Status.JiraTicket).To(Equal(Skipped))
+		})
 	})
 
 	Describe("rejectJiraTicket", func() {
@@ -195,13 +282,115 @@ var _ = Describe("JitRequestReconciler jira_client Unit Tests", Ordered, Label("
 			Expect(err).NotTo(HaveOccurred())
 
 			By("Creating a jira ticket")
-			ticket, err := reconciler.createJiraTicket(ctx, jitRequest, jitConfig.JiraProject, jitConfig.JiraIssueType, jitConfig.CustomFields, jitConfig.RequiredFields, jitConfig.Labels, jitConfig.Environment)
+			ticket, err := reconciler.createJiraTicket(ctx, jitRequest, jitConfig.JiraProject, jitConfig.JiraIssueType, jitConfig.ServiceDesk, jitConfig.CustomFields, jitConfig.RequiredFields, jitConfig.Labels, jitConfig.Environment, time.UTC, templates.New(jitConfig))
 			Expect(err).NotTo(HaveOccurred())
 
 			By("Simulating rejecting a ticket")
 			jitRequest.Status.JiraTicket = ticket
 			jitRequest.Status.Message = "test rejected"
-			err = reconciler.rejectJiraTicket(ctx, jitRequest, "1")
+			err = reconciler.rejectJiraTicket(ctx, jitRequest, "1", templates.New(jitConfig))
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("should reject a Jira Ticket with a transition name", func() {
+			By("Simulating a valid JitRequest")
+			jitRequest, err := testUtils.CreateJitRequest(ctx, reconciler.Client, 10, testUtils.ValidClusterRole, TestNamespace)
+			Expect(err).NotTo(HaveOccurred())
+
+			By("Creating a jira ticket")
+			ticket, err := reconciler.createJiraTicket(ctx, jitRequest, jitConfig.JiraProject, jitConfig.JiraIssueType, jitConfig.ServiceDesk, jitConfig.CustomFields, jitConfig.RequiredFields, jitConfig.Labels, jitConfig.Environment, time.UTC, templates.New(jitConfig))
+			Expect(err).NotTo(HaveOccurred())
+
+			By("Simulating rejecting a ticket by transition name")
+			jitRequest.Status.JiraTicket = ticket
+			jitRequest.Status.Message = "test rejected"
+			err = reconciler.rejectJiraTicket(ctx, jitRequest, "reject", templates.New(jitConfig))
+			Expect(err).NotTo(HaveOccurred())
+		})
+	})
+
+	Describe("revokeJiraTicket", func() {
+
+		It("should revoke a Jira Ticket", func() {
+			By("Simulating a valid JitRequest")
+			jitRequest, err := testUtils.CreateJitRequest(ctx, reconciler.Client, 10, testUtils.ValidClusterRole, TestNamespace)
+			Expect(err).NotTo(HaveOccurred())
+
+			By("Creating a jira ticket")
+			ticket, err := reconciler.createJiraTicket(ctx, jitRequest, jitConfig.JiraProject, jitConfig.JiraIssueType, jitConfig.ServiceDesk, jitConfig.CustomFields, jitConfig.RequiredFields, jitConfig.Labels, jitConfig.Environment, time.UTC, templates.New(jitConfig))
+			Expect(err).NotTo(HaveOccurred())
+
+			By("Simulating revoking a ticket")
+			jitRequest.Status.JiraTicket = ticket
+			jitRequest.Spec.Revocation = &v1.RevocationSpec{
+				RevokedBy: "cpt-keyes@unsc.com",
+				Reason:    "test revoked",
+			}
+			err = reconciler.revokeJiraTicket(ctx, jitRequest, "1", templates.New(jitConfig))
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("should only comment on a Jira Ticket if no transition is configured", func() {
+			By("Simulating a valid JitRequest")
+			jitRequest, err := testUtils.CreateJitRequest(ctx, reconciler.Client, 10, testUtils.ValidClusterRole, TestNamespace)
+			Expect(err).NotTo(HaveOccurred())
+
+			By("Creating a jira ticket")
+			ticket, err := reconciler.createJiraTicket(ctx, jitRequest, jitConfig.JiraProject, jitConfig.JiraIssueType, jitConfig.ServiceDesk, jitConfig.CustomFields, jitConfig.RequiredFields, jitConfig.Labels, jitConfig.Environment, time.UTC, templates.New(jitConfig))
+			Expect(err).NotTo(HaveOccurred())
+
+			By("Simulating revoking a ticket")
+			jitRequest.Status.JiraTicket = ticket
+			jitRequest.Spec.Revocation = &v1.RevocationSpec{
+				RevokedBy: "cpt-keyes@unsc.com",
+				Reason:    "test revoked",
+			}
+			err = reconciler.revokeJiraTicket(ctx, jitRequest, "", templates.New(jitConfig))
+			Expect(err).NotTo(HaveOccurred())
+		})
+	})
+
+	Describe("requestJiraExtension", func() {
+
+		It("should request an extension on a Jira Ticket", func() {
+			By("Simulating a valid JitRequest")
+			jitRequest, err := testUtils.CreateJitRequest(ctx, reconciler.Client, 10, testUtils.ValidClusterRole, TestNamespace)
+			Expect(err).NotTo(HaveOccurred())
+
+			By("Creating a jira ticket")
+			ticket, err := reconciler.createJiraTicket(ctx, jitRequest, jitConfig.JiraProject, jitConfig.JiraIssueType, jitConfig.ServiceDesk, jitConfig.CustomFields, jitConfig.RequiredFields, jitConfig.Labels, jitConfig.Environment, time.UTC, templates.New(jitConfig))
+			Expect(err).NotTo(HaveOccurred())
+
+			By("Simulating an extension request")
+			jitRequest.Status.JiraTicket = ticket
+			jitRequest.Status.EndTime = jitRequest.Spec.EndTime
+			jitRequest.Spec.Extension = &v1.ExtensionSpec{
+				EndTime: metav1.NewTime(jitRequest.Spec.EndTime.Add(time.Hour)),
+				Reason:  "test extension",
+			}
+			err = reconciler.requestJiraExtension(ctx, jitRequest, "1", templates.New(jitConfig))
+			Expect(err).NotTo(HaveOccurred())
+		})
+	})
+
+	Describe("completeJiraExtension", func() {
+
+		It("should complete an extension on a Jira Ticket", func() {
+			By("Simulating a valid JitRequest")
+			jitRequest, err := testUtils.CreateJitRequest(ctx, reconciler.Client, 10, testUtils.ValidClusterRole, TestNamespace)
+			Expect(err).NotTo(HaveOccurred())
+
+			By("Creating a jira ticket")
+			ticket, err := reconciler.createJiraTicket(ctx, jitRequest, jitConfig.JiraProject, jitConfig.JiraIssueType, jitConfig.ServiceDesk, jitConfig.CustomFields, jitConfig.RequiredFields, jitConfig.Labels, jitConfig.Environment, time.UTC, templates.New(jitConfig))
+			Expect(err).NotTo(HaveOccurred())
+
+			By("Simulating completing an extension")
+			jitRequest.Status.JiraTicket = ticket
+			jitRequest.Status.Extension = &v1.ExtensionStatus{
+				State:   v1.ExtensionPending,
+				EndTime: metav1.NewTime(jitRequest.Spec.EndTime.Add(time.Hour)),
+			}
+			err = reconciler.completeJiraExtension(ctx, jitRequest, "1", templates.New(jitConfig))
 			Expect(err).NotTo(HaveOccurred())
 		})
 	})
@@ -214,7 +403,7 @@ var _ = Describe("JitRequestReconciler jira_client Unit Tests", Ordered, Label("
 			Expect(err).NotTo(HaveOccurred())
 
 			By("Creating a jira ticket")
-			ticket, err := reconciler.createJiraTicket(ctx, jitRequest, jitConfig.JiraProject, jitConfig.JiraIssueType, jitConfig.CustomFields, jitConfig.RequiredFields, jitConfig.Labels, jitConfig.Environment)
+			ticket, err := reconciler.createJiraTicket(ctx, jitRequest, jitConfig.JiraProject, jitConfig.JiraIssueType, jitConfig.ServiceDesk, jitConfig.CustomFields, jitConfig.RequiredFields, jitConfig.Labels, jitConfig.Environment, time.UTC, templates.New(jitConfig))
 			Expect(err).NotTo(HaveOccurred())
 
 			By("Simulating updating a ticket")
@@ -232,14 +421,30 @@ var _ = Describe("JitRequestReconciler jira_client Unit Tests", Ordered, Label("
 			Expect(err).NotTo(HaveOccurred())
 
 			By("Creating a jira ticket")
-			ticket, err := reconciler.createJiraTicket(ctx, jitRequest, jitConfig.JiraProject, jitConfig.JiraIssueType, jitConfig.CustomFields, jitConfig.RequiredFields, jitConfig.Labels, jitConfig.Environment)
+			ticket, err := reconciler.createJiraTicket(ctx, jitRequest, jitConfig.JiraProject, jitConfig.JiraIssueType, jitConfig.ServiceDesk, jitConfig.CustomFields, jitConfig.RequiredFields, jitConfig.Labels, jitConfig.Environment, time.UTC, templates.New(jitConfig))
 			Expect(err).NotTo(HaveOccurred())
 
 			By("Simulating completing a ticket")
 			jitRequest.Status.JiraTicket = ticket
-			err = reconciler.completeJiraTicket(ctx, jitRequest, "1")
+			err = reconciler.completeJiraTicket(ctx, jitRequest, "1", templates.New(jitConfig))
 			Expect(err).NotTo(HaveOccurred())
 		})
+
+		It("should return an error and raise an event if the transition is not available", func() {
+			By("Simulating a valid JitRequest")
+			jitRequest, err := testUtils.CreateJitRequest(ctx, reconciler.Client, 10, testUtils.ValidClusterRole, TestNamespace)
+			Expect(err).NotTo(HaveOccurred())
+
+			By("Creating a jira ticket")
+			ticket, err := reconciler.createJiraTicket(ctx, jitRequest, jitConfig.JiraProject, jitConfig.JiraIssueType, jitConfig.ServiceDesk, jitConfig.CustomFields, jitConfig.RequiredFields, jitConfig.Labels, jitConfig.Environment, time.UTC, templates.New(jitConfig))
+			Expect(err).NotTo(HaveOccurred())
+
+			By("Simulating completing a ticket with an unknown transition")
+			jitRequest.Status.JiraTicket = ticket
+			err = reconciler.completeJiraTicket(ctx, jitRequest, "Deploy", templates.New(jitConfig))
+			Expect(err).To(MatchError(ContainSubstring("transition 'Deploy' is not available from the current status")))
+			Expect(fakeRecorder.Events).To(Receive(ContainSubstring(EventFailedJiraTransition)))
+		})
 	})
 
 	Describe("getJiraApproval", func() {
@@ -250,7 +455,7 @@ var _ = Describe("JitRequestReconciler jira_client Unit Tests", Ordered, Label("
 			Expect(err).NotTo(HaveOccurred())
 
 			By("Creating a jira ticket")
-			ticket, err := reconciler.createJiraTicket(ctx, jitRequest, jitConfig.JiraProject, jitConfig.JiraIssueType, jitConfig.CustomFields, jitConfig.RequiredFields, jitConfig.Labels, jitConfig.Environment)
+			ticket, err := reconciler.createJiraTicket(ctx, jitRequest, jitConfig.JiraProject, jitConfig.JiraIssueType, jitConfig.ServiceDesk, jitConfig.CustomFields, jitConfig.RequiredFields, jitConfig.Labels, jitConfig.Environment, time.UTC, templates.New(jitConfig))
 			Expect(err).NotTo(HaveOccurred())
 
 			By("Approving a jira ticket")
@@ -258,7 +463,8 @@ var _ = Describe("JitRequestReconciler jira_client Unit Tests", Ordered, Label("
 			testUtils.IssueStatus = testUtils.TestJiraWorkflowApproved
 
 			By("Checking getJiraApproval has no error")
-			err = reconciler.getJiraApproval(ctx, jitRequest, testUtils.IssueStatus)
+			jitConfig.JiraWorkflowApproveStatus = testUtils.IssueStatus
+			err = reconciler.getJiraApproval(ctx, jitRequest, jitConfig)
 			Expect(err).NotTo(HaveOccurred())
 		})
 
@@ -268,14 +474,83 @@ var _ = Describe("JitRequestReconciler jira_client Unit Tests", Ordered, Label("
 			Expect(err).NotTo(HaveOccurred())
 
 			By("Creating a jira ticket")
-			ticket, err := reconciler.createJiraTicket(ctx, jitRequest, jitConfig.JiraProject, jitConfig.JiraIssueType, jitConfig.CustomFields, jitConfig.RequiredFields, jitConfig.Labels, jitConfig.Environment)
+			ticket, err := reconciler.createJiraTicket(ctx, jitRequest, jitConfig.JiraProject, jitConfig.JiraIssueType, jitConfig.ServiceDesk, jitConfig.CustomFields, jitConfig.RequiredFields, jitConfig.Labels, jitConfig.Environment, time.UTC, templates.New(jitConfig))
 			Expect(err).NotTo(HaveOccurred())
 
 			By("Checking getJiraApproval raises an error")
 			jitRequest.Status.JiraTicket = ticket
-			err = reconciler.getJiraApproval(ctx, jitRequest, "Not Approved")
+			jitConfig.JiraWorkflowApproveStatus = "Not Approved"
+			err = reconciler.getJiraApproval(ctx, jitRequest, jitConfig)
 			Expect(err).To(HaveOccurred())
 			Expect(err.Error()).To(ContainSubstring("failed on jira approval"))
 		})
+
+		It("should not count the reporter moving the jira ticket to the approved status unless self-approval is enabled", func() {
+			By("Simulating a valid JitRequest")
+			jitRequest, err := testUtils.CreateJitRequest(ctx, reconciler.Client, 10, testUtils.ValidClusterRole, TestNamespace)
+			Expect(err).NotTo(HaveOccurred())
+
+			By("Creating a jira ticket")
+			ticket, err := reconciler.createJiraTicket(ctx, jitRequest, jitConfig.JiraProject, jitConfig.JiraIssueType, jitConfig.ServiceDesk, jitConfig.CustomFields, jitConfig.RequiredFields, jitConfig.Labels, jitConfig.Environment, time.UTC, templates.New(jitConfig))
+			Expect(err).NotTo(HaveOccurred())
+			jitRequest.Status.JiraTicket = ticket
+
+			By("Approving the jira ticket as the reporter")
+			testUtils.IssueStatus = testUtils.TestJiraWorkflowApproved
+			testUtils.IssueApprover = "john117"
+			DeferCleanup(func() { testUtils.IssueApprover = "cptKeyes" })
+			jitConfig.JiraWorkflowApproveStatus = testUtils.IssueStatus
+			jitConfig.SelfApprovalEnabled = false
+
+			By("Checking the reporter's own approval does not count")
+			err = reconciler.getJiraApproval(ctx, jitRequest, jitConfig)
+			Expect(err).To(MatchError(ContainSubstring("0 of 1 required approvals")))
+
+			By("Checking another approver approves the ticket")
+			testUtils.IssueApprover = "cptKeyes"
+			err = reconciler.getJiraApproval(ctx, jitRequest, jitConfig)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(jitRequest.Status.Approvals).To(HaveLen(1))
+			Expect(jitRequest.Status.Approvals[0].Approver).To(Equal("cptKeyes"))
+
+			By("Checking the reporter's own approval counts with self-approval enabled")
+			testUtils.IssueApprover = "john117"
+			jitConfig.SelfApprovalEnabled = true
+			err = reconciler.getJiraApproval(ctx, jitRequest, jitConfig)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("should require a quorum of distinct approvers other than the reporter in serviceDesk mode", func() {
+			By("Simulating a valid JitRequest")
+			jitRequest, err := testUtils.CreateJitRequest(ctx, reconciler.Client, 10, testUtils.ValidClusterRole, TestNamespace)
+			Expect(err).NotTo(HaveOccurred())
+
+			By("Creating a jira ticket")
+			ticket, err := reconciler.createJiraTicket(ctx, jitRequest, jitConfig.JiraProject, jitConfig.JiraIssueType, jitConfig.ServiceDesk, jitConfig.CustomFields, jitConfig.RequiredFields, jitConfig.Labels, jitConfig.Environment, time.UTC, templates.New(jitConfig))
+			Expect(err).NotTo(HaveOccurred())
+			jitRequest.Status.JiraTicket = ticket
+
+			By("Requiring two approvals for the cluster role")
+			jitConfig.ApprovalMode = v1.ApprovalModeServiceDesk
+			jitConfig.RolePolicies = map[string]v1.RolePolicySpec{
+				testUtils.ValidClusterRole: {RequiredApprovals: 2},
+			}
+			DeferCleanup(func() {
+				testUtils.ServiceDeskApprovers = nil
+			})
+
+			By("Checking the reporter's own approval does not count")
+			testUtils.ServiceDeskApprovers = []string{jitRequest.Spec.Reporter, "cpt-keyes@unsc.com"}
+			err = reconciler.getJiraApproval(ctx, jitRequest, jitConfig)
+			Expect(err).To(MatchError(ContainSubstring("1 of 2 required approvals")))
+			Expect(jitRequest.Status.Approvals).To(HaveLen(1))
+			Expect(jitRequest.Status.Approvals[0].Approver).To(Equal("cptKeyes"))
+
+			By("Checking a second distinct approver approves the ticket")
+			testUtils.ServiceDeskApprovers = []string{"cpt-keyes@unsc.com", "oni@unsc.com", "oni@unsc.com"}
+			err = reconciler.getJiraApproval(ctx, jitRequest, jitConfig)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(jitRequest.Status.Approvals).To(HaveLen(2))
+		})
 	})
 })
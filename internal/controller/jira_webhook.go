@@ -0,0 +1,215 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	justintimev1 "jira-jit-rbac-operator/api/v1"
+)
+
+const (
+	// JiraWebhookPath is the path Jira webhooks are received on
+	JiraWebhookPath = "/jira/webhook"
+	// JiraTicketIndex indexes JitRequests by their Jira ticket
+	JiraTicketIndex = "status.jiraTicket"
+	// JiraIssueUpdatedEvent is the only Jira webhook event acted on
+	JiraIssueUpdatedEvent = "jira:issue_updated"
+	// JiraSignatureHeader carries the HMAC signature of the payload, set by Jira from the secret of the webhook
+	JiraSignatureHeader = "X-Hub-Signature"
+	// JiraEventsBufferSize is the capacity of the events channel, webhooks are refused once it is full
+	JiraEventsBufferSize = 100
+	// jiraWebhookMaxBodyBytes limits the size of webhook payloads
+	jiraWebhookMaxBodyBytes = 1 << 20
+)
+
+// JiraWebhookReceiver receives Jira issue webhooks and enqueues a reconcile of the matching JitRequest(s)
+type JiraWebhookReceiver struct {
+	client.Client
+	// Address to serve webhooks on, i.e. ":8090"
+	BindAddress string
+	// Shared secret, verified as the HMAC signature of the payload
+	Secret string
+	// Events to enqueue JitRequests on, watched by the JitRequestReconciler, buffered with JiraEventsBufferSize
+	Events chan<- event.GenericEvent
+	// Closed once this replica is the leader running the JitRequestReconciler, defaults to the Elected channel of the manager
+	Elected <-chan struct{}
+}
+
+// jiraWebhookPayload is the part of a Jira webhook payload used to find the JitRequest(s)
+type jiraWebhookPayload struct {
+	WebhookEvent string `json:"webhookEvent"`
+	Issue        struct {
+		Key string `json:"key"`
+	} `json:"issue"`
+}
+
+// SetupJiraWebhookWithManager indexes JitRequests by Jira ticket and adds the receiver to the manager
+func SetupJiraWebhookWithManager(mgr ctrl.Manager, receiver *JiraWebhookReceiver) error {
+	if receiver.Secret == "" {
+		return fmt.Errorf("a secret is required to receive Jira webhooks")
+	}
+	if receiver.Elected == nil {
+		receiver.Elected = mgr.Elected()
+	}
+	if err := mgr.GetFieldIndexer().IndexField(context.Background(), &justintimev1.JitRequest{}, JiraTicketIndex, indexJiraTicket); err != nil {
+		return fmt.Errorf("failed to index JitRequests by jira ticket: %w", err)
+	}
+	return mgr.Add(receiver)
+}
+
+// indexJiraTicket returns the Jira ticket of a JitRequest for the field index
+func indexJiraTicket(obj client.Object) []string {
+	jitRequest, ok := obj.(*justintimev1.JitRequest)
+	if !ok || jitRequest.Status.JiraTicket == "" || jitRequest.Status.JiraTicket == Skipped {
+		return nil
+	}
+	return []string{jitRequest.Status.JiraTicket}
+}
+
+// NeedLeaderElection returns false so webhooks are served by every replica behind the service,
+// replicas that are not the leader refuse them with a 503 so Jira retries them
+func (w *JiraWebhookReceiver) NeedLeaderElection() bool {
+	return false
+}
+
+// Start serves Jira webhooks until the context is cancelled
+func (w *JiraWebhookReceiver) Start(ctx context.Context) error {
+	l := log.FromContext(ctx).WithName("jira-webhook")
+
+	mux := http.NewServeMux()
+	mux.Handle(JiraWebhookPath, w)
+	server := &http.Server{
+		Addr:              w.BindAddress,
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := server.Shutdown(shutdownCtx); err != nil {
+			l.Error(err, "failed to shut down jira webhook server")
+		}
+	}()
+
+	l.Info("Serving Jira webhooks", "address", w.BindAddress, "path", JiraWebhookPath)
+	if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+// ServeHTTP verifies a Jira webhook and enqueues the JitRequest(s) for its issue.
+// It never blocks on the events channel, webhooks that can't be enqueued are refused with a 503 for Jira to retry them.
+func (w *JiraWebhookReceiver) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	ctx := req.Context()
+	l := log.FromContext(ctx).WithName("jira-webhook")
+
+	if req.Method != http.MethodPost {
+		http.Error(rw, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	body, err := io.ReadAll(http.MaxBytesReader(rw, req.Body, jiraWebhookMaxBodyBytes))
+	if err != nil {
+		http.Error(rw, "failed to read payload", http.StatusBadRequest)
+		return
+	}
+
+	if !w.verify(req, body) {
+		l.Info("Rejected Jira webhook with an invalid signature", "remoteAddr", req.RemoteAddr)
+		http.Error(rw, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	var payload jiraWebhookPayload
+	if err := json.Unmarshal(body, &payload); err != nil {
+		http.Error(rw, "invalid payload", http.StatusBadRequest)
+		return
+	}
+
+	// other events have nothing to act on
+	if payload.WebhookEvent != JiraIssueUpdatedEvent {
+		rw.WriteHeader(http.StatusOK)
+		return
+	}
+	if payload.Issue.Key == "" {
+		http.Error(rw, "missing issue key", http.StatusBadRequest)
+		return
+	}
+
+	// only the leader reconciles the events
+	select {
+	case <-w.Elected:
+	default:
+		http.Error(rw, "not the leader, retry later", http.StatusServiceUnavailable)
+		return
+	}
+
+	jitRequests := &justintimev1.JitRequestList{}
+	if err := w.List(ctx, jitRequests, client.MatchingFields{JiraTicketIndex: payload.Issue.Key}); err != nil {
+		l.Error(err, "failed to list JitRequests", "jiraTicket", payload.Issue.Key)
+		http.Error(rw, "failed to find JitRequest", http.StatusInternalServerError)
+		return
+	}
+
+	for i := range jitRequests.Items {
+		jitRequest := &jitRequests.Items[i]
+		select {
+		case w.Events <- event.GenericEvent{Object: jitRequest}:
+			l.Info("Enqueued JitRequest from Jira webhook", "jiraTicket", payload.Issue.Key, "name", jitRequest.Name)
+		default:
+			l.Info("Refused Jira webhook, too many pending events", "jiraTicket", payload.Issue.Key, "name", jitRequest.Name)
+			http.Error(rw, "too many pending webhooks, retry later", http.StatusServiceUnavailable)
+			return
+		}
+	}
+
+	rw.WriteHeader(http.StatusAccepted)
+}
+
+// verify checks the HMAC signature of the payload, unsigned webhooks are rejected
+func (w *JiraWebhookReceiver) verify(req *http.Request, body []byte) bool {
+	signature := req.Header.Get(JiraSignatureHeader)
+	if signature == "" {
+		return false
+	}
+	expected, err := hex.DecodeString(strings.TrimPrefix(signature, "sha256="))
+	if err != nil {
+		return false
+	}
+	mac := hmac.New(sha256.New, []byte(w.Secret))
+	mac.Write(body)
+	return hmac.Equal(mac.Sum(nil), expected)
+}
@@ -0,0 +1,139 @@
+package controller
+
+import (
+	"bytes"
+	v1 "jira-jit-rbac-operator/api/v1"
+	testUtils "jira-jit-rbac-operator/test/utils"
+	"net/http"
+	"net/http/httptest"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+)
+
+var _ = Describe("JiraWebhookReceiver Unit Tests", Label("unit", "jira-webhook"), func() {
+
+	const secret = "cortana"
+
+	var receiver *JiraWebhookReceiver
+	var events chan event.GenericEvent
+	var elected chan struct{}
+
+	BeforeEach(func() {
+		By("setting a receiver with a pre-approved JitRequest for the Jira ticket")
+		jitRequest := &v1.JitRequest{
+			ObjectMeta: metav1.ObjectMeta{Name: JitRequestName},
+			Status: v1.JitRequestStatus{
+				State:      StatusPreApproved,
+				JiraTicket: JiraTicket,
+			},
+		}
+		otherJitRequest := &v1.JitRequest{
+			ObjectMeta: metav1.ObjectMeta{Name: "other-jit-test"},
+			Status: v1.JitRequestStatus{
+				State:      StatusPreApproved,
+				JiraTicket: "IAM-2",
+			},
+		}
+		k8sClient := fake.NewClientBuilder().
+			WithScheme(scheme.Scheme).
+			WithIndex(&v1.JitRequest{}, JiraTicketIndex, indexJiraTicket).
+			WithObjects(jitRequest, otherJitRequest).
+			Build()
+
+		events = make(chan event.GenericEvent, 10)
+		elected = make(chan struct{})
+		close(elected)
+		receiver = &JiraWebhookReceiver{
+			Client:  k8sClient,
+			Secret:  secret,
+			Events:  events,
+			Elected: elected,
+		}
+	})
+
+	send := func(payload []byte, target, signature string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, target, bytes.NewReader(payload))
+		if signature != "" {
+			req.Header.Set(JiraSignatureHeader, signature)
+		}
+		rec := httptest.NewRecorder()
+		receiver.ServeHTTP(rec, req)
+		return rec
+	}
+
+	It("should enqueue the JitRequest for a signed issue updated webhook", func() {
+		payload := testUtils.JiraWebhookPayload(JiraTicket, "Approved")
+		rec := send(payload, JiraWebhookPath, testUtils.SignJiraWebhook(payload, secret))
+		Expect(rec.Code).To(Equal(http.StatusAccepted))
+
+		By("Checking only the JitRequest for the Jira ticket is enqueued")
+		Expect(events).To(HaveLen(1))
+		Expect((<-events).Object.GetName()).To(Equal(JitRequestName))
+	})
+
+	It("should reject a webhook with only the secret query parameter", func() {
+		payload := testUtils.JiraWebhookPayload(JiraTicket, "rejected")
+		rec := send(payload, JiraWebhookPath+"?secret="+secret, "")
+		Expect(rec.Code).To(Equal(http.StatusUnauthorized))
+		Expect(events).To(BeEmpty())
+	})
+
+	It("should reject a webhook with an invalid signature", func() {
+		payload := testUtils.JiraWebhookPayload(JiraTicket, "Approved")
+		rec := send(payload, JiraWebhookPath, testUtils.SignJiraWebhook(payload, "flood"))
+		Expect(rec.Code).To(Equal(http.StatusUnauthorized))
+		Expect(events).To(BeEmpty())
+	})
+
+	It("should serve webhooks without leader election", func() {
+		Expect(receiver.NeedLeaderElection()).To(BeFalse())
+	})
+
+	It("should reject a webhook without a secret", func() {
+		payload := testUtils.JiraWebhookPayload(JiraTicket, "Approved")
+		rec := send(payload, JiraWebhookPath, "")
+		Expect(rec.Code).To(Equal(http.StatusUnauthorized))
+		Expect(events).To(BeEmpty())
+	})
+
+	It("should ignore other webhook events", func() {
+		payload := []byte(`{"webhookEvent":"jira:issue_created","issue":{"key":"IAM-1"}}`)
+		rec := send(payload, JiraWebhookPath, testUtils.SignJiraWebhook(payload, secret))
+		Expect(rec.Code).To(Equal(http.StatusOK))
+		Expect(events).To(BeEmpty())
+	})
+
+	It("should accept a webhook for an unknown Jira ticket without enqueuing", func() {
+		payload := testUtils.JiraWebhookPayload("IAM-404", "Approved")
+		rec := send(payload, JiraWebhookPath, testUtils.SignJiraWebhook(payload, secret))
+		Expect(rec.Code).To(Equal(http.StatusAccepted))
+		Expect(events).To(BeEmpty())
+	})
+
+	It("should refuse a webhook with a 503 until this replica is the leader", func() {
+		receiver.Elected = make(chan struct{})
+		payload := testUtils.JiraWebhookPayload(JiraTicket, "Approved")
+		rec := send(payload, JiraWebhookPath, testUtils.SignJiraWebhook(payload, secret))
+		Expect(rec.Code).To(Equal(http.StatusServiceUnavailable))
+		Expect(events).To(BeEmpty())
+	})
+
+	It("should refuse a webhook with a 503 instead of blocking while the events are full", func() {
+		receiver.Events = make(chan event.GenericEvent)
+		payload := testUtils.JiraWebhookPayload(JiraTicket, "Approved")
+		rec := send(payload, JiraWebhookPath, testUtils.SignJiraWebhook(payload, secret))
+		Expect(rec.Code).To(Equal(http.StatusServiceUnavailable))
+	})
+
+	It("should only accept POST requests", func() {
+		req := httptest.NewRequest(http.MethodGet, JiraWebhookPath, nil)
+		rec := httptest.NewRecorder()
+		receiver.ServeHTTP(rec, req)
+		Expect(rec.Code).To(Equal(http.StatusMethodNotAllowed))
+	})
+})
@@ -22,15 +22,19 @@ import (
 	"strings"
 
 	jira "github.com/ctreminiom/go-atlassian/v2/jira/v2"
+	"github.com/go-logr/logr"
 
+	"k8s.io/apimachinery/pkg/api/equality"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/builder" // Required for Watching
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/source"
 
 	justintimev1 "jira-jit-rbac-operator/api/v1"
 	"jira-jit-rbac-operator/pkg/utils"
@@ -49,14 +53,19 @@ var OperatorNamespace = os.Getenv("OPERATOR_NAMESPACE")
 // +kubebuilder:rbac:groups=core,resources=events,verbs=create;patch
 
 // +kubebuilder:rbac:groups=rbac.authorization.k8s.io,resources=rolebindings,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=rbac.authorization.k8s.io,resources=clusterrolebindings,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups="",resources=namespaces,verbs=get;list;watch
 
 // JitRequestReconciler reconciles a JitRequest object
 type JitRequestReconciler struct {
 	JiraClient *jira.Client
+	// Jira Server or Cloud, defaults to Server if empty
+	JiraFlavour utils.JiraFlavour
 	client.Client
 	Scheme   *runtime.Scheme
 	Recorder record.EventRecorder
+	// Optional events from the JiraWebhookReceiver to reconcile a JitRequest as soon as its Jira ticket is updated
+	JiraEvents <-chan event.GenericEvent
 }
 
 // Reconcile is the main loop for reconciling a JitRequest
@@ -74,42 +83,55 @@ func (r *JitRequestReconciler) Reconcile(ctx context.Context, req ctrl.Request)
 	if err != nil {
 		return ctrl.Result{}, err
 	}
-	jiraWorkflowApproveStatus := operatorConfig.JiraWorkflowApproveStatus
-	rejectedTransitionID := operatorConfig.RejectedTransitionID
-	allowedClusterRoles := operatorConfig.AllowedClusterRoles
-	jiraProject := operatorConfig.JiraProject
-	jiraIssueType := operatorConfig.JiraIssueType
-	completedTransitionID := operatorConfig.CompletedTransitionID
-	customFieldsConfig := operatorConfig.CustomFields
-	requiredFieldsConfig := operatorConfig.RequiredFields
-	ticketLabels := operatorConfig.Labels
-	targetEnvironment := operatorConfig.Environment
-	additionalComments := operatorConfig.AdditionalCommentText
-
-	l.Info("Got JitRequest", "Requestor", jitRequest.Spec.Reporter, "Role", jitRequest.Spec.ClusterRole, "Namespace", strings.Join(jitRequest.Spec.Namespaces, ", "))
+	l.Info("Got JitRequest", "Requestor", jitRequest.Spec.Reporter, "Role", jitRequest.Spec.ClusterRole, "Namespace", strings.Join(jitRequest.Spec.Namespaces, ", "), "ClusterScoped", jitRequest.Spec.ClusterScoped)
+
+	result, err := r.reconcileState(ctx, l, jitRequest, operatorConfig)
+
+	// requeue instead of erroring while Jira is unavailable
+	return r.handleJiraAvailability(ctx, l, jitRequest, result, err)
+}
+
+// reconcileState handles a JitRequest based on its status
+func (r *JitRequestReconciler) reconcileState(ctx context.Context, l logr.Logger, jitRequest *justintimev1.JitRequest, operatorConfig *justintimev1.JustInTimeConfigSpec) (ctrl.Result, error) {
+	// Revoke access early if requested
+	if jitRequest.Spec.Revocation != nil && !isFinished(jitRequest) {
+		return r.handleRevoked(ctx, l, jitRequest, operatorConfig)
+	}
 
 	// Handle JitRequest based on its status
 	switch jitRequest.Status.State {
 	case StatusRejected:
-		return r.handleRejected(ctx, l, jitRequest, rejectedTransitionID)
+		return r.handleRejected(ctx, l, jitRequest, operatorConfig)
+	case StatusRevoked, StatusExpired:
+		// keep finished JitRequests for auditing until retention period has passed
+		return r.handleFinished(ctx, l, jitRequest, operatorConfig)
 	case "":
-		return r.handleNewRequest(ctx, l, jitRequest, allowedClusterRoles, jiraProject, jiraIssueType, customFieldsConfig, requiredFieldsConfig, ticketLabels, targetEnvironment, additionalComments)
+		return r.handleNewRequest(ctx, l, jitRequest, operatorConfig)
 	case StatusPreApproved:
-		return r.handlePreApproved(ctx, l, jitRequest, completedTransitionID, jiraWorkflowApproveStatus)
+		return r.handlePreApproved(ctx, l, jitRequest, operatorConfig)
 	case StatusSucceeded:
-		return r.handleCleanup(ctx, l, jitRequest)
+		return r.handleSucceeded(ctx, l, jitRequest, operatorConfig)
 	default:
-		return r.handleCleanup(ctx, l, jitRequest)
+		return r.handleCleanup(ctx, l, jitRequest, operatorConfig)
 	}
 }
 
 // jitRequestPredicate filters events for JitRequest objects and ignores is StatusRejected is identical for update events
+// revocations and extension requests are always passed for update events
 func jitRequestPredicate() predicate.Predicate {
 	return predicate.Funcs{
 		UpdateFunc: func(e event.UpdateEvent) bool {
 			oldJitRequest := e.ObjectOld.(*justintimev1.JitRequest)
 			newJitRequest := e.ObjectNew.(*justintimev1.JitRequest)
 
+			if oldJitRequest.Spec.Revocation == nil && newJitRequest.Spec.Revocation != nil {
+				return true
+			}
+
+			if !equality.Semantic.DeepEqual(oldJitRequest.Spec.Extension, newJitRequest.Spec.Extension) {
+				return true
+			}
+
 			if oldJitRequest.Status.State == StatusRejected &&
 				newJitRequest.Status.State == StatusRejected {
 				return false
@@ -122,8 +144,14 @@ func jitRequestPredicate() predicate.Predicate {
 
 // SetupWithManager sets up the controller with the Manager.
 func (r *JitRequestReconciler) SetupWithManager(mgr ctrl.Manager) error {
-	return ctrl.NewControllerManagedBy(mgr).
+	b := ctrl.NewControllerManagedBy(mgr).
 		For(&justintimev1.JitRequest{}, builder.WithPredicates(predicate.ResourceVersionChangedPredicate{}, jitRequestPredicate())).
-		Named("jitrequest").
-		Complete(r)
+		Named("jitrequest")
+
+	// reconcile on Jira webhooks, these bypass the predicates as nothing changed on the JitRequest itself
+	if r.JiraEvents != nil {
+		b = b.WatchesRawSource(source.Channel(r.JiraEvents, &handler.EnqueueRequestForObject{}))
+	}
+
+	return b.Complete(r)
 }
@@ -4,12 +4,15 @@ import (
 	"context"
 	"fmt"
 	justintimev1 "jira-jit-rbac-operator/api/v1"
+	"jira-jit-rbac-operator/pkg/utils"
+	"strings"
 
 	"github.com/go-logr/logr"
 	corev1 "k8s.io/api/core/v1"
 	rbacv1 "k8s.io/api/rbac/v1"
 
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/util/retry"
 
@@ -33,8 +36,10 @@ func (r *JitRequestReconciler) updateStatus(ctx context.Context, jitRequest *jus
 	jitRequest.Status.State = status
 	jitRequest.Status.Message = message
 	jitRequest.Status.JiraTicket = jiraTicket
-	jitRequest.Status.StartTime = jitRequest.Spec.StartTime
-	jitRequest.Status.EndTime = jitRequest.Spec.EndTime
+	// startOnApproval requests only get a start and end time when access is granted
+	if !jitRequest.Spec.StartOnApproval {
+		setAccessTimes(jitRequest, time.Now())
+	}
 
 	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
 		return r.Status().Update(ctx, jitRequest)
@@ -47,6 +52,155 @@ func (r *JitRequestReconciler) updateStatus(ctx context.Context, jitRequest *jus
 	return nil
 }
 
+// setAccessTimes sets the status start and end time from the spec, startOnApproval requests start at the given time
+func setAccessTimes(jitRequest *justintimev1.JitRequest, now time.Time) {
+	startTime, endTime := utils.RequestedTimes(jitRequest, now)
+	// only set once, the end time can move on an approved extension
+	if jitRequest.Status.StartTime.IsZero() {
+		jitRequest.Status.StartTime = metav1.NewTime(startTime)
+	}
+	if jitRequest.Status.EndTime.IsZero() {
+		jitRequest.Status.EndTime = metav1.NewTime(endTime)
+	}
+}
+
+// approvalDeadline returns the time a JitRequest must be approved by before it is rejected
+func approvalDeadline(jitRequest *justintimev1.JitRequest, gracePeriod, startOnApprovalTimeout time.Duration) time.Time {
+	// late approvals are accepted within the grace period, but never after the end time
+	if !jitRequest.Spec.StartOnApproval {
+		deadline := jitRequest.Status.StartTime.Add(gracePeriod)
+		if !jitRequest.Status.EndTime.IsZero() && jitRequest.Status.EndTime.Time.Before(deadline) {
+			return jitRequest.Status.EndTime.Time
+		}
+		return deadline
+	}
+
+	deadline := jitRequest.CreationTimestamp.Add(startOnApprovalTimeout)
+	if !jitRequest.Spec.EndTime.IsZero() && jitRequest.Spec.EndTime.Time.Before(deadline) {
+		return jitRequest.Spec.EndTime.Time
+	}
+	return deadline
+}
+
+// getApprovalPollInterval returns the configured interval to poll Jira for approval or the default
+func getApprovalPollInterval(operatorConfig *justintimev1.JustInTimeConfigSpec) time.Duration {
+	if operatorConfig.ApprovalPollInterval == nil || operatorConfig.ApprovalPollInterval.Duration <= 0 {
+		return DefaultApprovalPollInterval
+	}
+	return operatorConfig.ApprovalPollInterval.Duration
+}
+
+// getStartOnApprovalTimeout returns the configured period to wait for approval of a startOnApproval JitRequest or the default
+func getStartOnApprovalTimeout(operatorConfig *justintimev1.JustInTimeConfigSpec) time.Duration {
+	if operatorConfig.StartOnApprovalTimeout == nil || operatorConfig.StartOnApprovalTimeout.Duration <= 0 {
+		return DefaultStartOnApprovalTimeout
+	}
+	return operatorConfig.StartOnApprovalTimeout.Duration
+}
+
+// getApprovalGracePeriod returns the configured grace period for late approvals, none by default
+func getApprovalGracePeriod(operatorConfig *justintimev1.JustInTimeConfigSpec) time.Duration {
+	if operatorConfig.ApprovalGracePeriod == nil {
+		return 0
+	}
+	return operatorConfig.ApprovalGracePeriod.Duration
+}
+
+// setCondition sets a condition on a JitRequest, it is persisted with the next status update
+func setCondition(jitRequest *justintimev1.JitRequest, conditionType string, status metav1.ConditionStatus, reason, message string) {
+	meta.SetStatusCondition(&jitRequest.Status.Conditions, metav1.Condition{
+		Type:               conditionType,
+		Status:             status,
+		ObservedGeneration: jitRequest.Generation,
+		Reason:             reason,
+		Message:            message,
+	})
+}
+
+// updateJiraCondition sets the JiraAvailable condition on the latest JitRequest, without the changes of a failed reconcile
+func (r *JitRequestReconciler) updateJiraCondition(ctx context.Context, jitRequest *justintimev1.JitRequest, status metav1.ConditionStatus, reason, message string) error {
+	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		latest, err := r.fetchJitRequest(ctx, client.ObjectKeyFromObject(jitRequest))
+		if err != nil {
+			return err
+		}
+		setCondition(latest, justintimev1.ConditionJiraAvailable, status, reason, message)
+		return r.Status().Update(ctx, latest)
+	})
+	if client.IgnoreNotFound(err) != nil {
+		return fmt.Errorf("failed to update JitRequest status: %v", err)
+	}
+	return nil
+}
+
+// updateCompletedStatus updates the status of a JitRequest that reached a final state and records the completion time
+func (r *JitRequestReconciler) updateCompletedStatus(ctx context.Context, jitRequest *justintimev1.JitRequest, status, message string) error {
+	if jitRequest.Status.CompletionTime == nil {
+		now := metav1.Now()
+		jitRequest.Status.CompletionTime = &now
+	}
+	return r.updateStatus(ctx, jitRequest, status, message, jitRequest.Status.JiraTicket)
+}
+
+// getRetentionPeriod returns the configured retention period for finished JitRequests or the default
+func getRetentionPeriod(operatorConfig *justintimev1.JustInTimeConfigSpec) time.Duration {
+	if operatorConfig.RetentionPeriod == nil {
+		return DefaultRetentionPeriod
+	}
+	return operatorConfig.RetentionPeriod.Duration
+}
+
+// isJiraRejected returns true if the Jira ticket has been moved to one of the configured rejected statuses
+func isJiraRejected(jitRequest *justintimev1.JitRequest, operatorConfig *justintimev1.JustInTimeConfigSpec) bool {
+	for _, status := range utils.RejectedStatuses(operatorConfig) {
+		if strings.EqualFold(jitRequest.Status.JiraStatus, status) {
+			return true
+		}
+	}
+	return false
+}
+
+// isFinished returns true if a JitRequest is in a final state
+func isFinished(jitRequest *justintimev1.JitRequest) bool {
+	switch jitRequest.Status.State {
+	case StatusRejected, StatusRevoked, StatusExpired:
+		return true
+	}
+	return false
+}
+
+// updateExtensionStatus updates the extension status of a JitRequest
+func (r *JitRequestReconciler) updateExtensionStatus(ctx context.Context, jitRequest *justintimev1.JitRequest, state, message string) error {
+	extension := &justintimev1.ExtensionStatus{
+		State:   state,
+		EndTime: jitRequest.Spec.Extension.EndTime,
+		Message: message,
+	}
+	// keep when the extension was requested and its approvers, approvals before a new request don't count
+	previous := jitRequest.Status.Extension
+	if state == justintimev1.ExtensionPending {
+		now := metav1.Now()
+		extension.RequestedAt = &now
+	} else if previous != nil && previous.EndTime.Equal(&extension.EndTime) {
+		extension.RequestedAt = previous.RequestedAt
+		extension.Approvals = previous.Approvals
+	}
+	jitRequest.Status.Extension = extension
+	return r.updateStatus(ctx, jitRequest, jitRequest.Status.State, jitRequest.Status.Message, jitRequest.Status.JiraTicket)
+}
+
+// requeueDelay returns the delay to poll Jira for approval, bounded by the deadline
+func requeueDelay(pollInterval time.Duration, deadline time.Time) time.Duration {
+	delay := time.Until(deadline)
+	if delay > pollInterval {
+		return pollInterval
+	}
+	if delay < 0 {
+		return 0
+	}
+	return delay
+}
+
 // deleteJitRequest deletes a JitRequest
 func (r *JitRequestReconciler) deleteJitRequest(ctx context.Context, jitRequest *justintimev1.JitRequest) error {
 	l := log.FromContext(ctx)
@@ -74,6 +228,7 @@ func (r *JitRequestReconciler) raiseEvent(obj client.Object, eventType, reason,
 // rejectInvalidNamespace rejects an invalid namespace
 func (r *JitRequestReconciler) rejectInvalidNamespace(ctx context.Context, l logr.Logger, jitRequest *justintimev1.JitRequest, jiraIssueKey, namespace, err string) (ctrl.Result, error) {
 	errorMsg := fmt.Sprintf("Namespace(s) %s not validated | Error: %s", namespace, err)
+	setCondition(jitRequest, justintimev1.ConditionValidated, metav1.ConditionFalse, ReasonInvalidNamespace, errorMsg)
 	r.raiseEvent(jitRequest, "Warning", EventValidationFailed, errorMsg)
 	if err := r.updateStatus(ctx, jitRequest, StatusRejected, errorMsg, jiraIssueKey); err != nil {
 		l.Error(err, "failed to update status to Rejected")
@@ -85,6 +240,34 @@ func (r *JitRequestReconciler) rejectInvalidNamespace(ctx context.Context, l log
 // rejectInvalidRole rejects an invalid cluster role
 func (r *JitRequestReconciler) rejectInvalidRole(ctx context.Context, l logr.Logger, jitRequest *justintimev1.JitRequest, jiraIssueKey string) (ctrl.Result, error) {
 	errorMsg := fmt.Sprintf("ClusterRole '%s' is not allowed", jitRequest.Spec.ClusterRole)
+	if jitRequest.Spec.ClusterScoped {
+		errorMsg = fmt.Sprintf("ClusterRole '%s' is not allowed cluster-wide", jitRequest.Spec.ClusterRole)
+	}
+	setCondition(jitRequest, justintimev1.ConditionValidated, metav1.ConditionFalse, ReasonInvalidClusterRole, errorMsg)
+	r.raiseEvent(jitRequest, "Warning", EventValidationFailed, errorMsg)
+	if err := r.updateStatus(ctx, jitRequest, StatusRejected, errorMsg, jiraIssueKey); err != nil {
+		l.Error(err, "failed to update status to Rejected")
+		return ctrl.Result{}, err
+	}
+	return ctrl.Result{}, nil
+}
+
+// rejectInvalidSubject rejects an invalid subject
+func (r *JitRequestReconciler) rejectInvalidSubject(ctx context.Context, l logr.Logger, jitRequest *justintimev1.JitRequest, jiraIssueKey, err string) (ctrl.Result, error) {
+	errorMsg := fmt.Sprintf("Subject(s) not validated | Error: %s", err)
+	setCondition(jitRequest, justintimev1.ConditionValidated, metav1.ConditionFalse, ReasonInvalidSubject, errorMsg)
+	r.raiseEvent(jitRequest, "Warning", EventValidationFailed, errorMsg)
+	if err := r.updateStatus(ctx, jitRequest, StatusRejected, errorMsg, jiraIssueKey); err != nil {
+		l.Error(err, "failed to update status to Rejected")
+		return ctrl.Result{}, err
+	}
+	return ctrl.Result{}, nil
+}
+
+// rejectPolicyViolation rejects a JitRequest outside the policy for its cluster role
+func (r *JitRequestReconciler) rejectPolicyViolation(ctx context.Context, l logr.Logger, jitRequest *justintimev1.JitRequest, jiraIssueKey, err string) (ctrl.Result, error) {
+	errorMsg := fmt.Sprintf("ClusterRole '%s' policy not met | Error: %s", jitRequest.Spec.ClusterRole, err)
+	setCondition(jitRequest, justintimev1.ConditionValidated, metav1.ConditionFalse, ReasonPolicyViolation, errorMsg)
 	r.raiseEvent(jitRequest, "Warning", EventValidationFailed, errorMsg)
 	if err := r.updateStatus(ctx, jitRequest, StatusRejected, errorMsg, jiraIssueKey); err != nil {
 		l.Error(err, "failed to update status to Rejected")
@@ -93,6 +276,18 @@ func (r *JitRequestReconciler) rejectInvalidRole(ctx context.Context, l logr.Log
 	return ctrl.Result{}, nil
 }
 
+// rejectInvalidLinkedIssue rejects a JitRequest with an invalid linked issue before its Jira ticket is created
+func (r *JitRequestReconciler) rejectInvalidLinkedIssue(ctx context.Context, l logr.Logger, jitRequest *justintimev1.JitRequest, err string) (ctrl.Result, error) {
+	errorMsg := fmt.Sprintf("Linked issue '%s' not validated | Error: %s", jitRequest.Spec.LinkedJiraTicket, err)
+	setCondition(jitRequest, justintimev1.ConditionValidated, metav1.ConditionFalse, ReasonInvalidLinkedIssue, errorMsg)
+	r.raiseEvent(jitRequest, "Warning", EventValidationFailed, errorMsg)
+	if err := r.updateStatus(ctx, jitRequest, StatusRejected, errorMsg, Skipped); err != nil {
+		l.Error(err, "failed to update status to Rejected")
+		return ctrl.Result{}, err
+	}
+	return ctrl.Result{}, nil
+}
+
 // deleteOwnedObjects deletes role binding(s) in case of k8s GC failed to delete
 func (r *JitRequestReconciler) deleteOwnedObjects(ctx context.Context, jitRequest *justintimev1.JitRequest) error {
 	for _, namespace := range jitRequest.Spec.Namespaces {
@@ -116,6 +311,27 @@ func (r *JitRequestReconciler) deleteOwnedObjects(ctx context.Context, jitReques
 		}
 	}
 
+	// Delete the cluster role binding of a cluster scoped JitRequest
+	if jitRequest.Spec.ClusterScoped {
+		clusterRoleBindings := &rbacv1.ClusterRoleBindingList{}
+
+		if err := r.List(ctx, clusterRoleBindings); err != nil {
+			return err
+		}
+
+		for _, clusterRoleBinding := range clusterRoleBindings.Items {
+			for _, ownerRef := range clusterRoleBinding.OwnerReferences {
+				if ownerRef.Kind == "JitRequest" && ownerRef.Name == jitRequest.Name {
+					// Delete the ClusterRoleBinding if it is owned by the JitRequest
+					if err := r.Delete(ctx, &clusterRoleBinding); err != nil && !apierrors.IsNotFound(err) {
+						return err
+					}
+					break
+				}
+			}
+		}
+	}
+
 	return nil
 }
 
@@ -124,8 +340,8 @@ func isAlreadyExistsError(err error) bool {
 	return err != nil && apierrors.IsAlreadyExists(err)
 }
 
-// createRoleBinding creates role binding(s) for a JitRequest's namespaces
-func (r *JitRequestReconciler) createRoleBinding(ctx context.Context, jitRequest *justintimev1.JitRequest) error {
+// buildSubjects returns the role binding subjects for a JitRequest
+func buildSubjects(jitRequest *justintimev1.JitRequest) []rbacv1.Subject {
 	// Add reporter to subject
 	subjects := []rbacv1.Subject{
 		{
@@ -142,15 +358,39 @@ func (r *JitRequestReconciler) createRoleBinding(ctx context.Context, jitRequest
 		})
 	}
 
+	// Add typed subjects if defined
+	for _, subject := range jitRequest.Spec.Subjects {
+		rbacSubject := rbacv1.Subject{
+			Kind: subject.Kind,
+			Name: subject.Name,
+		}
+		switch subject.Kind {
+		case rbacv1.ServiceAccountKind:
+			rbacSubject.Namespace = subject.Namespace
+		default:
+			rbacSubject.APIGroup = rbacv1.GroupName
+		}
+		subjects = append(subjects, rbacSubject)
+	}
+
+	return subjects
+}
+
+// createRoleBinding creates role binding(s) for a JitRequest's namespaces, or a cluster role binding if cluster scoped
+func (r *JitRequestReconciler) createRoleBinding(ctx context.Context, jitRequest *justintimev1.JitRequest) error {
+	if jitRequest.Spec.ClusterScoped {
+		return r.createClusterRoleBinding(ctx, jitRequest)
+	}
+
+	subjects := buildSubjects(jitRequest)
+
 	// Loop through namespaces in JitRequest and create role binding
 	for _, namespace := range jitRequest.Spec.Namespaces {
 		roleBinding := &rbacv1.RoleBinding{
 			ObjectMeta: metav1.ObjectMeta{
-				Name:      fmt.Sprintf("%s-jit", jitRequest.Name),
-				Namespace: namespace,
-				Annotations: map[string]string{
-					"justintime.samir.io/expiry": jitRequest.Spec.EndTime.Time.Format(time.RFC3339),
-				},
+				Name:        fmt.Sprintf("%s-jit", jitRequest.Name),
+				Namespace:   namespace,
+				Annotations: bindingAnnotations(jitRequest),
 			},
 			Subjects: subjects,
 			RoleRef: rbacv1.RoleRef{
@@ -175,3 +415,86 @@ func (r *JitRequestReconciler) createRoleBinding(ctx context.Context, jitRequest
 
 	return nil
 }
+
+// bindingAnnotations returns the expiry and approval audit annotations of the role bindings for a JitRequest
+func bindingAnnotations(jitRequest *justintimev1.JitRequest) map[string]string {
+	annotations := map[string]string{
+		ExpiryAnnotation:     jitRequest.Status.EndTime.Time.Format(time.RFC3339),
+		JiraTicketAnnotation: jitRequest.Status.JiraTicket,
+	}
+	if jitRequest.Status.ApprovedJiraStatus != "" {
+		annotations[JiraStatusAnnotation] = jitRequest.Status.ApprovedJiraStatus
+	}
+	if len(jitRequest.Status.Approvals) > 0 {
+		approvers := make([]string, 0, len(jitRequest.Status.Approvals))
+		for _, approval := range jitRequest.Status.Approvals {
+			approvers = append(approvers, approval.Approver)
+		}
+		annotations[ApprovedByAnnotation] = strings.Join(approvers, ",")
+	}
+	if jitRequest.Status.ApprovedAt != nil {
+		annotations[ApprovedAtAnnotation] = jitRequest.Status.ApprovedAt.Time.Format(time.RFC3339)
+	}
+	return annotations
+}
+
+// createClusterRoleBinding creates a cluster role binding for a cluster scoped JitRequest
+func (r *JitRequestReconciler) createClusterRoleBinding(ctx context.Context, jitRequest *justintimev1.JitRequest) error {
+	clusterRoleBinding := &rbacv1.ClusterRoleBinding{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        fmt.Sprintf("%s-jit", jitRequest.Name),
+			Annotations: bindingAnnotations(jitRequest),
+		},
+		Subjects: buildSubjects(jitRequest),
+		RoleRef: rbacv1.RoleRef{
+			APIGroup: rbacv1.GroupName,
+			Kind:     "ClusterRole",
+			Name:     jitRequest.Spec.ClusterRole,
+		},
+	}
+
+	// Set owner references
+	if err := ctrl.SetControllerReference(jitRequest, clusterRoleBinding, r.Scheme); err != nil {
+		return fmt.Errorf("failed to set owner reference for ClusterRoleBinding: %v", err)
+	}
+
+	// Create ClusterRoleBinding
+	if err := r.Client.Create(ctx, clusterRoleBinding); err != nil {
+		if !isAlreadyExistsError(err) {
+			return fmt.Errorf("failed to create ClusterRoleBinding: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// updateRoleBindingExpiry updates the expiry annotation on role binding(s) or the cluster role binding for a JitRequest
+func (r *JitRequestReconciler) updateRoleBindingExpiry(ctx context.Context, jitRequest *justintimev1.JitRequest, endTime metav1.Time) error {
+	name := fmt.Sprintf("%s-jit", jitRequest.Name)
+	expiry := endTime.Time.Format(time.RFC3339)
+
+	if jitRequest.Spec.ClusterScoped {
+		clusterRoleBinding := &rbacv1.ClusterRoleBinding{}
+		if err := r.Get(ctx, types.NamespacedName{Name: name}, clusterRoleBinding); err != nil {
+			return fmt.Errorf("failed to get ClusterRoleBinding: %w", err)
+		}
+		metav1.SetMetaDataAnnotation(&clusterRoleBinding.ObjectMeta, ExpiryAnnotation, expiry)
+		if err := r.Update(ctx, clusterRoleBinding); err != nil {
+			return fmt.Errorf("failed to update ClusterRoleBinding: %w", err)
+		}
+		return nil
+	}
+
+	for _, namespace := range jitRequest.Spec.Namespaces {
+		roleBinding := &rbacv1.RoleBinding{}
+		if err := r.Get(ctx, types.NamespacedName{Name: name, Namespace: namespace}, roleBinding); err != nil {
+			return fmt.Errorf("failed to get RoleBinding: %w", err)
+		}
+		metav1.SetMetaDataAnnotation(&roleBinding.ObjectMeta, ExpiryAnnotation, expiry)
+		if err := r.Update(ctx, roleBinding); err != nil {
+			return fmt.Errorf("failed to update RoleBinding: %w", err)
+		}
+	}
+
+	return nil
+}
@@ -12,6 +12,7 @@ import (
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/kubernetes/scheme"
@@ -226,6 +227,104 @@ var _ = Describe("JitRequestReconciler utils Unit Tests", Ordered, Label("unit",
 		})
 	})
 
+	Describe("rejectInvalidSubject", func() {
+
+		It("should reject invalid subjects", func() {
+			// Create JitRequest
+			jitRequest, err := testUtils.CreateJitRequest(ctx, reconciler.Client, 10, testUtils.ValidClusterRole, TestNamespace)
+			Expect(err).NotTo(HaveOccurred())
+
+			_, err = reconciler.rejectInvalidSubject(ctx, l, jitRequest, "jiraIssueKey", "error")
+			Expect(err).NotTo(HaveOccurred())
+
+			By("Checking the Validated condition is false")
+			validated := meta.FindStatusCondition(jitRequest.Status.Conditions, v1.ConditionValidated)
+			Expect(validated).NotTo(BeNil())
+			Expect(validated.Status).To(Equal(metav1.ConditionFalse))
+			Expect(validated.Reason).To(Equal(ReasonInvalidSubject))
+		})
+
+		It("should error if failed status update in rejectInvalidSubject", func() {
+			jitRequest := &v1.JitRequest{}
+			_, err := reconciler.rejectInvalidSubject(ctx, l, jitRequest, "jiraIssueKey", "error")
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("failed to update JitRequest status: resource name may not be empty"))
+		})
+	})
+
+	Describe("approvalDeadline", func() {
+
+		It("should allow the grace period after the start time, bounded by the end time", func() {
+			jitRequest := &v1.JitRequest{}
+			jitRequest.Status.StartTime = metav1.NewTime(time.Now())
+			jitRequest.Status.EndTime = metav1.NewTime(jitRequest.Status.StartTime.Add(time.Hour))
+			Expect(approvalDeadline(jitRequest, 15*time.Minute, DefaultStartOnApprovalTimeout)).To(Equal(jitRequest.Status.StartTime.Add(15 * time.Minute)))
+			Expect(approvalDeadline(jitRequest, 2*time.Hour, DefaultStartOnApprovalTimeout)).To(Equal(jitRequest.Status.EndTime.Time))
+		})
+
+		It("should use the start on approval timeout for startOnApproval requests", func() {
+			jitRequest := &v1.JitRequest{}
+			jitRequest.CreationTimestamp = metav1.NewTime(time.Now())
+			jitRequest.Spec.StartOnApproval = true
+			Expect(approvalDeadline(jitRequest, 0, DefaultStartOnApprovalTimeout)).To(Equal(jitRequest.CreationTimestamp.Add(DefaultStartOnApprovalTimeout)))
+			Expect(approvalDeadline(jitRequest, 0, 4*time.Hour)).To(Equal(jitRequest.CreationTimestamp.Add(4 * time.Hour)))
+		})
+
+		It("should use the configured start on approval timeout or the default", func() {
+			operatorConfig := &v1.JustInTimeConfigSpec{}
+			Expect(getStartOnApprovalTimeout(operatorConfig)).To(Equal(DefaultStartOnApprovalTimeout))
+			operatorConfig.StartOnApprovalTimeout = &metav1.Duration{Duration: 4 * time.Hour}
+			Expect(getStartOnApprovalTimeout(operatorConfig)).To(Equal(4 * time.Hour))
+		})
+	})
+
+	Describe("requeueDelay", func() {
+
+		It("should return the poll interval bounded by the deadline", func() {
+			Expect(requeueDelay(time.Minute, time.Now().Add(time.Hour))).To(Equal(time.Minute))
+			Expect(requeueDelay(time.Minute, time.Now().Add(10*time.Second))).To(BeNumerically("<=", 10*time.Second))
+			Expect(requeueDelay(time.Minute, time.Now().Add(-time.Second))).To(BeZero())
+		})
+	})
+
+	Describe("setCondition", func() {
+
+		It("should set and update a condition with the observed generation", func() {
+			jitRequest := &v1.JitRequest{}
+			jitRequest.Generation = 1
+			setCondition(jitRequest, v1.ConditionAccessGranted, metav1.ConditionTrue, ReasonRoleBindingCreated, "granted")
+			Expect(jitRequest.Status.Conditions).To(HaveLen(1))
+			Expect(jitRequest.Status.Conditions[0].ObservedGeneration).To(Equal(int64(1)))
+
+			By("Updating the same condition type")
+			jitRequest.Generation = 2
+			setCondition(jitRequest, v1.ConditionAccessGranted, metav1.ConditionFalse, ReasonEndTimeReached, "expired")
+			Expect(jitRequest.Status.Conditions).To(HaveLen(1))
+			Expect(jitRequest.Status.Conditions[0].Status).To(Equal(metav1.ConditionFalse))
+			Expect(jitRequest.Status.Conditions[0].Reason).To(Equal(ReasonEndTimeReached))
+			Expect(jitRequest.Status.Conditions[0].ObservedGeneration).To(Equal(int64(2)))
+		})
+	})
+
+	Describe("buildSubjects", func() {
+
+		It("should build subjects for the reporter, additional users and typed subjects", func() {
+			jitRequest := genericJitRequest
+			jitRequest.Spec.Subjects = []v1.SubjectSpec{
+				{Kind: rbacv1.GroupKind, Name: "on-call"},
+				{Kind: rbacv1.ServiceAccountKind, Name: "deployer", Namespace: TestNamespace},
+			}
+
+			subjects := buildSubjects(jitRequest)
+			Expect(subjects).To(ConsistOf(
+				rbacv1.Subject{Kind: rbacv1.UserKind, Name: "master-chief@unsc.com"},
+				rbacv1.Subject{Kind: rbacv1.UserKind, Name: "foo@foo.com"},
+				rbacv1.Subject{Kind: rbacv1.GroupKind, APIGroup: rbacv1.GroupName, Name: "on-call"},
+				rbacv1.Subject{Kind: rbacv1.ServiceAccountKind, Name: "deployer", Namespace: TestNamespace},
+			))
+		})
+	})
+
 	Describe("createRoleBinding", func() {
 
 		It("should create role bindings", func() {
@@ -245,6 +344,62 @@ var _ = Describe("JitRequestReconciler utils Unit Tests", Ordered, Label("unit",
 		})
 	})
 
+	Describe("createRoleBinding for a cluster scoped JitRequest", func() {
+
+		It("should create a cluster role binding", func() {
+			// Create cluster role binding
+			jitRequest := genericJitRequest
+			jitRequest.Spec.ClusterScoped = true
+			jitRequest.Spec.Namespaces = nil
+			jitRequest.Spec.ClusterRole = testUtils.ValidClusterScopedRole
+			err := reconciler.createRoleBinding(ctx, jitRequest)
+			Expect(err).NotTo(HaveOccurred())
+
+			By("checking cluster role binding exists")
+			crbName := fmt.Sprintf("%s-jit", jitRequest.Name)
+			crb := &rbacv1.ClusterRoleBinding{}
+			err = reconciler.Get(ctx, types.NamespacedName{Name: crbName}, crb)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(crb.RoleRef.Name).To(Equal(testUtils.ValidClusterScopedRole))
+			Expect(crb.Annotations).To(HaveKey(ExpiryAnnotation))
+		})
+	})
+
+	Describe("updateRoleBindingExpiry", func() {
+
+		It("should update the expiry annotation on role bindings", func() {
+			// Create role binding
+			jitRequest := genericJitRequest
+			jitRequest.ObjectMeta.UID = "updateRoleBindingExpiry"
+			jitRequest.ObjectMeta.Name = "update-role-binding-expiry"
+			err := reconciler.createRoleBinding(ctx, jitRequest)
+			Expect(err).NotTo(HaveOccurred())
+
+			// updateRoleBindingExpiry
+			endTime := metav1.NewTime(metav1.Now().Add(time.Hour))
+			err = reconciler.updateRoleBindingExpiry(ctx, jitRequest, endTime)
+			Expect(err).NotTo(HaveOccurred())
+
+			By("checking role binding expiry is updated")
+			rb := &rbacv1.RoleBinding{}
+			namespacedName := types.NamespacedName{
+				Namespace: TestNamespace,
+				Name:      fmt.Sprintf("%s-jit", jitRequest.Name),
+			}
+			err = reconciler.Get(ctx, namespacedName, rb)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(rb.Annotations[ExpiryAnnotation]).To(Equal(endTime.Time.Format(time.RFC3339)))
+		})
+
+		It("should error if the role binding does not exist", func() {
+			jitRequest := genericJitRequest
+			jitRequest.ObjectMeta.Name = "missing-role-binding"
+			err := reconciler.updateRoleBindingExpiry(ctx, jitRequest, metav1.Now())
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("failed to get RoleBinding"))
+		})
+	})
+
 	Describe("deleteOwnedObjects", func() {
 
 		It("should delete role bindings", func() {
@@ -270,5 +425,28 @@ var _ = Describe("JitRequestReconciler utils Unit Tests", Ordered, Label("unit",
 			Expect(err).To(HaveOccurred())
 			Expect(err.Error()).To(ContainSubstring("not found"))
 		})
+
+		It("should delete cluster role bindings", func() {
+			// Create cluster role binding
+			jitRequest := genericJitRequest
+			jitRequest.ObjectMeta.UID = "deleteOwnedClusterObjects"
+			jitRequest.ObjectMeta.Name = "deleteOwnedClusterObjects"
+			jitRequest.Spec.ClusterScoped = true
+			jitRequest.Spec.Namespaces = nil
+			jitRequest.Spec.ClusterRole = testUtils.ValidClusterScopedRole
+			err := reconciler.createRoleBinding(ctx, jitRequest)
+			Expect(err).NotTo(HaveOccurred())
+
+			// deleteOwnedObjects
+			err = reconciler.deleteOwnedObjects(ctx, jitRequest)
+			Expect(err).NotTo(HaveOccurred())
+
+			By("checking cluster role binding is removed")
+			crbName := fmt.Sprintf("%s-jit", jitRequest.Name)
+			crb := &rbacv1.ClusterRoleBinding{}
+			err = reconciler.Get(ctx, types.NamespacedName{Name: crbName}, crb)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("not found"))
+		})
 	})
 })
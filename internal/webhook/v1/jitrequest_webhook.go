@@ -18,10 +18,12 @@ package v1
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"strings"
 	"time"
 
+	"k8s.io/apimachinery/pkg/api/equality"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/util/validation/field"
 	ctrl "sigs.k8s.io/controller-runtime"
@@ -31,6 +33,7 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
 
 	justintimev1 "jira-jit-rbac-operator/api/v1"
+	"jira-jit-rbac-operator/pkg/resilience"
 	"jira-jit-rbac-operator/pkg/utils"
 
 	jira "github.com/ctreminiom/go-atlassian/v2/jira/v2"
@@ -41,11 +44,13 @@ import (
 var jitRequestLog = logf.Log.WithName("jitrequest-resource")
 var globalClient client.Client
 var globalJiraClient *jira.Client
+var globalJiraFlavour utils.JiraFlavour
 
 // SetupJitRequestWebhookWithManager registers the webhook for JitRequest in the manager.
-func SetupJitRequestWebhookWithManager(mgr ctrl.Manager, jiraClient *jira.Client) error {
+func SetupJitRequestWebhookWithManager(mgr ctrl.Manager, jiraClient *jira.Client, jiraFlavour utils.JiraFlavour) error {
 	globalClient = mgr.GetClient()
 	globalJiraClient = jiraClient
+	globalJiraFlavour = jiraFlavour
 	return ctrl.NewWebhookManagedBy(mgr).For(&justintimev1.JitRequest{}).
 		WithValidator(&JitRequestCustomValidator{}).
 		Complete()
@@ -74,54 +79,82 @@ func validateJitRequestSpec(ctx context.Context, jitRequest *justintimev1.JitReq
 		return nil, err
 	}
 
+	// check namespaces are only set for namespaced requests
+	if jitRequest.Spec.ClusterScoped && len(jitRequest.Spec.Namespaces) > 0 {
+		msg := "namespaces must not be set for a cluster scoped JitRequest"
+		return field.Invalid(field.NewPath("spec").Child("namespaces"), jitRequest.Spec.Namespaces, msg), nil
+	}
+	if !jitRequest.Spec.ClusterScoped && len(jitRequest.Spec.Namespaces) == 0 {
+		msg := "namespaces are required unless clusterScoped is true"
+		return field.Required(field.NewPath("spec").Child("namespaces"), msg), nil
+	}
+
 	// check cluster role is allowed
-	allowedClusterRoles := operatorConfig.AllowedClusterRoles
+	allowedClusterRoles := utils.AllowedClusterRoles(operatorConfig, jitRequest)
 	allowedClusterRolesString := strings.Join(allowedClusterRoles, ", ")
 	msg := fmt.Sprintf("clusterRole must be one of '%s'", allowedClusterRolesString)
 	if !utils.Contains(allowedClusterRoles, jitRequest.Spec.ClusterRole) {
 		return field.Invalid(field.NewPath("spec").Child("clusterRole"), jitRequest.Spec.ClusterRole, msg), nil
 	}
 
-	// check startTime is after current time
-	startTime := jitRequest.Spec.StartTime.Time
-	msg = "start time must be after current time"
-	if !startTime.After(time.Now()) {
-		return field.Invalid(field.NewPath("spec").Child("startTime"), jitRequest.Spec.StartTime, msg), nil
+	// check typed subjects are valid
+	if fieldErr := utils.ValidateSubjects(jitRequest.Spec.Subjects); fieldErr != nil {
+		return fieldErr, nil
 	}
 
-	// check endTime is after startTime
-	endTime := jitRequest.Spec.EndTime.Time
-	msg = fmt.Sprintf("end time must be after startTime '%s'", startTime)
-	if !endTime.After(startTime) {
-		return field.Invalid(field.NewPath("spec").Child("endTime"), jitRequest.Spec.EndTime, msg), nil
+	// check start time, end time and duration
+	if fieldErr := utils.ValidateTimes(jitRequest, time.Now()); fieldErr != nil {
+		return fieldErr, nil
 	}
 
-	// check namespaces match regex defined in config
-	_, err = utils.ValidateNamespaceRegex(jitRequest.Spec.Namespaces)
-	if err != nil {
-		return field.Invalid(field.NewPath("spec").Child("namespaces"), jitRequest.Spec.Namespaces, err.Error()), nil
+	if !jitRequest.Spec.ClusterScoped {
+		// check namespaces match regex defined in config
+		_, err = utils.ValidateNamespaceRegex(jitRequest.Spec.Namespaces)
+		if err != nil {
+			return field.Invalid(field.NewPath("spec").Child("namespaces"), jitRequest.Spec.Namespaces, err.Error()), nil
+		}
+
+		// check namespace labels match namespace(s)
+		_, err = utils.ValidateNamespaceLabels(ctx, jitRequest, globalClient)
+		if err != nil {
+			return field.Invalid(field.NewPath("spec").Child("namespaces"), jitRequest.Spec.Namespaces, err.Error()), nil
+		}
 	}
 
-	// check namespace labels match namespace(s)
-	_, err = utils.ValidateNamespaceLabels(ctx, jitRequest, globalClient)
+	// check the request is within the policy for its cluster role
+	fieldErr, err := utils.ValidateRolePolicy(ctx, jitRequest, operatorConfig, globalClient, time.Now())
 	if err != nil {
-		return field.Invalid(field.NewPath("spec").Child("namespaces"), jitRequest.Spec.Namespaces, err.Error()), nil
+		return nil, err
+	}
+	if fieldErr != nil {
+		return fieldErr, nil
 	}
 
 	// check customFields from config match jiraFields in JitRequest
 	customFieldsConfig := operatorConfig.CustomFields
-	for fieldName := range customFieldsConfig {
-		_, exists := jitRequest.Spec.JiraFields[fieldName]
+	for fieldName, settings := range customFieldsConfig {
+		value, exists := jitRequest.Spec.JiraFields[fieldName]
 		if !exists {
 			// Missing field, reject
 			errMsg := fmt.Sprintf("missing custom field: %s", fieldName)
 			return field.Invalid(field.NewPath("spec").Child("jiraFields"), jitRequest.Spec.JiraFields, errMsg), nil
 		}
+		// check the value is valid for the field type
+		if _, err := utils.JiraFieldValue(globalJiraFlavour, settings.Type, value); err != nil {
+			return field.Invalid(field.NewPath("spec").Child("jiraFields").Child(fieldName), value, err.Error()), nil
+		}
 	}
 
 	// get reporter name from jira
 	reporter := jitRequest.Spec.Reporter
-	reporterName, err := utils.GetNameByEmail(reporter, globalJiraClient)
+	reporterName, err := utils.GetNameByEmail(ctx, reporter, globalJiraClient, globalJiraFlavour)
+	if resilience.IsUnavailable(err) {
+		return nil, err
+	}
+	if errors.Is(err, utils.ErrAmbiguousUser) {
+		errMsg := fmt.Sprintf("multiple Jira users match the reporter email: %s", reporter)
+		return field.Invalid(field.NewPath("spec").Child("userEmail"), reporter, errMsg), nil
+	}
 	if err != nil {
 		// reporter does not exist, reject
 		errMsg := fmt.Sprintf("failed to find reporter user: %s", reporter)
@@ -129,10 +162,29 @@ func validateJitRequestSpec(ctx context.Context, jitRequest *justintimev1.JitReq
 	}
 
 	// validate jira users exist and do not match reporter
-	for fieldName := range customFieldsConfig {
-		if customFieldsConfig[fieldName].Type == "user" {
-			jiraUser := jitRequest.Spec.JiraFields[fieldName]
-			jiraUserName, err := utils.GetNameByEmail(jiraUser, globalJiraClient)
+	for fieldName, settings := range customFieldsConfig {
+		var jiraUsers []string
+		switch settings.Type {
+		case justintimev1.FieldTypeUser:
+			jiraUsers = []string{jitRequest.Spec.JiraFields[fieldName]}
+		case justintimev1.FieldTypeMultiUser:
+			jiraUsers = utils.SplitFieldValues(jitRequest.Spec.JiraFields[fieldName])
+		default:
+			continue
+		}
+		if len(jiraUsers) == 0 {
+			errMsg := fmt.Sprintf("Jira user does not exist or failed to find user: %s", fieldName)
+			return field.Invalid(field.NewPath("spec").Child("jiraFields").Child(fieldName), jitRequest.Spec.JiraFields[fieldName], errMsg), nil
+		}
+		for _, jiraUser := range jiraUsers {
+			jiraUserName, err := utils.GetNameByEmail(ctx, jiraUser, globalJiraClient, globalJiraFlavour)
+			if resilience.IsUnavailable(err) {
+				return nil, err
+			}
+			if errors.Is(err, utils.ErrAmbiguousUser) {
+				errMsg := fmt.Sprintf("multiple Jira users match the email of user field: %s", fieldName)
+				return field.Invalid(field.NewPath("spec").Child("jiraFields").Child(fieldName), jiraUser, errMsg), nil
+			}
 			// check jira user exists from user fields
 			if err != nil || jiraUser == "" {
 				errMsg := fmt.Sprintf("Jira user does not exist or failed to find user: %s", fieldName)
@@ -149,6 +201,120 @@ func validateJitRequestSpec(ctx context.Context, jitRequest *justintimev1.JitReq
 	return nil, nil
 }
 
+// validateLinkedIssue validates the linked issue of a JitRequest is in an allowed project and still open
+func validateLinkedIssue(ctx context.Context, jitRequest *justintimev1.JitRequest) (*field.Error, error) {
+	// Fetch operator config
+	operatorConfig, err := utils.ReadConfigFromFile()
+	if err != nil {
+		return nil, err
+	}
+	return utils.ValidateLinkedIssue(ctx, jitRequest, operatorConfig, globalJiraClient)
+}
+
+// validateRevocation validates a revocation does not change any other fields of a JitRequest
+func validateRevocation(ctx context.Context, oldJitRequest, jitRequest *justintimev1.JitRequest) *field.Error {
+	revocationPath := field.NewPath("spec").Child("revocation")
+	revocation := jitRequest.Spec.Revocation
+
+	if revocation.RevokedBy == "" {
+		return field.Required(revocationPath.Child("revokedBy"), "revokedBy is required to revoke a JitRequest")
+	}
+	if revocation.Reason == "" {
+		return field.Required(revocationPath.Child("reason"), "reason is required to revoke a JitRequest")
+	}
+
+	// finished JitRequests are only kept for auditing
+	if oldJitRequest.Status.CompletionTime != nil {
+		return field.Forbidden(revocationPath, "a JitRequest that has already finished cannot be revoked")
+	}
+
+	// check nothing else changed alongside the revocation
+	newSpec := jitRequest.Spec.DeepCopy()
+	newSpec.Revocation = nil
+	if !equality.Semantic.DeepEqual(oldJitRequest.Spec, *newSpec) {
+		return field.Forbidden(field.NewPath("spec"), "revocation cannot be combined with other changes")
+	}
+
+	// revokedBy is recorded in the Jira ticket, it must be the user making the change
+	req, err := admission.RequestFromContext(ctx)
+	if err != nil {
+		return field.Forbidden(revocationPath.Child("revokedBy"), "the user revoking the JitRequest could not be determined")
+	}
+	if revocation.RevokedBy != req.UserInfo.Username {
+		return field.Invalid(revocationPath.Child("revokedBy"), revocation.RevokedBy,
+			fmt.Sprintf("revokedBy must be the user revoking the JitRequest: %s", req.UserInfo.Username))
+	}
+
+	jitRequestLog.Info("JitRequest revoked", "name", jitRequest.GetName(), "revokedBy", revocation.RevokedBy)
+	return nil
+}
+
+// validateExtension validates an extension request on a Succeeded JitRequest, policy limits from the config still apply
+func validateExtension(oldJitRequest, jitRequest *justintimev1.JitRequest) (*field.Error, error) {
+	extensionPath := field.NewPath("spec").Child("extension")
+	extension := jitRequest.Spec.Extension
+
+	if extension == nil {
+		return field.Forbidden(extensionPath, "an extension cannot be removed"), nil
+	}
+	if extension.Reason == "" {
+		return field.Required(extensionPath.Child("reason"), "reason is required to extend a JitRequest"), nil
+	}
+
+	// check nothing else changed alongside the extension
+	newSpec := jitRequest.Spec.DeepCopy()
+	newSpec.Extension = oldJitRequest.Spec.Extension
+	if !equality.Semantic.DeepEqual(oldJitRequest.Spec, *newSpec) {
+		return field.Forbidden(field.NewPath("spec"), "extension cannot be combined with other changes"), nil
+	}
+
+	// check access has been granted and no extension is awaiting approval
+	if oldJitRequest.Status.State != justintimev1.StateSucceeded {
+		msg := fmt.Sprintf("only a %s JitRequest can be extended", justintimev1.StateSucceeded)
+		return field.Forbidden(extensionPath, msg), nil
+	}
+	if oldJitRequest.Status.Extension != nil && oldJitRequest.Status.Extension.State == justintimev1.ExtensionPending {
+		return field.Forbidden(extensionPath, "an extension is already pending approval"), nil
+	}
+
+	// check new endTime is after current endTime
+	endTime := oldJitRequest.Status.EndTime.Time
+	msg := fmt.Sprintf("extension end time must be after current end time '%s'", endTime)
+	if !extension.EndTime.After(endTime) {
+		return field.Invalid(extensionPath.Child("endTime"), extension.EndTime, msg), nil
+	}
+	if !time.Now().Before(endTime) {
+		return field.Forbidden(extensionPath, "access has already expired"), nil
+	}
+
+	// Fetch operator config
+	operatorConfig, err := utils.ReadConfigFromFile()
+	if err != nil {
+		return nil, err
+	}
+
+	// check cluster role is still allowed
+	allowedClusterRoles := utils.AllowedClusterRoles(operatorConfig, jitRequest)
+	if !utils.Contains(allowedClusterRoles, jitRequest.Spec.ClusterRole) {
+		msg := fmt.Sprintf("clusterRole must be one of '%s'", strings.Join(allowedClusterRoles, ", "))
+		return field.Invalid(field.NewPath("spec").Child("clusterRole"), jitRequest.Spec.ClusterRole, msg), nil
+	}
+
+	// check total access is still within the policy for the cluster role
+	if fieldErr := utils.ValidateExtensionPolicy(operatorConfig, jitRequest); fieldErr != nil {
+		return fieldErr, nil
+	}
+
+	// check namespaces still match regex defined in config
+	if !jitRequest.Spec.ClusterScoped {
+		if _, err := utils.ValidateNamespaceRegex(jitRequest.Spec.Namespaces); err != nil {
+			return field.Invalid(field.NewPath("spec").Child("namespaces"), jitRequest.Spec.Namespaces, err.Error()), nil
+		}
+	}
+
+	return nil, nil
+}
+
 // ValidateCreate implements webhook.CustomValidator so a webhook will be registered for the type JitRequest.
 func (v *JitRequestCustomValidator) ValidateCreate(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
 	jitRequest, ok := obj.(*justintimev1.JitRequest)
@@ -157,6 +323,13 @@ func (v *JitRequestCustomValidator) ValidateCreate(ctx context.Context, obj runt
 	}
 	jitRequestLog.Info("Validation for JitRequest upon creation", "name", jitRequest.GetName())
 
+	if jitRequest.Spec.Revocation != nil {
+		return nil, field.Forbidden(field.NewPath("spec").Child("revocation"), "a JitRequest cannot be revoked on creation")
+	}
+	if jitRequest.Spec.Extension != nil {
+		return nil, field.Forbidden(field.NewPath("spec").Child("extension"), "a JitRequest cannot be extended on creation")
+	}
+
 	fieldErr, err := validateJitRequestSpec(ctx, jitRequest)
 	if err != nil {
 		return nil, err
@@ -165,6 +338,14 @@ func (v *JitRequestCustomValidator) ValidateCreate(ctx context.Context, obj runt
 		return nil, fieldErr
 	}
 
+	fieldErr, err = validateLinkedIssue(ctx, jitRequest)
+	if err != nil {
+		return nil, err
+	}
+	if fieldErr != nil {
+		return nil, fieldErr
+	}
+
 	return nil, nil
 }
 
@@ -174,7 +355,40 @@ func (v *JitRequestCustomValidator) ValidateUpdate(ctx context.Context, oldObj,
 	if !ok {
 		return nil, fmt.Errorf("expected a JitRequest object for the newObj but got %T", newObj)
 	}
+	oldJitRequest, ok := oldObj.(*justintimev1.JitRequest)
+	if !ok {
+		return nil, fmt.Errorf("expected a JitRequest object for the oldObj but got %T", oldObj)
+	}
 	jitRequestLog.Info("Validation for JitRequest upon update", "name", jitRequest.GetName())
+
+	// revoked JitRequests are kept for auditing and cannot be modified
+	if oldJitRequest.Spec.Revocation != nil {
+		if !equality.Semantic.DeepEqual(oldJitRequest.Spec, jitRequest.Spec) {
+			return nil, field.Forbidden(field.NewPath("spec"), "a revoked JitRequest cannot be modified")
+		}
+		return nil, nil
+	}
+
+	// revoking only needs the revocation validated, the request may already be active
+	if jitRequest.Spec.Revocation != nil {
+		if fieldErr := validateRevocation(ctx, oldJitRequest, jitRequest); fieldErr != nil {
+			return nil, fieldErr
+		}
+		return nil, nil
+	}
+
+	// extending only needs the extension validated, the request is already active
+	if !equality.Semantic.DeepEqual(oldJitRequest.Spec.Extension, jitRequest.Spec.Extension) {
+		fieldErr, err := validateExtension(oldJitRequest, jitRequest)
+		if err != nil {
+			return nil, err
+		}
+		if fieldErr != nil {
+			return nil, fieldErr
+		}
+		return nil, nil
+	}
+
 	fieldErr, err := validateJitRequestSpec(ctx, jitRequest)
 	if err != nil {
 		return nil, err
@@ -183,6 +397,17 @@ func (v *JitRequestCustomValidator) ValidateUpdate(ctx context.Context, oldObj,
 		return nil, fieldErr
 	}
 
+	// the linked issue may have been closed since creation, only a new one is validated
+	if oldJitRequest.Spec.LinkedJiraTicket != jitRequest.Spec.LinkedJiraTicket {
+		fieldErr, err = validateLinkedIssue(ctx, jitRequest)
+		if err != nil {
+			return nil, err
+		}
+		if fieldErr != nil {
+			return nil, fieldErr
+		}
+	}
+
 	return nil, nil
 }
 
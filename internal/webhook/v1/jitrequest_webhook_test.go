@@ -17,6 +17,7 @@ limitations under the License.
 package v1
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"os"
@@ -25,7 +26,10 @@ import (
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+	admissionv1 "k8s.io/api/admission/v1"
+	authenticationv1 "k8s.io/api/authentication/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
 
 	justintimev1 "jira-jit-rbac-operator/api/v1"
 	"jira-jit-rbac-operator/test/utils"
@@ -37,10 +41,11 @@ var (
 )
 
 const (
-	TestJitConfig      = "jira-jit-rbac-operator-default"
-	ValidClusterRole   = "edit"
-	InvalidClusterRole = "admin"
-	InvalidNamespace   = "invalid-namespace"
+	TestJitConfig          = "jira-jit-rbac-operator-default"
+	ValidClusterRole       = "edit"
+	ValidClusterScopedRole = "view"
+	InvalidClusterRole     = "admin"
+	InvalidNamespace       = "invalid-namespace"
 )
 
 // Function to initialise os vars
@@ -199,6 +204,30 @@ var _ = Describe("JitRequest Webhook", Ordered, func() {
 				"should fail if reporter and approver are the same")
 		})
 
+		It("Should deny creation if multiple Jira users have the reporter email", func() {
+			By("simulating a reporter email shared by two Jira users")
+			obj.Spec.Reporter = utils.AmbiguousUser
+			Expect(validator.ValidateCreate(ctx, obj)).Error().To(
+				MatchError(ContainSubstring("multiple Jira users match the reporter email: spartan@unsc.com")),
+				"should fail if the reporter is ambiguous")
+		})
+
+		It("Should deny creation if a Jira user field matches multiple users", func() {
+			By("simulating a user field email shared by two Jira users")
+			obj.Spec.JiraFields["Approver"] = utils.AmbiguousUser
+			Expect(validator.ValidateCreate(ctx, obj)).Error().To(
+				MatchError(ContainSubstring("multiple Jira users match the email of user field: Approver")),
+				"should fail if a Jira user field is ambiguous")
+		})
+
+		It("Should deny creation if a Jira user field only partially matches a user", func() {
+			By("simulating a partial email in a user field")
+			obj.Spec.JiraFields["Approver"] = "keyes@unsc.com"
+			Expect(validator.ValidateCreate(ctx, obj)).Error().To(
+				MatchError(ContainSubstring("Jira user does not exist or failed to find user: Approver")),
+				"should fail if a Jira user field is not an exact email")
+		})
+
 		It("Should deny creation if a Jira user field does not exist", func() {
 			By("simulating a non-existent Jira user in a user field")
 			obj.Spec.JiraFields["Approver"] = "nonexistent@unsc.com"
@@ -207,6 +236,190 @@ var _ = Describe("JitRequest Webhook", Ordered, func() {
 				"should fail if a Jira user field does not exist")
 		})
 
+		It("Should admit creation if the linked issue is open", func() {
+			By("simulating a request linked to an open incident")
+			obj.Spec.LinkedJiraTicket = utils.LinkedIssue
+			Expect(validator.ValidateCreate(ctx, obj)).To(BeNil())
+		})
+
+		It("Should deny creation if the linked issue is closed", func() {
+			By("simulating a request linked to a resolved incident")
+			obj.Spec.LinkedJiraTicket = utils.ClosedLinkedIssue
+			Expect(validator.ValidateCreate(ctx, obj)).Error().To(
+				MatchError(ContainSubstring("linked issue must be open, it is in status 'Resolved'")),
+				"linkedJiraTicket to fail if the issue is closed")
+		})
+
+		It("Should deny creation if the linked issue is not in an allowed project", func() {
+			By("simulating a request linked to an issue of another project")
+			obj.Spec.LinkedJiraTicket = utils.OtherProjectLinkedIssue
+			Expect(validator.ValidateCreate(ctx, obj)).Error().To(
+				MatchError(ContainSubstring("linked issue must be in one of the projects 'IAM, INC'")),
+				"linkedJiraTicket to fail if the issue is in another project")
+		})
+
+		It("Should deny creation if revocation is set", func() {
+			By("simulating a revoked request on creation")
+			obj.Spec.Revocation = &justintimev1.RevocationSpec{
+				RevokedBy: "cpt-keyes@unsc.com",
+				Reason:    "incident resolved",
+			}
+			Expect(validator.ValidateCreate(ctx, obj)).Error().To(
+				MatchError(ContainSubstring("a JitRequest cannot be revoked on creation")),
+				"revocation to fail on creation")
+		})
+
+		It("Should admit update if only revocation is set on an active request", func() {
+			By("simulating revoking a request past its start time")
+			obj.Spec.StartTime = metav1.NewTime(metav1.Now().Add(-10 * time.Second))
+			oldObj := obj.DeepCopy()
+			obj.Spec.Revocation = &justintimev1.RevocationSpec{
+				RevokedBy: "cpt-keyes@unsc.com",
+				Reason:    "incident resolved",
+			}
+			Expect(validator.ValidateUpdate(revokerContext("cpt-keyes@unsc.com"), oldObj, obj)).To(BeNil())
+		})
+
+		It("Should deny update if revokedBy is not the user revoking the request", func() {
+			By("simulating a revocation on behalf of another user")
+			obj.Spec.StartTime = metav1.NewTime(metav1.Now().Add(-10 * time.Second))
+			oldObj := obj.DeepCopy()
+			obj.Spec.Revocation = &justintimev1.RevocationSpec{
+				RevokedBy: "cpt-keyes@unsc.com",
+				Reason:    "incident resolved",
+			}
+			Expect(validator.ValidateUpdate(revokerContext("sgt-johnson@unsc.com"), oldObj, obj)).Error().To(
+				MatchError(ContainSubstring("revokedBy must be the user revoking the JitRequest: sgt-johnson@unsc.com")),
+				"revocation to fail for another user")
+		})
+
+		It("Should deny update if revocation reason is missing", func() {
+			By("simulating a revocation without a reason")
+			oldObj := obj.DeepCopy()
+			obj.Spec.Revocation = &justintimev1.RevocationSpec{
+				RevokedBy: "cpt-keyes@unsc.com",
+			}
+			Expect(validator.ValidateUpdate(ctx, oldObj, obj)).Error().To(
+				MatchError(ContainSubstring("reason is required to revoke a JitRequest")),
+				"revocation to fail without a reason")
+		})
+
+		It("Should deny update if revocation is combined with other changes", func() {
+			By("simulating a revocation with a cluster role change")
+			oldObj := obj.DeepCopy()
+			obj.Spec.ClusterRole = InvalidClusterRole
+			obj.Spec.Revocation = &justintimev1.RevocationSpec{
+				RevokedBy: "cpt-keyes@unsc.com",
+				Reason:    "incident resolved",
+			}
+			Expect(validator.ValidateUpdate(ctx, oldObj, obj)).Error().To(
+				MatchError(ContainSubstring("revocation cannot be combined with other changes")),
+				"revocation to fail if other fields change")
+		})
+
+		It("Should deny update if revocation is set on a finished request", func() {
+			By("simulating revoking an expired request")
+			completionTime := metav1.Now()
+			obj.Status.State = "Expired"
+			obj.Status.CompletionTime = &completionTime
+			oldObj := obj.DeepCopy()
+			obj.Spec.Revocation = &justintimev1.RevocationSpec{
+				RevokedBy: "cpt-keyes@unsc.com",
+				Reason:    "incident resolved",
+			}
+			Expect(validator.ValidateUpdate(ctx, oldObj, obj)).Error().To(
+				MatchError(ContainSubstring("a JitRequest that has already finished cannot be revoked")),
+				"revocation to fail on a finished request")
+		})
+
+		It("Should deny update of a revoked request", func() {
+			By("simulating a change to a revoked request")
+			obj.Spec.Revocation = &justintimev1.RevocationSpec{
+				RevokedBy: "cpt-keyes@unsc.com",
+				Reason:    "incident resolved",
+			}
+			oldObj := obj.DeepCopy()
+			obj.Spec.Revocation = nil
+			Expect(validator.ValidateUpdate(ctx, oldObj, obj)).Error().To(
+				MatchError(ContainSubstring("a revoked JitRequest cannot be modified")),
+				"revoked request to be immutable")
+		})
+
+		It("Should admit update if only extension is set on a Succeeded request", func() {
+			By("simulating extending an active request")
+			obj.Spec.StartTime = metav1.NewTime(metav1.Now().Add(-10 * time.Second))
+			obj.Status.State = "Succeeded"
+			obj.Status.EndTime = obj.Spec.EndTime
+			oldObj := obj.DeepCopy()
+			obj.Spec.Extension = &justintimev1.ExtensionSpec{
+				EndTime: metav1.NewTime(obj.Spec.EndTime.Add(time.Hour)),
+				Reason:  "incident ongoing",
+			}
+			Expect(validator.ValidateUpdate(ctx, oldObj, obj)).To(BeNil())
+		})
+
+		It("Should deny update if extension is set on a request that has not succeeded", func() {
+			By("simulating extending a pre-approved request")
+			obj.Status.State = "Pre-Approved"
+			obj.Status.EndTime = obj.Spec.EndTime
+			oldObj := obj.DeepCopy()
+			obj.Spec.Extension = &justintimev1.ExtensionSpec{
+				EndTime: metav1.NewTime(obj.Spec.EndTime.Add(time.Hour)),
+				Reason:  "incident ongoing",
+			}
+			Expect(validator.ValidateUpdate(ctx, oldObj, obj)).Error().To(
+				MatchError(ContainSubstring("only a Succeeded JitRequest can be extended")),
+				"extension to fail if access has not been granted")
+		})
+
+		It("Should deny update if an extension is already pending approval", func() {
+			By("simulating a second extension while one is pending")
+			obj.Status.State = "Succeeded"
+			obj.Status.EndTime = obj.Spec.EndTime
+			obj.Spec.Extension = &justintimev1.ExtensionSpec{
+				EndTime: metav1.NewTime(obj.Spec.EndTime.Add(time.Hour)),
+				Reason:  "incident ongoing",
+			}
+			obj.Status.Extension = &justintimev1.ExtensionStatus{
+				State:   justintimev1.ExtensionPending,
+				EndTime: obj.Spec.Extension.EndTime,
+			}
+			oldObj := obj.DeepCopy()
+			obj.Spec.Extension.EndTime = metav1.NewTime(obj.Spec.EndTime.Add(2 * time.Hour))
+			Expect(validator.ValidateUpdate(ctx, oldObj, obj)).Error().To(
+				MatchError(ContainSubstring("an extension is already pending approval")),
+				"extension to fail if one is already pending")
+		})
+
+		It("Should deny update if extension end time is before current end time", func() {
+			By("simulating an extension that shortens access")
+			obj.Status.State = "Succeeded"
+			obj.Status.EndTime = obj.Spec.EndTime
+			oldObj := obj.DeepCopy()
+			obj.Spec.Extension = &justintimev1.ExtensionSpec{
+				EndTime: metav1.NewTime(obj.Spec.EndTime.Add(-5 * time.Second)),
+				Reason:  "incident ongoing",
+			}
+			Expect(validator.ValidateUpdate(ctx, oldObj, obj)).Error().To(
+				MatchError(ContainSubstring("extension end time must be after current end time")),
+				"extension to fail if it does not move the end time")
+		})
+
+		It("Should deny update if extension is combined with other changes", func() {
+			By("simulating an extension with a cluster role change")
+			obj.Status.State = "Succeeded"
+			obj.Status.EndTime = obj.Spec.EndTime
+			oldObj := obj.DeepCopy()
+			obj.Spec.ClusterRole = InvalidClusterRole
+			obj.Spec.Extension = &justintimev1.ExtensionSpec{
+				EndTime: metav1.NewTime(obj.Spec.EndTime.Add(time.Hour)),
+				Reason:  "incident ongoing",
+			}
+			Expect(validator.ValidateUpdate(ctx, oldObj, obj)).Error().To(
+				MatchError(ContainSubstring("extension cannot be combined with other changes")),
+				"extension to fail if other fields change")
+		})
+
 		It("Should deny update if cluster role is invalid", func() {
 			By("simulating an invalid cluster role update")
 			oldObj := obj
@@ -224,6 +437,109 @@ var _ = Describe("JitRequest Webhook", Ordered, func() {
 				"clusterRole to fail if not allowed in config")
 		})
 
+		It("Should deny creation if namespaces are set for a cluster scoped request", func() {
+			By("simulating a cluster scoped request with namespaces")
+			obj.Spec.ClusterScoped = true
+			obj.Spec.ClusterRole = ValidClusterScopedRole
+			Expect(validator.ValidateCreate(ctx, obj)).Error().To(
+				MatchError(ContainSubstring("namespaces must not be set for a cluster scoped JitRequest")),
+				"namespaces to fail if set on a cluster scoped request")
+		})
+
+		It("Should deny creation if cluster role is not allowed cluster-wide", func() {
+			By("simulating a cluster scoped request with a namespaced only cluster role")
+			obj.Spec.ClusterScoped = true
+			obj.Spec.Namespaces = nil
+			Expect(validator.ValidateCreate(ctx, obj)).Error().To(
+				MatchError(ContainSubstring("clusterRole must be one of 'view'")),
+				"clusterRole to fail if not allowed cluster-wide in config")
+		})
+
+		It("Should admit creation of a cluster scoped request with an allowed cluster role", func() {
+			By("simulating a valid cluster scoped request")
+			obj.Spec.ClusterScoped = true
+			obj.Spec.Namespaces = nil
+			obj.Spec.ClusterRole = ValidClusterScopedRole
+			Expect(validator.ValidateCreate(ctx, obj)).To(BeNil())
+		})
+
+		It("Should deny creation if the duration exceeds the role policy", func() {
+			By("simulating a cluster scoped request longer than the max duration for its cluster role")
+			obj.Spec.ClusterScoped = true
+			obj.Spec.Namespaces = nil
+			obj.Spec.ClusterRole = ValidClusterScopedRole
+			obj.Spec.EndTime = metav1.NewTime(obj.Spec.StartTime.Add(2 * time.Hour))
+			Expect(validator.ValidateCreate(ctx, obj)).Error().To(
+				MatchError(ContainSubstring("duration must not exceed '1h0m0s' for clusterRole 'view'")),
+				"endTime to fail if exceeding the role policy max duration")
+		})
+
+		It("Should deny creation if a jira field required by the role policy is empty", func() {
+			By("simulating a cluster scoped request without a justification")
+			obj.Spec.ClusterScoped = true
+			obj.Spec.Namespaces = nil
+			obj.Spec.ClusterRole = ValidClusterScopedRole
+			obj.Spec.JiraFields["Justification"] = ""
+			Expect(validator.ValidateCreate(ctx, obj)).Error().To(
+				MatchError(ContainSubstring("jira field 'Justification' is required for clusterRole 'view'")),
+				"jiraFields to fail if missing a field required by the role policy")
+		})
+
+		It("Should deny update if extension exceeds the role policy max duration", func() {
+			By("simulating extending a cluster scoped request past the max duration")
+			obj.Spec.ClusterScoped = true
+			obj.Spec.Namespaces = nil
+			obj.Spec.ClusterRole = ValidClusterScopedRole
+			obj.Status.State = "Succeeded"
+			obj.Status.StartTime = metav1.NewTime(metav1.Now().Add(-10 * time.Second))
+			obj.Status.EndTime = obj.Spec.EndTime
+			oldObj := obj.DeepCopy()
+			obj.Spec.Extension = &justintimev1.ExtensionSpec{
+				EndTime: metav1.NewTime(obj.Spec.EndTime.Add(2 * time.Hour)),
+				Reason:  "incident ongoing",
+			}
+			Expect(validator.ValidateUpdate(ctx, oldObj, obj)).Error().To(
+				MatchError(ContainSubstring("total duration must not exceed '1h0m0s' for clusterRole 'view'")),
+				"extension to fail if exceeding the role policy max duration")
+		})
+
+		It("Should deny creation if namespaces are missing for a namespaced request", func() {
+			By("simulating a namespaced request without namespaces")
+			obj.Spec.Namespaces = nil
+			Expect(validator.ValidateCreate(ctx, obj)).Error().To(
+				MatchError(ContainSubstring("namespaces are required unless clusterScoped is true")),
+				"namespaces to fail if missing on a namespaced request")
+		})
+
+		It("Should admit creation with Group and ServiceAccount subjects", func() {
+			By("simulating a request with typed subjects")
+			obj.Spec.Subjects = []justintimev1.SubjectSpec{
+				{Kind: "Group", Name: "on-call"},
+				{Kind: "ServiceAccount", Name: "deployer", Namespace: TestNamespace},
+			}
+			Expect(validator.ValidateCreate(ctx, obj)).To(BeNil())
+		})
+
+		It("Should deny creation if a ServiceAccount subject has no namespace", func() {
+			By("simulating a ServiceAccount subject without a namespace")
+			obj.Spec.Subjects = []justintimev1.SubjectSpec{
+				{Kind: "ServiceAccount", Name: "deployer"},
+			}
+			Expect(validator.ValidateCreate(ctx, obj)).Error().To(
+				MatchError(ContainSubstring("namespace is required for ServiceAccount subjects")),
+				"subjects to fail if a ServiceAccount has no namespace")
+		})
+
+		It("Should deny creation if a subject kind is not supported", func() {
+			By("simulating an unsupported subject kind")
+			obj.Spec.Subjects = []justintimev1.SubjectSpec{
+				{Kind: "Robot", Name: "343-guilty-spark"},
+			}
+			Expect(validator.ValidateCreate(ctx, obj)).Error().To(
+				MatchError(ContainSubstring("Unsupported value")),
+				"subjects to fail if the kind is not supported")
+		})
+
 		It("Should deny creation if startTime is invalid", func() {
 			By("simulating an invalid startTime")
 			obj.Spec.StartTime = metav1.NewTime(metav1.Now().Add(-10 * time.Second))
@@ -240,6 +556,46 @@ var _ = Describe("JitRequest Webhook", Ordered, func() {
 				"endTime to fail if not after startTime")
 		})
 
+		It("Should admit creation with a duration instead of an endTime", func() {
+			By("simulating a duration based request")
+			obj.Spec.EndTime = metav1.Time{}
+			obj.Spec.Duration = &metav1.Duration{Duration: time.Hour}
+			Expect(validator.ValidateCreate(ctx, obj)).To(BeNil())
+		})
+
+		It("Should deny creation if both endTime and duration are set", func() {
+			By("simulating a request with endTime and duration")
+			obj.Spec.Duration = &metav1.Duration{Duration: time.Hour}
+			Expect(validator.ValidateCreate(ctx, obj)).Error().To(
+				MatchError(ContainSubstring("duration and endTime are mutually exclusive")),
+				"duration to fail if endTime is also set")
+		})
+
+		It("Should deny creation if neither endTime nor duration are set", func() {
+			By("simulating a request without an end")
+			obj.Spec.EndTime = metav1.Time{}
+			Expect(validator.ValidateCreate(ctx, obj)).Error().To(
+				MatchError(ContainSubstring("one of endTime or duration is required")),
+				"request to fail without endTime or duration")
+		})
+
+		It("Should admit creation of a startOnApproval request without a startTime", func() {
+			By("simulating a start on approval request")
+			obj.Spec.StartTime = metav1.Time{}
+			obj.Spec.EndTime = metav1.Time{}
+			obj.Spec.StartOnApproval = true
+			obj.Spec.Duration = &metav1.Duration{Duration: time.Hour}
+			Expect(validator.ValidateCreate(ctx, obj)).To(BeNil())
+		})
+
+		It("Should deny creation if startTime is set with startOnApproval", func() {
+			By("simulating a start on approval request with a startTime")
+			obj.Spec.StartOnApproval = true
+			Expect(validator.ValidateCreate(ctx, obj)).Error().To(
+				MatchError(ContainSubstring("startTime must not be set with startOnApproval")),
+				"startTime to fail with startOnApproval")
+		})
+
 		It("Should deny creation if any namespace is invalid if using NamespaceAllowedRegex in config", func() {
 			By("simulating an invalid namespace")
 			obj.Spec.Namespaces = []string{
@@ -277,3 +633,12 @@ var _ = Describe("JitRequest Webhook", Ordered, func() {
 	})
 
 })
+
+// revokerContext returns a context carrying an admission request made by username
+func revokerContext(username string) context.Context {
+	return admission.NewContextWithRequest(ctx, admission.Request{
+		AdmissionRequest: admissionv1.AdmissionRequest{
+			UserInfo: authenticationv1.UserInfo{Username: username},
+		},
+	})
+}
@@ -45,6 +45,7 @@ import (
 
 	justintimev1 "jira-jit-rbac-operator/api/v1"
 	"jira-jit-rbac-operator/internal/config"
+	pkgutils "jira-jit-rbac-operator/pkg/utils"
 	"jira-jit-rbac-operator/test/utils"
 	// +kubebuilder:scaffold:imports
 )
@@ -133,7 +134,7 @@ var _ = BeforeSuite(func() {
 	})
 	Expect(err).NotTo(HaveOccurred())
 
-	err = SetupJitRequestWebhookWithManager(mgr, jiraClient)
+	err = SetupJitRequestWebhookWithManager(mgr, jiraClient, pkgutils.JiraServer)
 	Expect(err).NotTo(HaveOccurred())
 
 	// +kubebuilder:scaffold:webhook
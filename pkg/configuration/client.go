@@ -21,6 +21,7 @@ import (
 
 	"github.com/pkg/errors"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
@@ -57,9 +58,9 @@ func NewJitRbacOperatorConfiguration(ctx context.Context, client client.Client,
 							Cluster:     "minikube",
 						},
 						RequiredFields: &justintimev1.RequiredFieldsSpec{
-							StartTime:   justintimev1.CustomFieldSettings{Type: "date", JiraCustomField: "customfield_10118"},
-							EndTime:     justintimev1.CustomFieldSettings{Type: "date", JiraCustomField: "customfield_10119"},
-							ClusterRole: justintimev1.CustomFieldSettings{Type: "date", JiraCustomField: "customfield_10117"},
+							StartTime:   justintimev1.CustomFieldSettings{Type: "datetime", JiraCustomField: "customfield_10118"},
+							EndTime:     justintimev1.CustomFieldSettings{Type: "datetime", JiraCustomField: "customfield_10119"},
+							ClusterRole: justintimev1.CustomFieldSettings{Type: "text", JiraCustomField: "customfield_10117"},
 						},
 						CustomFields: map[string]justintimev1.CustomFieldSettings{
 							"Approver":      {Type: "user", JiraCustomField: "customfield_10114"},
@@ -81,6 +82,38 @@ func (c *jitRbacOperatorConfiguration) SelfApprovalEnabled() bool {
 	return c.retrievalFn().Spec.SelfApprovalEnabled
 }
 
+func (c *jitRbacOperatorConfiguration) RetentionPeriod() *metav1.Duration {
+	return c.retrievalFn().Spec.RetentionPeriod
+}
+
+func (c *jitRbacOperatorConfiguration) ApprovalPollInterval() *metav1.Duration {
+	return c.retrievalFn().Spec.ApprovalPollInterval
+}
+
+func (c *jitRbacOperatorConfiguration) ApprovalGracePeriod() *metav1.Duration {
+	return c.retrievalFn().Spec.ApprovalGracePeriod
+}
+
+func (c *jitRbacOperatorConfiguration) StartOnApprovalTimeout() *metav1.Duration {
+	return c.retrievalFn().Spec.StartOnApprovalTimeout
+}
+
+func (c *jitRbacOperatorConfiguration) ApprovalMode() string {
+	return c.retrievalFn().Spec.ApprovalMode
+}
+
+func (c *jitRbacOperatorConfiguration) RolePolicies() map[string]justintimev1.RolePolicySpec {
+	return c.retrievalFn().Spec.RolePolicies
+}
+
+func (c *jitRbacOperatorConfiguration) Timezone() string {
+	return c.retrievalFn().Spec.Timezone
+}
+
+func (c *jitRbacOperatorConfiguration) Templates() *justintimev1.JiraTemplatesSpec {
+	return c.retrievalFn().Spec.Templates
+}
+
 func (c *jitRbacOperatorConfiguration) NamespaceAllowedRegex() string {
 	return c.retrievalFn().Spec.NamespaceAllowedRegex
 }
@@ -101,10 +134,22 @@ func (c *jitRbacOperatorConfiguration) AllowedClusterRoles() []string {
 	return c.retrievalFn().Spec.AllowedClusterRoles
 }
 
+func (c *jitRbacOperatorConfiguration) AllowedClusterScopedRoles() []string {
+	return c.retrievalFn().Spec.AllowedClusterScopedRoles
+}
+
 func (c *jitRbacOperatorConfiguration) JiraWorkflowApproveStatus() string {
 	return c.retrievalFn().Spec.JiraWorkflowApproveStatus
 }
 
+func (c *jitRbacOperatorConfiguration) JiraWorkflowRejectedStatus() string {
+	return c.retrievalFn().Spec.JiraWorkflowRejectedStatus
+}
+
+func (c *jitRbacOperatorConfiguration) JiraWorkflowRejectedStatuses() []string {
+	return c.retrievalFn().Spec.JiraWorkflowRejectedStatuses
+}
+
 func (c *jitRbacOperatorConfiguration) RejectedTransitionID() string {
 	return c.retrievalFn().Spec.RejectedTransitionID
 }
@@ -117,10 +162,34 @@ func (c *jitRbacOperatorConfiguration) JiraIssueType() string {
 	return c.retrievalFn().Spec.JiraIssueType
 }
 
+func (c *jitRbacOperatorConfiguration) LinkedIssueProjects() []string {
+	return c.retrievalFn().Spec.LinkedIssueProjects
+}
+
+func (c *jitRbacOperatorConfiguration) IssueLinkType() string {
+	return c.retrievalFn().Spec.IssueLinkType
+}
+
+func (c *jitRbacOperatorConfiguration) ServiceDesk() *justintimev1.ServiceDeskSpec {
+	return c.retrievalFn().Spec.ServiceDesk
+}
+
 func (c *jitRbacOperatorConfiguration) CompletedTransitionID() string {
 	return c.retrievalFn().Spec.CompletedTransitionID
 }
 
+func (c *jitRbacOperatorConfiguration) RevokedTransitionID() string {
+	return c.retrievalFn().Spec.RevokedTransitionID
+}
+
+func (c *jitRbacOperatorConfiguration) ExpiredTransitionID() string {
+	return c.retrievalFn().Spec.ExpiredTransitionID
+}
+
+func (c *jitRbacOperatorConfiguration) ExtensionTransitionID() string {
+	return c.retrievalFn().Spec.ExtensionTransitionID
+}
+
 func (c *jitRbacOperatorConfiguration) CustomFields() map[string]justintimev1.CustomFieldSettings {
 	return c.retrievalFn().Spec.CustomFields
 }
@@ -16,15 +16,28 @@ limitations under the License.
 
 package configuration
 
-import justintimev1 "jira-jit-rbac-operator/api/v1"
+import (
+	justintimev1 "jira-jit-rbac-operator/api/v1"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
 
 type Configuration interface {
 	AllowedClusterRoles() []string
+	AllowedClusterScopedRoles() []string
 	JiraWorkflowApproveStatus() string
+	JiraWorkflowRejectedStatus() string
+	JiraWorkflowRejectedStatuses() []string
 	RejectedTransitionID() string
 	JiraProject() string
 	JiraIssueType() string
+	LinkedIssueProjects() []string
+	IssueLinkType() string
+	ServiceDesk() *justintimev1.ServiceDeskSpec
 	CompletedTransitionID() string
+	RevokedTransitionID() string
+	ExpiredTransitionID() string
+	ExtensionTransitionID() string
 	CustomFields() map[string]justintimev1.CustomFieldSettings
 	RequiredFields() *justintimev1.RequiredFieldsSpec
 	Labels() []string
@@ -32,4 +45,12 @@ type Configuration interface {
 	Environment() *justintimev1.EnvironmentSpec
 	NamespaceAllowedRegex() string
 	SelfApprovalEnabled() bool
+	RetentionPeriod() *metav1.Duration
+	ApprovalPollInterval() *metav1.Duration
+	ApprovalGracePeriod() *metav1.Duration
+	StartOnApprovalTimeout() *metav1.Duration
+	ApprovalMode() string
+	RolePolicies() map[string]justintimev1.RolePolicySpec
+	Timezone() string
+	Templates() *justintimev1.JiraTemplatesSpec
 }
@@ -2,6 +2,7 @@ package configuration
 
 import (
 	"context"
+	"time"
 
 	justintimev1 "jira-jit-rbac-operator/api/v1"
 
@@ -33,10 +34,19 @@ var _ = Describe("JitRbacOperatorConfiguration", func() {
 		config := NewJitRbacOperatorConfiguration(ctx, k8sClient, configName)
 
 		Expect(config.AllowedClusterRoles()).To(Equal([]string{"edit"}))
+		Expect(config.AllowedClusterScopedRoles()).To(BeEmpty())
 		Expect(config.JiraWorkflowApproveStatus()).To(Equal("Approved"))
+		Expect(config.JiraWorkflowRejectedStatus()).To(BeEmpty())
+		Expect(config.JiraWorkflowRejectedStatuses()).To(BeEmpty())
 		Expect(config.JiraProject()).To(Equal("IAM"))
 		Expect(config.JiraIssueType()).To(Equal("Access Request"))
+		Expect(config.LinkedIssueProjects()).To(BeEmpty())
+		Expect(config.IssueLinkType()).To(BeEmpty())
+		Expect(config.ServiceDesk()).To(BeNil())
 		Expect(config.CompletedTransitionID()).To(Equal("41"))
+		Expect(config.RevokedTransitionID()).To(BeEmpty())
+		Expect(config.ExpiredTransitionID()).To(BeEmpty())
+		Expect(config.ExtensionTransitionID()).To(BeEmpty())
 		Expect(config.AdditionalCommentText()).To(Equal("config: default"))
 		Expect(config.NamespaceAllowedRegex()).To(Equal(".*"))
 		Expect(config.Labels()).To(Equal([]string{"default-config"}))
@@ -45,9 +55,9 @@ var _ = Describe("JitRbacOperatorConfiguration", func() {
 			Cluster:     "minikube",
 		}))
 		Expect(config.RequiredFields()).To(Equal(&justintimev1.RequiredFieldsSpec{
-			StartTime:   justintimev1.CustomFieldSettings{Type: "date", JiraCustomField: "customfield_10118"},
-			EndTime:     justintimev1.CustomFieldSettings{Type: "date", JiraCustomField: "customfield_10119"},
-			ClusterRole: justintimev1.CustomFieldSettings{Type: "date", JiraCustomField: "customfield_10117"},
+			StartTime:   justintimev1.CustomFieldSettings{Type: "datetime", JiraCustomField: "customfield_10118"},
+			EndTime:     justintimev1.CustomFieldSettings{Type: "datetime", JiraCustomField: "customfield_10119"},
+			ClusterRole: justintimev1.CustomFieldSettings{Type: "text", JiraCustomField: "customfield_10117"},
 		}))
 		Expect(config.CustomFields()).To(Equal(map[string]justintimev1.CustomFieldSettings{
 			"Approver":      {Type: "user", JiraCustomField: "customfield_10114"},
@@ -55,6 +65,14 @@ var _ = Describe("JitRbacOperatorConfiguration", func() {
 			"Justification": {Type: "text", JiraCustomField: "customfield_10116"},
 		}))
 		Expect(config.SelfApprovalEnabled()).To(BeFalse())
+		Expect(config.RetentionPeriod()).To(BeNil())
+		Expect(config.ApprovalPollInterval()).To(BeNil())
+		Expect(config.ApprovalGracePeriod()).To(BeNil())
+		Expect(config.StartOnApprovalTimeout()).To(BeNil())
+		Expect(config.ApprovalMode()).To(BeEmpty())
+		Expect(config.RolePolicies()).To(BeEmpty())
+		Expect(config.Timezone()).To(BeEmpty())
+		Expect(config.Templates()).To(BeNil())
 	})
 
 	It("should return the retrieved configuration if found", func() {
@@ -63,14 +81,23 @@ var _ = Describe("JitRbacOperatorConfiguration", func() {
 				Name: configName,
 			},
 			Spec: justintimev1.JustInTimeConfigSpec{
-				AllowedClusterRoles:       []string{"admin"},
-				JiraWorkflowApproveStatus: "Approved",
-				RejectedTransitionID:      "22",
-				JiraProject:               "IAM",
-				JiraIssueType:             "Access Request",
-				CompletedTransitionID:     "42",
-				AdditionalCommentText:     "config: custom",
-				NamespaceAllowedRegex:     ".*",
+				AllowedClusterRoles:          []string{"admin"},
+				AllowedClusterScopedRoles:    []string{"view"},
+				JiraWorkflowApproveStatus:    "Approved",
+				JiraWorkflowRejectedStatus:   "Rejected",
+				JiraWorkflowRejectedStatuses: []string{"Declined", "Closed"},
+				RejectedTransitionID:         "22",
+				JiraProject:                  "IAM",
+				JiraIssueType:                "Access Request",
+				LinkedIssueProjects:          []string{"INC", "OPS"},
+				IssueLinkType:                "Blocks",
+				ServiceDesk:                  &justintimev1.ServiceDeskSpec{ServiceDeskID: "1", RequestTypeID: "25"},
+				CompletedTransitionID:        "42",
+				RevokedTransitionID:          "52",
+				ExpiredTransitionID:          "72",
+				ExtensionTransitionID:        "62",
+				AdditionalCommentText:        "config: custom",
+				NamespaceAllowedRegex:        ".*",
 				Labels: []string{
 					"custom-config",
 				},
@@ -79,16 +106,38 @@ var _ = Describe("JitRbacOperatorConfiguration", func() {
 					Cluster:     "k8s",
 				},
 				RequiredFields: &justintimev1.RequiredFieldsSpec{
-					StartTime:   justintimev1.CustomFieldSettings{Type: "date", JiraCustomField: "customfield_10118"},
-					EndTime:     justintimev1.CustomFieldSettings{Type: "date", JiraCustomField: "customfield_10119"},
-					ClusterRole: justintimev1.CustomFieldSettings{Type: "date", JiraCustomField: "customfield_10117"},
+					StartTime:   justintimev1.CustomFieldSettings{Type: "datetime", JiraCustomField: "customfield_10118"},
+					EndTime:     justintimev1.CustomFieldSettings{Type: "datetime", JiraCustomField: "customfield_10119"},
+					ClusterRole: justintimev1.CustomFieldSettings{Type: "text", JiraCustomField: "customfield_10117"},
 				},
 				CustomFields: map[string]justintimev1.CustomFieldSettings{
 					"Approver":      {Type: "user", JiraCustomField: "customfield_10114"},
 					"ProductOwner":  {Type: "user", JiraCustomField: "customfield_10115"},
 					"Justification": {Type: "text", JiraCustomField: "customfield_10116"},
 				},
-				SelfApprovalEnabled: true,
+				SelfApprovalEnabled:    true,
+				RetentionPeriod:        &metav1.Duration{Duration: 24 * time.Hour},
+				ApprovalPollInterval:   &metav1.Duration{Duration: 30 * time.Second},
+				ApprovalGracePeriod:    &metav1.Duration{Duration: 15 * time.Minute},
+				StartOnApprovalTimeout: &metav1.Duration{Duration: 4 * time.Hour},
+				ApprovalMode:           justintimev1.ApprovalModeServiceDesk,
+				RolePolicies: map[string]justintimev1.RolePolicySpec{
+					"admin": {
+						MaxDuration:             &metav1.Duration{Duration: 4 * time.Hour},
+						MaxLeadTime:             &metav1.Duration{Duration: 24 * time.Hour},
+						NamespaceAllowedRegexes: []string{"^team-.*"},
+						NamespaceSelector: &metav1.LabelSelector{
+							MatchLabels: map[string]string{"env": "dev"},
+						},
+						RequiredJiraFields: []string{"Justification"},
+						RequiredApprovals:  2,
+					},
+				},
+				Timezone: "Europe/London",
+				Templates: &justintimev1.JiraTemplatesSpec{
+					Summary:          "JIT {{ .JitRequest.Spec.ClusterRole }} for {{ .JitRequest.Spec.Reporter }}",
+					CompletedComment: "Access granted in {{ .Environment.Cluster }}",
+				},
 			},
 		}
 
@@ -96,11 +145,20 @@ var _ = Describe("JitRbacOperatorConfiguration", func() {
 		config := NewJitRbacOperatorConfiguration(ctx, k8sClient, configName)
 
 		Expect(config.AllowedClusterRoles()).To(Equal(expectedConfig.Spec.AllowedClusterRoles))
+		Expect(config.AllowedClusterScopedRoles()).To(Equal(expectedConfig.Spec.AllowedClusterScopedRoles))
 		Expect(config.JiraWorkflowApproveStatus()).To(Equal(expectedConfig.Spec.JiraWorkflowApproveStatus))
+		Expect(config.JiraWorkflowRejectedStatus()).To(Equal(expectedConfig.Spec.JiraWorkflowRejectedStatus))
+		Expect(config.JiraWorkflowRejectedStatuses()).To(Equal(expectedConfig.Spec.JiraWorkflowRejectedStatuses))
 		Expect(config.RejectedTransitionID()).To(Equal(expectedConfig.Spec.RejectedTransitionID))
 		Expect(config.JiraProject()).To(Equal(expectedConfig.Spec.JiraProject))
 		Expect(config.JiraIssueType()).To(Equal(expectedConfig.Spec.JiraIssueType))
+		Expect(config.LinkedIssueProjects()).To(Equal(expectedConfig.Spec.LinkedIssueProjects))
+		Expect(config.IssueLinkType()).To(Equal(expectedConfig.Spec.IssueLinkType))
+		Expect(config.ServiceDesk()).To(Equal(expectedConfig.Spec.ServiceDesk))
 		Expect(config.CompletedTransitionID()).To(Equal(expectedConfig.Spec.CompletedTransitionID))
+		Expect(config.RevokedTransitionID()).To(Equal(expectedConfig.Spec.RevokedTransitionID))
+		Expect(config.ExpiredTransitionID()).To(Equal(expectedConfig.Spec.ExpiredTransitionID))
+		Expect(config.ExtensionTransitionID()).To(Equal(expectedConfig.Spec.ExtensionTransitionID))
 		Expect(config.AdditionalCommentText()).To(Equal(expectedConfig.Spec.AdditionalCommentText))
 		Expect(config.NamespaceAllowedRegex()).To(Equal(expectedConfig.Spec.NamespaceAllowedRegex))
 		Expect(config.Labels()).To(Equal(expectedConfig.Spec.Labels))
@@ -108,5 +166,13 @@ var _ = Describe("JitRbacOperatorConfiguration", func() {
 		Expect(config.RequiredFields()).To(Equal(expectedConfig.Spec.RequiredFields))
 		Expect(config.CustomFields()).To(Equal(expectedConfig.Spec.CustomFields))
 		Expect(config.SelfApprovalEnabled()).To(BeTrue())
+		Expect(config.RetentionPeriod()).To(Equal(expectedConfig.Spec.RetentionPeriod))
+		Expect(config.ApprovalPollInterval()).To(Equal(expectedConfig.Spec.ApprovalPollInterval))
+		Expect(config.ApprovalGracePeriod()).To(Equal(expectedConfig.Spec.ApprovalGracePeriod))
+		Expect(config.StartOnApprovalTimeout()).To(Equal(expectedConfig.Spec.StartOnApprovalTimeout))
+		Expect(config.ApprovalMode()).To(Equal(expectedConfig.Spec.ApprovalMode))
+		Expect(config.RolePolicies()).To(Equal(expectedConfig.Spec.RolePolicies))
+		Expect(config.Timezone()).To(Equal(expectedConfig.Spec.Timezone))
+		Expect(config.Templates()).To(Equal(expectedConfig.Spec.Templates))
 	})
 })
@@ -0,0 +1,145 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package credentials
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// Keys of the Jira credentials, as files in a mounted directory or keys in a Secret
+const (
+	BaseURLKey    = "base-url"
+	AuthModeKey   = "auth-mode"
+	APITokenKey   = "api-token"
+	UsernameKey   = "username"
+	PasswordKey   = "password"
+	CABundleKey   = "ca.crt"
+	ClientCertKey = "tls.crt"
+	ClientKeyKey  = "tls.key"
+)
+
+// AuthMode is how the operator authenticates to Jira
+type AuthMode string
+
+const (
+	// AuthModeBearer uses the api token as a bearer PAT, for Jira Server/Data Center
+	AuthModeBearer AuthMode = "bearer"
+	// AuthModeBasic uses the username and password, or api token, for Jira Cloud
+	AuthModeBasic AuthMode = "basic"
+)
+
+// Credentials are the connection settings for Jira
+type Credentials struct {
+	BaseURL  *url.URL
+	AuthMode AuthMode
+	APIToken string
+	Username string
+	Password string
+	// Optional CA bundle to verify the Jira server
+	CABundle []byte
+	// Optional client certificate for mutual TLS
+	ClientCert []byte
+	ClientKey  []byte
+}
+
+// Parse returns the Credentials for a set of keys, using the defaultAuthMode if no auth mode is set
+func Parse(data map[string][]byte, defaultAuthMode AuthMode) (*Credentials, error) {
+	value := func(key string) string {
+		return strings.TrimSpace(string(data[key]))
+	}
+
+	if value(BaseURLKey) == "" {
+		return nil, fmt.Errorf("missing jira credentials key: %s", BaseURLKey)
+	}
+	baseURL, err := url.Parse(value(BaseURLKey))
+	if err != nil || baseURL.Scheme == "" || baseURL.Host == "" {
+		return nil, fmt.Errorf("invalid jira base url '%s'", value(BaseURLKey))
+	}
+
+	creds := &Credentials{
+		BaseURL:    baseURL,
+		AuthMode:   AuthMode(strings.ToLower(value(AuthModeKey))),
+		APIToken:   value(APITokenKey),
+		Username:   value(UsernameKey),
+		Password:   value(PasswordKey),
+		CABundle:   data[CABundleKey],
+		ClientCert: data[ClientCertKey],
+		ClientKey:  data[ClientKeyKey],
+	}
+	if creds.AuthMode == "" {
+		creds.AuthMode = defaultAuthMode
+	}
+
+	switch creds.AuthMode {
+	case AuthModeBearer:
+		if creds.APIToken == "" {
+			return nil, fmt.Errorf("missing jira credentials key: %s", APITokenKey)
+		}
+	case AuthModeBasic:
+		if creds.Username == "" {
+			return nil, fmt.Errorf("missing jira credentials key: %s", UsernameKey)
+		}
+		// Jira Cloud uses the api token as the password
+		if creds.Password == "" {
+			creds.Password = creds.APIToken
+		}
+		if creds.Password == "" {
+			return nil, fmt.Errorf("missing jira credentials key: %s or %s", PasswordKey, APITokenKey)
+		}
+	default:
+		return nil, fmt.Errorf("unknown jira auth mode '%s', must be one of: %s, %s", creds.AuthMode, AuthModeBearer, AuthModeBasic)
+	}
+
+	if (len(creds.ClientCert) == 0) != (len(creds.ClientKey) == 0) {
+		return nil, fmt.Errorf("jira client certificate requires both %s and %s", ClientCertKey, ClientKeyKey)
+	}
+
+	// check the TLS settings are valid before they are used
+	if _, err := creds.TLSConfig(); err != nil {
+		return nil, err
+	}
+
+	return creds, nil
+}
+
+// TLSConfig returns the TLS config for the CA bundle and client certificate, nil if neither are set
+func (c *Credentials) TLSConfig() (*tls.Config, error) {
+	if len(c.CABundle) == 0 && len(c.ClientCert) == 0 {
+		return nil, nil
+	}
+
+	tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+	if len(c.CABundle) > 0 {
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(c.CABundle) {
+			return nil, fmt.Errorf("invalid jira CA bundle: no certificates found in %s", CABundleKey)
+		}
+		tlsConfig.RootCAs = pool
+	}
+	if len(c.ClientCert) > 0 {
+		cert, err := tls.X509KeyPair(c.ClientCert, c.ClientKey)
+		if err != nil {
+			return nil, fmt.Errorf("invalid jira client certificate: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+	return tlsConfig, nil
+}
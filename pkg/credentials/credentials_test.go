@@ -0,0 +1,208 @@
+package credentials
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+var _ = Describe("Credentials", func() {
+
+	Describe("Parse", func() {
+		It("should parse bearer credentials", func() {
+			creds, err := Parse(map[string][]byte{
+				BaseURLKey:  []byte("https://jira.unsc.com\n"),
+				APITokenKey: []byte("cortana"),
+			}, AuthModeBearer)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(creds.BaseURL.String()).To(Equal("https://jira.unsc.com"))
+			Expect(creds.AuthMode).To(Equal(AuthModeBearer))
+			Expect(creds.APIToken).To(Equal("cortana"))
+		})
+
+		It("should use the api token as the basic auth password", func() {
+			creds, err := Parse(map[string][]byte{
+				BaseURLKey:  []byte("https://unsc.atlassian.net"),
+				AuthModeKey: []byte("basic"),
+				UsernameKey: []byte("master-chief@unsc.com"),
+				APITokenKey: []byte("cortana"),
+			}, AuthModeBearer)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(creds.AuthMode).To(Equal(AuthModeBasic))
+			Expect(creds.Password).To(Equal("cortana"))
+		})
+
+		It("should return an error for a missing base url", func() {
+			_, err := Parse(map[string][]byte{APITokenKey: []byte("cortana")}, AuthModeBearer)
+			Expect(err).To(MatchError("missing jira credentials key: base-url"))
+		})
+
+		It("should return an error for a missing username with basic auth", func() {
+			_, err := Parse(map[string][]byte{
+				BaseURLKey:  []byte("https://unsc.atlassian.net"),
+				APITokenKey: []byte("cortana"),
+			}, AuthModeBasic)
+			Expect(err).To(MatchError("missing jira credentials key: username"))
+		})
+
+		It("should return an error for an unknown auth mode", func() {
+			_, err := Parse(map[string][]byte{
+				BaseURLKey:  []byte("https://jira.unsc.com"),
+				AuthModeKey: []byte("oauth"),
+			}, AuthModeBearer)
+			Expect(err).To(MatchError(ContainSubstring("unknown jira auth mode 'oauth'")))
+		})
+
+		It("should return an error for an invalid CA bundle", func() {
+			_, err := Parse(map[string][]byte{
+				BaseURLKey:  []byte("https://jira.unsc.com"),
+				APITokenKey: []byte("cortana"),
+				CABundleKey: []byte("not a certificate"),
+			}, AuthModeBearer)
+			Expect(err).To(MatchError(ContainSubstring("invalid jira CA bundle")))
+		})
+
+		It("should return an error for a client certificate without a key", func() {
+			_, err := Parse(map[string][]byte{
+				BaseURLKey:    []byte("https://jira.unsc.com"),
+				APITokenKey:   []byte("cortana"),
+				ClientCertKey: []byte("certificate"),
+			}, AuthModeBearer)
+			Expect(err).To(MatchError("jira client certificate requires both tls.crt and tls.key"))
+		})
+	})
+
+	Describe("Sources", func() {
+		It("should load the files of a mounted Secret", func() {
+			dir := GinkgoT().TempDir()
+			Expect(os.Mkdir(filepath.Join(dir, "..data"), 0o755)).To(Succeed())
+			Expect(os.WriteFile(filepath.Join(dir, BaseURLKey), []byte("https://jira.unsc.com"), 0o600)).To(Succeed())
+			Expect(os.WriteFile(filepath.Join(dir, APITokenKey), []byte("cortana"), 0o600)).To(Succeed())
+
+			data, err := (&FileSource{Dir: dir}).Load(context.Background())
+			Expect(err).NotTo(HaveOccurred())
+			Expect(data).To(Equal(map[string][]byte{
+				BaseURLKey:  []byte("https://jira.unsc.com"),
+				APITokenKey: []byte("cortana"),
+			}))
+		})
+
+		It("should load the data of a Secret", func() {
+			reader := fake.NewClientBuilder().WithObjects(&corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: "jira-credentials", Namespace: "jit"},
+				Data:       map[string][]byte{APITokenKey: []byte("cortana")},
+			}).Build()
+
+			source := &SecretSource{Reader: reader, Key: types.NamespacedName{Namespace: "jit", Name: "jira-credentials"}}
+			data, err := source.Load(context.Background())
+			Expect(err).NotTo(HaveOccurred())
+			Expect(data).To(HaveKeyWithValue(APITokenKey, []byte("cortana")))
+		})
+	})
+
+	Describe("Reloader", func() {
+		var server *httptest.Server
+		var requests []*http.Request
+
+		BeforeEach(func() {
+			requests = nil
+			server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				requests = append(requests, r)
+				w.WriteHeader(http.StatusOK)
+			}))
+		})
+
+		AfterEach(func() {
+			server.Close()
+		})
+
+		It("should authenticate requests with the reloaded credentials", func() {
+			source := StaticSource{
+				BaseURLKey:  []byte(server.URL),
+				APITokenKey: []byte("cortana"),
+			}
+			reloader := NewReloader(source, AuthModeBearer, 0)
+			httpClient := &http.Client{Transport: reloader.Transport}
+			changes := 0
+			reloader.OnChange = func() { changes++ }
+
+			By("loading the initial credentials")
+			changed, err := reloader.Reload(context.Background())
+			Expect(err).NotTo(HaveOccurred())
+			Expect(changed).To(BeTrue())
+			Expect(changes).To(Equal(1))
+
+			_, err = httpClient.Get(server.URL + "/rest/api/2/myself")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(requests[0].Header.Get("Authorization")).To(Equal("Bearer cortana"))
+
+			By("reloading unchanged credentials")
+			changed, err = reloader.Reload(context.Background())
+			Expect(err).NotTo(HaveOccurred())
+			Expect(changed).To(BeFalse())
+			Expect(changes).To(Equal(1))
+
+			By("rotating the token")
+			source[APITokenKey] = []byte("roland")
+			changed, err = reloader.Reload(context.Background())
+			Expect(err).NotTo(HaveOccurred())
+			Expect(changed).To(BeTrue())
+			Expect(changes).To(Equal(2))
+
+			_, err = httpClient.Get(server.URL + "/rest/api/2/myself")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(requests[1].Header.Get("Authorization")).To(Equal("Bearer roland"))
+		})
+
+		It("should keep the current credentials if the reloaded credentials are invalid", func() {
+			source := StaticSource{
+				BaseURLKey:  []byte(server.URL),
+				APITokenKey: []byte("cortana"),
+			}
+			reloader := NewReloader(source, AuthModeBearer, 0)
+			_, err := reloader.Reload(context.Background())
+			Expect(err).NotTo(HaveOccurred())
+
+			delete(source, APITokenKey)
+			_, err = reloader.Reload(context.Background())
+			Expect(err).To(HaveOccurred())
+			Expect(reloader.Transport.Credentials().APIToken).To(Equal("cortana"))
+		})
+
+		It("should send requests to the reloaded base url", func() {
+			source := StaticSource{
+				BaseURLKey:  []byte("https://jira.unsc.com/jira"),
+				AuthModeKey: []byte("basic"),
+				UsernameKey: []byte("master-chief@unsc.com"),
+				APITokenKey: []byte("cortana"),
+			}
+			reloader := NewReloader(source, AuthModeBearer, 0)
+			httpClient := &http.Client{Transport: reloader.Transport}
+			_, err := reloader.Reload(context.Background())
+			Expect(err).NotTo(HaveOccurred())
+
+			By("moving jira to the test server")
+			source[BaseURLKey] = []byte(server.URL + "/")
+			_, err = reloader.Reload(context.Background())
+			Expect(err).NotTo(HaveOccurred())
+
+			_, err = httpClient.Get("https://jira.unsc.com/jira/rest/api/2/myself")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(requests).To(HaveLen(1))
+			Expect(requests[0].URL.Path).To(Equal("/rest/api/2/myself"))
+			username, password, ok := requests[0].BasicAuth()
+			Expect(ok).To(BeTrue())
+			Expect(username).To(Equal("master-chief@unsc.com"))
+			Expect(password).To(Equal("cortana"))
+		})
+	})
+})
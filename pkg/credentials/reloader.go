@@ -0,0 +1,126 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package credentials
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"sort"
+	"time"
+
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// Reloader loads the Jira credentials from a Source and updates the Transport when they change
+type Reloader struct {
+	Source Source
+	// Auth mode if not set in the credentials
+	DefaultAuthMode AuthMode
+	// How often to check the Source for changes, 0 to disable reloading
+	Interval  time.Duration
+	Transport *Transport
+	// Called once the credentials changed, i.e. to clear what was looked up in Jira with the previous credentials
+	OnChange func()
+
+	checksum []byte
+}
+
+// NewReloader returns a Reloader for the Source with a new Transport
+func NewReloader(source Source, defaultAuthMode AuthMode, interval time.Duration) *Reloader {
+	return &Reloader{
+		Source:          source,
+		DefaultAuthMode: defaultAuthMode,
+		Interval:        interval,
+		Transport:       &Transport{},
+	}
+}
+
+// Reload loads the credentials and updates the Transport, returns true if they changed
+func (r *Reloader) Reload(ctx context.Context) (bool, error) {
+	data, err := r.Source.Load(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	sum := checksum(data)
+	if bytes.Equal(sum, r.checksum) {
+		return false, nil
+	}
+
+	creds, err := Parse(data, r.DefaultAuthMode)
+	if err != nil {
+		return false, err
+	}
+	if err := r.Transport.Update(creds); err != nil {
+		return false, err
+	}
+	r.checksum = sum
+	if r.OnChange != nil {
+		r.OnChange()
+	}
+	return true, nil
+}
+
+// Start reloads the credentials every Interval until the context is cancelled, keeping the current credentials on errors
+func (r *Reloader) Start(ctx context.Context) error {
+	if r.Interval <= 0 {
+		return nil
+	}
+	l := log.FromContext(ctx).WithName("jira-credentials")
+
+	ticker := time.NewTicker(r.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			changed, err := r.Reload(ctx)
+			if err != nil {
+				l.Error(err, "failed to reload jira credentials, keeping the current credentials", "source", r.Source.String())
+				continue
+			}
+			if changed {
+				l.Info("Reloaded jira credentials", "source", r.Source.String(), "baseUrl", r.Transport.Credentials().BaseURL.String())
+			}
+		}
+	}
+}
+
+// NeedLeaderElection is false as every replica calls Jira from the webhook
+func (r *Reloader) NeedLeaderElection() bool {
+	return false
+}
+
+// checksum returns a hash of the credentials to detect changes
+func checksum(data map[string][]byte) []byte {
+	keys := make([]string, 0, len(data))
+	for key := range data {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	hash := sha256.New()
+	for _, key := range keys {
+		hash.Write([]byte(key))
+		hash.Write([]byte{0})
+		hash.Write(data[key])
+		hash.Write([]byte{0})
+	}
+	return hash.Sum(nil)
+}
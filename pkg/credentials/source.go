@@ -0,0 +1,106 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package credentials
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Source loads the raw Jira credentials keys
+type Source interface {
+	Load(ctx context.Context) (map[string][]byte, error)
+	String() string
+}
+
+// FileSource loads credentials from a directory with a file per key, i.e. a mounted Secret
+type FileSource struct {
+	Dir string
+}
+
+// Load reads each file in the directory as a key
+func (s *FileSource) Load(_ context.Context) (map[string][]byte, error) {
+	entries, err := os.ReadDir(s.Dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read jira credentials dir: %w", err)
+	}
+
+	data := map[string][]byte{}
+	for _, entry := range entries {
+		// skip the hidden ..data symlinks of a mounted Secret
+		if strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+		path := filepath.Join(s.Dir, entry.Name())
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read jira credentials file: %w", err)
+		}
+		if info.IsDir() {
+			continue
+		}
+		value, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read jira credentials file: %w", err)
+		}
+		data[entry.Name()] = value
+	}
+	return data, nil
+}
+
+func (s *FileSource) String() string {
+	return "dir " + s.Dir
+}
+
+// SecretSource loads credentials from the keys of a Secret
+type SecretSource struct {
+	// Reader should not be cached to avoid watching all Secrets, i.e. the manager's APIReader
+	Reader client.Reader
+	Key    types.NamespacedName
+}
+
+// Load gets the Secret data
+func (s *SecretSource) Load(ctx context.Context) (map[string][]byte, error) {
+	secret := &corev1.Secret{}
+	if err := s.Reader.Get(ctx, s.Key, secret); err != nil {
+		return nil, fmt.Errorf("failed to get jira credentials secret: %w", err)
+	}
+	return secret.Data, nil
+}
+
+func (s *SecretSource) String() string {
+	return "secret " + s.Key.String()
+}
+
+// StaticSource returns fixed credentials, i.e. from environment variables
+type StaticSource map[string][]byte
+
+// Load returns the static credentials
+func (s StaticSource) Load(_ context.Context) (map[string][]byte, error) {
+	return s, nil
+}
+
+func (s StaticSource) String() string {
+	return "environment"
+}
@@ -0,0 +1,103 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package credentials
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+)
+
+// Transport authenticates requests to Jira with the current Credentials, which can be updated at any time.
+// Requests are made against the base url the Jira client was created with and rewritten to the current base url.
+type Transport struct {
+	mu        sync.RWMutex
+	origin    *url.URL
+	creds     *Credentials
+	transport *http.Transport
+}
+
+// Update swaps the credentials used for new requests
+func (t *Transport) Update(creds *Credentials) error {
+	tlsConfig, err := creds.TLSConfig()
+	if err != nil {
+		return err
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = tlsConfig
+
+	t.mu.Lock()
+	previous := t.transport
+	if t.origin == nil {
+		t.origin = creds.BaseURL
+	}
+	t.creds = creds
+	t.transport = transport
+	t.mu.Unlock()
+
+	// connections of the previous credentials may use an old certificate
+	if previous != nil {
+		previous.CloseIdleConnections()
+	}
+	return nil
+}
+
+// Credentials returns the current credentials, nil if not loaded
+func (t *Transport) Credentials() *Credentials {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return t.creds
+}
+
+// RoundTrip sends the request to the current base url with the current credentials
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	t.mu.RLock()
+	origin, creds, transport := t.origin, t.creds, t.transport
+	t.mu.RUnlock()
+
+	if creds == nil {
+		return nil, errors.New("jira credentials are not loaded")
+	}
+
+	// the request must not be modified, see http.RoundTripper
+	req = req.Clone(req.Context())
+	rewriteURL(req.URL, origin, creds.BaseURL)
+	req.Host = ""
+
+	switch creds.AuthMode {
+	case AuthModeBasic:
+		req.SetBasicAuth(creds.Username, creds.Password)
+	default:
+		req.Header.Set("Authorization", "Bearer "+creds.APIToken)
+	}
+
+	return transport.RoundTrip(req)
+}
+
+// rewriteURL moves a url relative to the origin onto the base url
+func rewriteURL(u, origin, base *url.URL) {
+	if origin.Scheme == base.Scheme && origin.Host == base.Host && origin.Path == base.Path {
+		return
+	}
+	path := strings.TrimPrefix(u.Path, strings.TrimSuffix(origin.Path, "/"))
+	u.Scheme = base.Scheme
+	u.Host = base.Host
+	u.Path = strings.TrimSuffix(base.Path, "/") + path
+	u.RawPath = ""
+}
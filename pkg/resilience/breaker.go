@@ -0,0 +1,135 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resilience
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ErrUnavailable is matched by the errors of requests to Jira while it is unavailable
+var ErrUnavailable = errors.New("jira is unavailable")
+
+// UnavailableError is returned while the circuit breaker is open or when Jira could not be reached after retries
+type UnavailableError struct {
+	// How long until Jira should be called again
+	RetryAfter time.Duration
+	Err        error
+}
+
+func (e *UnavailableError) Error() string {
+	if e.Err == nil {
+		return fmt.Sprintf("%s, retry after %s", ErrUnavailable, e.RetryAfter)
+	}
+	return fmt.Sprintf("%s, retry after %s: %s", ErrUnavailable, e.RetryAfter, e.Err)
+}
+
+func (e *UnavailableError) Unwrap() error {
+	return e.Err
+}
+
+func (e *UnavailableError) Is(target error) bool {
+	return target == ErrUnavailable
+}
+
+// IsUnavailable returns true if the error is caused by Jira being unavailable
+func IsUnavailable(err error) bool {
+	return errors.Is(err, ErrUnavailable)
+}
+
+// Breaker opens after consecutive failures to stop calling Jira for a cooldown,
+// then lets a single probe request through and closes again once it succeeds
+type Breaker struct {
+	// Consecutive failures that open the breaker, 0 to disable it
+	Threshold int
+	// How long the breaker stays open
+	Cooldown time.Duration
+
+	mu        sync.Mutex
+	failures  int
+	openUntil time.Time
+	probing   bool
+	now       func() time.Time
+}
+
+// NewBreaker returns a closed Breaker
+func NewBreaker(threshold int, cooldown time.Duration) *Breaker {
+	return &Breaker{
+		Threshold: threshold,
+		Cooldown:  cooldown,
+		now:       time.Now,
+	}
+}
+
+// Allow returns an UnavailableError if the breaker is open or a probe request is already in flight
+func (b *Breaker) Allow() error {
+	if b.Threshold <= 0 {
+		return nil
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.failures < b.Threshold {
+		return nil
+	}
+	if remaining := b.openUntil.Sub(b.now()); remaining > 0 {
+		return &UnavailableError{RetryAfter: remaining}
+	}
+	if b.probing {
+		return &UnavailableError{RetryAfter: b.Cooldown}
+	}
+	b.probing = true
+	return nil
+}
+
+// Success closes the breaker
+func (b *Breaker) Success() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.failures = 0
+	b.probing = false
+}
+
+// Failure counts a failed request and opens the breaker for at least the cooldown once the threshold is reached
+func (b *Breaker) Failure(retryAfter time.Duration) {
+	if b.Threshold <= 0 {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.failures++
+	b.probing = false
+	if b.failures >= b.Threshold {
+		b.openUntil = b.now().Add(max(b.Cooldown, retryAfter))
+	}
+}
+
+// Abort releases a probe request that was cancelled by the caller, without counting it as a success or failure
+func (b *Breaker) Abort() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.probing = false
+}
+
+// Open returns true if requests are currently rejected
+func (b *Breaker) Open() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.Threshold > 0 && b.failures >= b.Threshold && b.now().Before(b.openUntil)
+}
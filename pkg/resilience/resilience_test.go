@@ -0,0 +1,253 @@
+package resilience
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Resilience", func() {
+
+	var (
+		calls   atomic.Int32
+		handler http.HandlerFunc
+		server  *httptest.Server
+		client  *http.Client
+		options Options
+	)
+
+	newClient := func() *http.Client {
+		return &http.Client{Transport: NewTransport(http.DefaultTransport, options)}
+	}
+
+	BeforeEach(func() {
+		calls.Store(0)
+		handler = func(w http.ResponseWriter, r *http.Request) {}
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			calls.Add(1)
+			handler(w, r)
+		}))
+		DeferCleanup(server.Close)
+		options = Options{
+			Timeout:          time.Second,
+			MaxRetries:       2,
+			MinBackoff:       time.Millisecond,
+			MaxBackoff:       10 * time.Millisecond,
+			BreakerThreshold: 2,
+			BreakerCooldown:  time.Minute,
+		}
+		client = newClient()
+	})
+
+	Describe("Transport", func() {
+		It("should retry a get request until jira is available", func() {
+			handler = func(w http.ResponseWriter, r *http.Request) {
+				if calls.Load() < 3 {
+					w.WriteHeader(http.StatusServiceUnavailable)
+					return
+				}
+				_, _ = w.Write([]byte("ok"))
+			}
+			resp, err := client.Get(server.URL)
+			Expect(err).NotTo(HaveOccurred())
+			defer resp.Body.Close()
+			body, _ := io.ReadAll(resp.Body)
+			Expect(string(body)).To(Equal("ok"))
+			Expect(calls.Load()).To(Equal(int32(3)))
+		})
+
+		It("should return an unavailable error once the retries are exhausted", func() {
+			handler = func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusBadGateway)
+			}
+			_, err := client.Get(server.URL)
+			Expect(IsUnavailable(err)).To(BeTrue())
+			Expect(err.Error()).To(ContainSubstring("502 Bad Gateway"))
+			Expect(calls.Load()).To(Equal(int32(3)))
+		})
+
+		It("should not retry a post request on a server error", func() {
+			handler = func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusServiceUnavailable)
+			}
+			_, err := client.Post(server.URL, "application/json", strings.NewReader(`{}`))
+			Expect(IsUnavailable(err)).To(BeTrue())
+			Expect(calls.Load()).To(Equal(int32(1)))
+		})
+
+		It("should retry a rate limited post request with its body", func() {
+			var bodies []string
+			handler = func(w http.ResponseWriter, r *http.Request) {
+				body, _ := io.ReadAll(r.Body)
+				bodies = append(bodies, string(body))
+				if calls.Load() == 1 {
+					w.Header().Set("Retry-After", "0")
+					w.WriteHeader(http.StatusTooManyRequests)
+					return
+				}
+				w.WriteHeader(http.StatusCreated)
+			}
+			resp, err := client.Post(server.URL, "application/json", strings.NewReader(`{"key":"IAM-1"}`))
+			Expect(err).NotTo(HaveOccurred())
+			resp.Body.Close()
+			Expect(resp.StatusCode).To(Equal(http.StatusCreated))
+			Expect(bodies).To(Equal([]string{`{"key":"IAM-1"}`, `{"key":"IAM-1"}`}))
+		})
+
+		It("should return client errors without retrying", func() {
+			handler = func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusNotFound)
+			}
+			resp, err := client.Get(server.URL)
+			Expect(err).NotTo(HaveOccurred())
+			resp.Body.Close()
+			Expect(resp.StatusCode).To(Equal(http.StatusNotFound))
+			Expect(calls.Load()).To(Equal(int32(1)))
+		})
+
+		It("should time out each attempt", func() {
+			options.Timeout = 10 * time.Millisecond
+			options.MaxRetries = 1
+			client = newClient()
+			handler = func(w http.ResponseWriter, r *http.Request) {
+				<-r.Context().Done()
+			}
+			_, err := client.Get(server.URL)
+			Expect(IsUnavailable(err)).To(BeTrue())
+			Expect(errors.Is(err, context.DeadlineExceeded)).To(BeTrue())
+			Expect(calls.Load()).To(Equal(int32(2)))
+		})
+
+		It("should stop retrying when the request is cancelled", func() {
+			options.MinBackoff = time.Minute
+			options.MaxBackoff = time.Minute
+			client = newClient()
+			handler = func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusServiceUnavailable)
+			}
+			ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+			defer cancel()
+			req, _ := http.NewRequestWithContext(ctx, http.MethodGet, server.URL, nil)
+			_, err := client.Do(req)
+			Expect(errors.Is(err, context.DeadlineExceeded)).To(BeTrue())
+			Expect(IsUnavailable(err)).To(BeFalse())
+			Expect(calls.Load()).To(Equal(int32(1)))
+		})
+
+		It("should open the breaker after consecutive failures", func() {
+			handler = func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusServiceUnavailable)
+			}
+			for range 2 {
+				_, err := client.Get(server.URL)
+				Expect(IsUnavailable(err)).To(BeTrue())
+			}
+			Expect(calls.Load()).To(Equal(int32(6)))
+
+			_, err := client.Get(server.URL)
+			var unavailableErr *UnavailableError
+			Expect(errors.As(err, &unavailableErr)).To(BeTrue())
+			Expect(unavailableErr.RetryAfter).To(BeNumerically("~", time.Minute, time.Second))
+			Expect(calls.Load()).To(Equal(int32(6)))
+		})
+
+		It("should open the breaker until a long retry after", func() {
+			options.BreakerThreshold = 1
+			client = newClient()
+			handler = func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Retry-After", "120")
+				w.WriteHeader(http.StatusTooManyRequests)
+			}
+			_, err := client.Get(server.URL)
+			Expect(IsUnavailable(err)).To(BeTrue())
+			Expect(calls.Load()).To(Equal(int32(1)))
+
+			_, err = client.Get(server.URL)
+			var unavailableErr *UnavailableError
+			Expect(errors.As(err, &unavailableErr)).To(BeTrue())
+			Expect(unavailableErr.RetryAfter).To(BeNumerically(">", time.Minute))
+			Expect(calls.Load()).To(Equal(int32(1)))
+		})
+	})
+
+	Describe("Breaker", func() {
+		var (
+			breaker *Breaker
+			now     time.Time
+		)
+
+		BeforeEach(func() {
+			now = time.Now()
+			breaker = NewBreaker(2, time.Minute)
+			breaker.now = func() time.Time { return now }
+		})
+
+		It("should let a single probe through after the cooldown", func() {
+			breaker.Failure(0)
+			Expect(breaker.Allow()).To(Succeed())
+			breaker.Failure(0)
+			Expect(breaker.Open()).To(BeTrue())
+			Expect(IsUnavailable(breaker.Allow())).To(BeTrue())
+
+			now = now.Add(time.Minute)
+			Expect(breaker.Allow()).To(Succeed())
+			Expect(IsUnavailable(breaker.Allow())).To(BeTrue())
+
+			breaker.Success()
+			Expect(breaker.Open()).To(BeFalse())
+			Expect(breaker.Allow()).To(Succeed())
+		})
+
+		It("should open again if the probe fails", func() {
+			breaker.Failure(0)
+			breaker.Failure(0)
+			now = now.Add(time.Minute)
+			Expect(breaker.Allow()).To(Succeed())
+			breaker.Failure(0)
+			Expect(breaker.Open()).To(BeTrue())
+		})
+
+		It("should release an aborted probe", func() {
+			breaker.Failure(0)
+			breaker.Failure(0)
+			now = now.Add(time.Minute)
+			Expect(breaker.Allow()).To(Succeed())
+			breaker.Abort()
+			Expect(breaker.Allow()).To(Succeed())
+		})
+
+		It("should never open when disabled", func() {
+			breaker.Threshold = 0
+			for range 10 {
+				breaker.Failure(0)
+			}
+			Expect(breaker.Open()).To(BeFalse())
+			Expect(breaker.Allow()).To(Succeed())
+		})
+	})
+
+	Describe("parseRetryAfter", func() {
+		It("should parse seconds and dates", func() {
+			header := http.Header{}
+			resp := &http.Response{Header: header}
+			Expect(parseRetryAfter(resp)).To(BeZero())
+
+			header.Set("Retry-After", "30")
+			Expect(parseRetryAfter(resp)).To(Equal(30 * time.Second))
+
+			header.Set("Retry-After", time.Now().Add(time.Hour).UTC().Format(http.TimeFormat))
+			Expect(parseRetryAfter(resp)).To(BeNumerically("~", time.Hour, 2*time.Second))
+
+			header.Set("Retry-After", "soon")
+			Expect(parseRetryAfter(resp)).To(BeZero())
+		})
+	})
+})
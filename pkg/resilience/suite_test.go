@@ -0,0 +1,13 @@
+package resilience
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestResilience(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Resilience Suite")
+}
@@ -0,0 +1,212 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resilience
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// Options of the Transport
+type Options struct {
+	// Timeout of each attempt of a request, 0 for none
+	Timeout time.Duration
+	// Retries after the first attempt of a request
+	MaxRetries int
+	// Backoff before the first retry, doubled on each retry up to MaxBackoff.
+	// A Retry-After longer than MaxBackoff is not waited for, the breaker is opened until then instead.
+	MinBackoff time.Duration
+	MaxBackoff time.Duration
+	// Consecutive failed requests that open the circuit breaker, 0 to disable it
+	BreakerThreshold int
+	// How long the circuit breaker stays open
+	BreakerCooldown time.Duration
+}
+
+// DefaultOptions are the options of the Transport if not set by flags
+func DefaultOptions() Options {
+	return Options{
+		Timeout:          10 * time.Second,
+		MaxRetries:       3,
+		MinBackoff:       500 * time.Millisecond,
+		MaxBackoff:       5 * time.Second,
+		BreakerThreshold: 5,
+		BreakerCooldown:  30 * time.Second,
+	}
+}
+
+// Transport times out, retries and circuit breaks requests to Jira.
+// 429 responses are retried for any method, 502, 503, 504 responses and network errors only for idempotent methods.
+// Requests that fail after retries, or are rejected by the open breaker, return an UnavailableError.
+type Transport struct {
+	Base    http.RoundTripper
+	Options Options
+	Breaker *Breaker
+}
+
+// NewTransport returns a Transport sending requests with the base RoundTripper
+func NewTransport(base http.RoundTripper, opts Options) *Transport {
+	return &Transport{
+		Base:    base,
+		Options: opts,
+		Breaker: NewBreaker(opts.BreakerThreshold, opts.BreakerCooldown),
+	}
+}
+
+// RoundTrip sends the request, retrying it with a jittered backoff while Jira is unavailable
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if err := t.Breaker.Allow(); err != nil {
+		return nil, err
+	}
+	ctx := req.Context()
+	replayable := req.Body == nil || req.Body == http.NoBody || req.GetBody != nil
+
+	for attempt := 0; ; attempt++ {
+		resp, err := t.attempt(req, attempt)
+		if ctx.Err() != nil {
+			t.Breaker.Abort()
+			return nil, ctx.Err()
+		}
+		if !unavailable(resp, err) {
+			t.Breaker.Success()
+			return resp, nil
+		}
+
+		retryAfter := parseRetryAfter(resp)
+		backoff := t.backoff(attempt)
+		if retryAfter > 0 {
+			backoff = retryAfter
+		}
+		retryable := replayable && attempt < t.Options.MaxRetries && backoff <= t.Options.MaxBackoff &&
+			(isIdempotent(req.Method) || resp != nil && resp.StatusCode == http.StatusTooManyRequests)
+
+		if resp != nil {
+			// drain the body so the connection can be reused
+			_, _ = io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+			err = fmt.Errorf("%s %s: %s", req.Method, req.URL.Path, resp.Status)
+		}
+		if !retryable {
+			t.Breaker.Failure(retryAfter)
+			return nil, &UnavailableError{RetryAfter: max(retryAfter, t.Options.BreakerCooldown), Err: err}
+		}
+
+		timer := time.NewTimer(backoff)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			t.Breaker.Abort()
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// attempt sends the request once with the per attempt timeout, replaying the body on retries
+func (t *Transport) attempt(req *http.Request, attempt int) (*http.Response, error) {
+	ctx, cancel := req.Context(), context.CancelFunc(func() {})
+	if t.Options.Timeout > 0 {
+		ctx, cancel = context.WithTimeout(ctx, t.Options.Timeout)
+	}
+
+	r := req.Clone(ctx)
+	if attempt > 0 && req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			cancel()
+			return nil, err
+		}
+		r.Body = body
+	}
+
+	resp, err := t.Base.RoundTrip(r)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	// the timeout must not be cancelled before the body is read
+	resp.Body = &cancelBody{ReadCloser: resp.Body, cancel: cancel}
+	return resp, nil
+}
+
+// backoff returns the jittered exponential backoff before a retry
+func (t *Transport) backoff(attempt int) time.Duration {
+	backoff := t.Options.MaxBackoff
+	if attempt < 32 {
+		backoff = min(t.Options.MinBackoff<<attempt, t.Options.MaxBackoff)
+	}
+	if backoff <= 0 {
+		return 0
+	}
+	return backoff/2 + rand.N(backoff/2+1)
+}
+
+// unavailable returns true if the request failed because Jira is unavailable or overloaded
+func unavailable(resp *http.Response, err error) bool {
+	if err != nil {
+		return true
+	}
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// isIdempotent returns true if a request with the method can be safely sent again
+func isIdempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+// parseRetryAfter returns the delay of a Retry-After header in seconds or as a date, 0 if not set
+func parseRetryAfter(resp *http.Response) time.Duration {
+	if resp == nil {
+		return 0
+	}
+	value := resp.Header.Get("Retry-After")
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		return max(time.Duration(seconds)*time.Second, 0)
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		return max(time.Until(date), 0)
+	}
+	return 0
+}
+
+// cancelBody cancels the context of the attempt when the response body is closed
+type cancelBody struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (b *cancelBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.cancel()
+	return err
+}
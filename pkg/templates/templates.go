@@ -0,0 +1,284 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package templates
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"text/template"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	justintimev1 "jira-jit-rbac-operator/api/v1"
+)
+
+// Names of the templates, as in the JiraTemplatesSpec
+const (
+	Summary                   = "summary"
+	Description               = "description"
+	PreApprovedComment        = "preApprovedComment"
+	CompletedComment          = "completedComment"
+	RejectedComment           = "rejectedComment"
+	RevokedComment            = "revokedComment"
+	ExpiredComment            = "expiredComment"
+	ExtensionRequestedComment = "extensionRequestedComment"
+	ExtensionCompletedComment = "extensionCompletedComment"
+)
+
+// Defaults are the templates used if not set in the config
+var Defaults = map[string]string{
+	Summary:     `Automated JIT request for {{ .JitRequest.Spec.Reporter }}`,
+	Description: ``,
+	PreApprovedComment: `{color:#00875a}*{{ .Message }}*{color}
+{{- if .JitRequest.Spec.ClusterScoped }}
+|*Scope*|Cluster-wide|
+{{- else }}
+|*Namespace(s)*|{{ join .Namespaces "\n" }}|
+{{- end }}
+|*User*|{{ .JitRequest.Spec.Reporter }}|
+{{- with .JitRequest.Spec.AdditionUserEmails }}
+|*Additional Users*|{{ join . "\n" }}|
+{{- end }}
+{{- with .JitRequest.Spec.Subjects }}
+|*Subjects*|{{ range $i, $subject := . }}{{ if $i }}{{ "\n" }}{{ end }}{{ subject $subject }}{{ end }}|
+{{- end }}
+{{- with .AdditionalComments }}
+
+*Additional Info:*
+{{ . }}
+{{- end }}`,
+	CompletedComment: `{color:#00875a}*Completed - Access granted until end time*{color}`,
+	RejectedComment:  `{color:#de350b}*Rejected - {{ .Message }}*{color}`,
+	RevokedComment: `{color:#de350b}*Revoked - Access has been revoked before end time*{color}
+|*Revoked By*|{{ .JitRequest.Spec.Revocation.RevokedBy }}|
+|*Reason*|{{ .JitRequest.Spec.Revocation.Reason }}|`,
+	ExpiredComment: `{color:#97a0af}*Expired - Access has been revoked at end time*{color}
+|*End Time*|{{ formatTime .JitRequest.Status.EndTime }}|
+|*Revoked At*|{{ formatTime .JitRequest.Status.CompletionTime }}|`,
+	ExtensionRequestedComment: `{color:#ff991f}*Extension requested - Access will be extended pending human approval(s)*{color}
+|*Current End Time*|{{ formatTime .JitRequest.Status.EndTime }}|
+|*Requested End Time*|{{ formatTime .JitRequest.Spec.Extension.EndTime }}|
+|*Reason*|{{ .JitRequest.Spec.Extension.Reason }}|`,
+	ExtensionCompletedComment: `{color:#00875a}*Completed - Access extended until {{ formatTime .JitRequest.Status.Extension.EndTime }}*{color}`,
+}
+
+// Data is what the templates are rendered over
+type Data struct {
+	JitRequest *justintimev1.JitRequest
+	// Environment and cluster name of the config
+	Environment *justintimev1.EnvironmentSpec
+	// Namespaces the role is bound in, empty for a cluster scoped JitRequest
+	Namespaces []string
+	// Status message of the JitRequest for the comment
+	Message string
+	// Additional comment text of the config
+	AdditionalComments string
+}
+
+// funcs are the functions available in the templates
+var funcs = template.FuncMap{
+	"join":       strings.Join,
+	"formatTime": formatTime,
+	"subject":    formatSubject,
+}
+
+// Templates renders the Jira ticket summary, description and comments for a config
+type Templates struct {
+	spec               *justintimev1.JiraTemplatesSpec
+	environment        *justintimev1.EnvironmentSpec
+	additionalComments string
+}
+
+// New returns the Templates of a config
+func New(operatorConfig *justintimev1.JustInTimeConfigSpec) *Templates {
+	return &Templates{
+		spec:               operatorConfig.Templates,
+		environment:        operatorConfig.Environment,
+		additionalComments: operatorConfig.AdditionalCommentText,
+	}
+}
+
+// Render renders a template for a JitRequest with the status message of the comment
+func (t *Templates) Render(name string, jitRequest *justintimev1.JitRequest, message string) (string, error) {
+	var namespaces []string
+	if !jitRequest.Spec.ClusterScoped {
+		namespaces = jitRequest.Spec.Namespaces
+	}
+	return render(name, text(t.spec, name), &Data{
+		JitRequest:         jitRequest,
+		Environment:        t.environment,
+		Namespaces:         namespaces,
+		Message:            message,
+		AdditionalComments: t.additionalComments,
+	})
+}
+
+// Validate parses the templates of a config and renders them over a sample JitRequest with every optional field set,
+// and over a minimal JitRequest with only the fields set when the template is used, so errors surface before they are used
+func Validate(spec *justintimev1.JiraTemplatesSpec) error {
+	if spec == nil {
+		return nil
+	}
+	sample := sampleData()
+	for name := range Defaults {
+		for _, data := range []*Data{sample, minimalData(name)} {
+			if _, err := render(name, text(spec, name), data); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// text returns the template of a name, or the default if not set
+func text(spec *justintimev1.JiraTemplatesSpec, name string) string {
+	if spec != nil {
+		var value string
+		switch name {
+		case Summary:
+			value = spec.Summary
+		case Description:
+			value = spec.Description
+		case PreApprovedComment:
+			value = spec.PreApprovedComment
+		case CompletedComment:
+			value = spec.CompletedComment
+		case RejectedComment:
+			value = spec.RejectedComment
+		case RevokedComment:
+			value = spec.RevokedComment
+		case ExpiredComment:
+			value = spec.ExpiredComment
+		case ExtensionRequestedComment:
+			value = spec.ExtensionRequestedComment
+		case ExtensionCompletedComment:
+			value = spec.ExtensionCompletedComment
+		}
+		if value != "" {
+			return value
+		}
+	}
+	return Defaults[name]
+}
+
+// render parses and executes a template
+func render(name, text string, data *Data) (string, error) {
+	tmpl, err := template.New(name).Funcs(funcs).Parse(text)
+	if err != nil {
+		return "", fmt.Errorf("invalid %s template: %w", name, err)
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("failed to render %s template: %w", name, err)
+	}
+	return buf.String(), nil
+}
+
+// formatTime formats a time as RFC3339
+func formatTime(t interface{}) (string, error) {
+	switch t := t.(type) {
+	case time.Time:
+		return t.Format(time.RFC3339), nil
+	case metav1.Time:
+		return t.Format(time.RFC3339), nil
+	case *metav1.Time:
+		if t == nil {
+			return "", nil
+		}
+		return t.Format(time.RFC3339), nil
+	default:
+		return "", fmt.Errorf("formatTime: unsupported type %T", t)
+	}
+}
+
+// formatSubject formats a subject as "Kind: namespace/name" or "Kind: name"
+func formatSubject(subject justintimev1.SubjectSpec) string {
+	if subject.Namespace != "" {
+		return fmt.Sprintf("%s: %s/%s", subject.Kind, subject.Namespace, subject.Name)
+	}
+	return fmt.Sprintf("%s: %s", subject.Kind, subject.Name)
+}
+
+// minimalData returns data with only the fields of a JitRequest that are always set when a template is used, to validate templates
+func minimalData(name string) *Data {
+	now := metav1.Now()
+	jitRequest := &justintimev1.JitRequest{
+		ObjectMeta: metav1.ObjectMeta{Name: "sample"},
+		Spec: justintimev1.JitRequestSpec{
+			Reporter:    "reporter@example.com",
+			ClusterRole: "edit",
+			Namespaces:  []string{"sample"},
+			StartTime:   now,
+			EndTime:     now,
+		},
+	}
+
+	switch name {
+	case RevokedComment:
+		jitRequest.Spec.Revocation = &justintimev1.RevocationSpec{RevokedBy: "admin@example.com", Reason: "sample"}
+		jitRequest.Status.CompletionTime = &now
+	case ExpiredComment:
+		jitRequest.Status.CompletionTime = &now
+	case ExtensionRequestedComment, ExtensionCompletedComment:
+		jitRequest.Spec.Extension = &justintimev1.ExtensionSpec{EndTime: now, Reason: "sample"}
+		jitRequest.Status.Extension = &justintimev1.ExtensionStatus{State: justintimev1.ExtensionPending, EndTime: now}
+	}
+
+	return &Data{
+		JitRequest:  jitRequest,
+		Environment: &justintimev1.EnvironmentSpec{Environment: "sample", Cluster: "sample"},
+		Namespaces:  jitRequest.Spec.Namespaces,
+	}
+}
+
+// sampleData returns data with every optional field of a JitRequest set, to validate templates
+func sampleData() *Data {
+	now := metav1.Now()
+	jitRequest := &justintimev1.JitRequest{
+		ObjectMeta: metav1.ObjectMeta{Name: "sample"},
+		Spec: justintimev1.JitRequestSpec{
+			Reporter:           "reporter@example.com",
+			AdditionUserEmails: []string{"user@example.com"},
+			Subjects:           []justintimev1.SubjectSpec{{Kind: "Group", Name: "sample"}},
+			ClusterRole:        "edit",
+			Namespaces:         []string{"sample"},
+			StartTime:          now,
+			EndTime:            now,
+			Duration:           &metav1.Duration{Duration: time.Hour},
+			JiraFields:         map[string]string{},
+			Revocation:         &justintimev1.RevocationSpec{RevokedBy: "admin@example.com", Reason: "sample"},
+			Extension:          &justintimev1.ExtensionSpec{EndTime: now, Reason: "sample"},
+		},
+		Status: justintimev1.JitRequestStatus{
+			Message:        "sample",
+			JiraTicket:     "IAM-1",
+			StartTime:      now,
+			EndTime:        now,
+			Extension:      &justintimev1.ExtensionStatus{State: justintimev1.ExtensionPending, EndTime: now},
+			CompletionTime: &now,
+		},
+	}
+	return &Data{
+		JitRequest:         jitRequest,
+		Environment:        &justintimev1.EnvironmentSpec{Environment: "sample", Cluster: "sample"},
+		Namespaces:         jitRequest.Spec.Namespaces,
+		Message:            "sample",
+		AdditionalComments: "sample",
+	}
+}
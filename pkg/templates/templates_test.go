@@ -0,0 +1,159 @@
+package templates
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	justintimev1 "jira-jit-rbac-operator/api/v1"
+)
+
+var _ = Describe("Templates", func() {
+	var operatorConfig *justintimev1.JustInTimeConfigSpec
+	var jitRequest *justintimev1.JitRequest
+
+	endTime := metav1.NewTime(time.Date(2024, 12, 4, 22, 0, 0, 0, time.UTC))
+	extendedTime := metav1.NewTime(time.Date(2024, 12, 4, 23, 0, 0, 0, time.UTC))
+
+	BeforeEach(func() {
+		operatorConfig = &justintimev1.JustInTimeConfigSpec{
+			Environment:           &justintimev1.EnvironmentSpec{Environment: "dev-test", Cluster: "minikube"},
+			AdditionalCommentText: "config: default",
+		}
+		jitRequest = &justintimev1.JitRequest{
+			Spec: justintimev1.JitRequestSpec{
+				Reporter:    "master-chief@unsc.com",
+				ClusterRole: "edit",
+				Namespaces:  []string{"reach", "harvest"},
+			},
+			Status: justintimev1.JitRequestStatus{
+				EndTime: endTime,
+			},
+		}
+	})
+
+	Describe("Render", func() {
+		It("should render the default summary and no description", func() {
+			summary, err := New(operatorConfig).Render(Summary, jitRequest, "")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(summary).To(Equal("Automated JIT request for master-chief@unsc.com"))
+
+			description, err := New(operatorConfig).Render(Description, jitRequest, "")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(description).To(BeEmpty())
+		})
+
+		It("should render the default pre-approval comment", func() {
+			comment, err := New(operatorConfig).Render(PreApprovedComment, jitRequest, "Pre-approval")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(comment).To(Equal("{color:#00875a}*Pre-approval*{color}" +
+				"\n|*Namespace(s)*|reach\nharvest|" +
+				"\n|*User*|master-chief@unsc.com|" +
+				"\n\n*Additional Info:*\nconfig: default"))
+		})
+
+		It("should render the default pre-approval comment for a cluster scoped request with subjects", func() {
+			jitRequest.Spec.ClusterScoped = true
+			jitRequest.Spec.AdditionUserEmails = []string{"cortana@unsc.com", "johnson@unsc.com"}
+			jitRequest.Spec.Subjects = []justintimev1.SubjectSpec{
+				{Kind: "Group", Name: "spartans"},
+				{Kind: "ServiceAccount", Namespace: "reach", Name: "pelican"},
+			}
+			operatorConfig.AdditionalCommentText = ""
+
+			comment, err := New(operatorConfig).Render(PreApprovedComment, jitRequest, "Pre-approval")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(comment).To(Equal("{color:#00875a}*Pre-approval*{color}" +
+				"\n|*Scope*|Cluster-wide|" +
+				"\n|*User*|master-chief@unsc.com|" +
+				"\n|*Additional Users*|cortana@unsc.com\njohnson@unsc.com|" +
+				"\n|*Subjects*|Group: spartans\nServiceAccount: reach/pelican|"))
+		})
+
+		It("should render the default lifecycle comments", func() {
+			jitRequest.Spec.Revocation = &justintimev1.RevocationSpec{RevokedBy: "cortana@unsc.com", Reason: "incident resolved"}
+			jitRequest.Spec.Extension = &justintimev1.ExtensionSpec{EndTime: extendedTime, Reason: "more time"}
+			jitRequest.Status.Extension = &justintimev1.ExtensionStatus{State: justintimev1.ExtensionApproved, EndTime: extendedTime}
+			completionTime := metav1.NewTime(endTime.Add(30 * time.Second))
+			jitRequest.Status.CompletionTime = &completionTime
+			jiraTemplates := New(operatorConfig)
+
+			comments := map[string]string{
+				CompletedComment: "{color:#00875a}*Completed - Access granted until end time*{color}",
+				RejectedComment:  "{color:#de350b}*Rejected - no approval*{color}",
+				RevokedComment: "{color:#de350b}*Revoked - Access has been revoked before end time*{color}" +
+					"\n|*Revoked By*|cortana@unsc.com|" +
+					"\n|*Reason*|incident resolved|",
+				ExpiredComment: "{color:#97a0af}*Expired - Access has been revoked at end time*{color}" +
+					"\n|*End Time*|2024-12-04T22:00:00Z|" +
+					"\n|*Revoked At*|2024-12-04T22:00:30Z|",
+				ExtensionRequestedComment: "{color:#ff991f}*Extension requested - Access will be extended pending human approval(s)*{color}" +
+					"\n|*Current End Time*|2024-12-04T22:00:00Z|" +
+					"\n|*Requested End Time*|2024-12-04T23:00:00Z|" +
+					"\n|*Reason*|more time|",
+				ExtensionCompletedComment: "{color:#00875a}*Completed - Access extended until 2024-12-04T23:00:00Z*{color}",
+			}
+			for name, expected := range comments {
+				comment, err := jiraTemplates.Render(name, jitRequest, "no approval")
+				Expect(err).NotTo(HaveOccurred(), name)
+				Expect(comment).To(Equal(expected), name)
+			}
+		})
+
+		It("should render a template from the config", func() {
+			operatorConfig.Templates = &justintimev1.JiraTemplatesSpec{
+				Summary:     "[{{ .Environment.Environment }}] {{ .JitRequest.Spec.ClusterRole }} for {{ .JitRequest.Spec.Reporter }}",
+				Description: "Namespaces: {{ join .Namespaces \", \" }} on {{ .Environment.Cluster }}",
+			}
+			jiraTemplates := New(operatorConfig)
+
+			summary, err := jiraTemplates.Render(Summary, jitRequest, "")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(summary).To(Equal("[dev-test] edit for master-chief@unsc.com"))
+
+			description, err := jiraTemplates.Render(Description, jitRequest, "")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(description).To(Equal("Namespaces: reach, harvest on minikube"))
+
+			comment, err := jiraTemplates.Render(CompletedComment, jitRequest, "")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(comment).To(Equal(Defaults[CompletedComment]))
+		})
+	})
+
+	Describe("Validate", func() {
+		It("should accept the defaults and valid templates", func() {
+			Expect(Validate(nil)).To(Succeed())
+			Expect(Validate(&justintimev1.JiraTemplatesSpec{
+				RevokedComment: "Revoked by {{ .JitRequest.Spec.Revocation.RevokedBy }} at {{ formatTime .JitRequest.Status.CompletionTime }}",
+			})).To(Succeed())
+		})
+
+		It("should return an error for a template that does not parse", func() {
+			err := Validate(&justintimev1.JiraTemplatesSpec{Summary: "JIT for {{ .JitRequest.Spec.Reporter"})
+			Expect(err).To(MatchError(ContainSubstring("invalid summary template")))
+		})
+
+		It("should return an error for a template using a field that is not set for its event", func() {
+			err := Validate(&justintimev1.JiraTemplatesSpec{CompletedComment: "Revoked by {{ .JitRequest.Spec.Revocation.RevokedBy }}"})
+			Expect(err).To(MatchError(ContainSubstring("failed to render completedComment template")))
+
+			err = Validate(&justintimev1.JiraTemplatesSpec{PreApprovedComment: "Until {{ formatTime .JitRequest.Status.Extension.EndTime }}"})
+			Expect(err).To(MatchError(ContainSubstring("failed to render preApprovedComment template")))
+		})
+
+		It("should accept a template guarding an optional field", func() {
+			Expect(Validate(&justintimev1.JiraTemplatesSpec{
+				CompletedComment: "{{ with .JitRequest.Spec.Revocation }}Revoked by {{ .RevokedBy }}{{ end }}",
+				RevokedComment:   "Revoked by {{ .JitRequest.Spec.Revocation.RevokedBy }}",
+			})).To(Succeed())
+		})
+
+		It("should return an error for a template that does not render", func() {
+			err := Validate(&justintimev1.JiraTemplatesSpec{CompletedComment: "{{ .JitRequest.Spec.Approver }}"})
+			Expect(err).To(MatchError(ContainSubstring("failed to render completedComment template")))
+		})
+	})
+})
@@ -0,0 +1,100 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"strings"
+	"sync"
+	"time"
+
+	jira "github.com/ctreminiom/go-atlassian/v2/jira/v2"
+)
+
+// DefaultUserCacheTTL is how long a Jira user found by email is cached if not set by flag
+const DefaultUserCacheTTL = 10 * time.Minute
+
+// userCache caches the Jira users found by GetNameByEmail, shared by the controller and webhook
+var userCache = &ttlCache{ttl: DefaultUserCacheTTL, entries: map[string]cacheEntry{}, now: time.Now}
+
+// SetUserCacheTTL sets how long Jira users are cached and clears the cache, 0 disables caching
+func SetUserCacheTTL(ttl time.Duration) {
+	userCache.mu.Lock()
+	defer userCache.mu.Unlock()
+	userCache.ttl = ttl
+	userCache.entries = map[string]cacheEntry{}
+}
+
+// ClearUserCache removes all cached Jira users, users found with previous Jira credentials may not be the same on a new base url
+func ClearUserCache() {
+	userCache.mu.Lock()
+	defer userCache.mu.Unlock()
+	userCache.entries = map[string]cacheEntry{}
+}
+
+// userCacheKey returns the cache key of an email, emails are case insensitive. The site is the base url the
+// client was created with, requests are sent to the current base url, so the cache is cleared when it changes.
+func userCacheKey(jiraClient *jira.Client, flavour JiraFlavour, email string) string {
+	site := ""
+	if jiraClient.Site != nil {
+		site = jiraClient.Site.String()
+	}
+	return strings.Join([]string{site, string(flavour), strings.ToLower(strings.TrimSpace(email))}, "|")
+}
+
+type cacheEntry struct {
+	value   string
+	expires time.Time
+}
+
+// ttlCache is a map of values that expire after the ttl, only successful lookups are cached
+type ttlCache struct {
+	mu      sync.Mutex
+	ttl     time.Duration
+	entries map[string]cacheEntry
+	now     func() time.Time
+}
+
+// get returns a value that has not expired
+func (c *ttlCache) get(key string) (string, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry, ok := c.entries[key]
+	if !ok {
+		return "", false
+	}
+	if !c.now().Before(entry.expires) {
+		delete(c.entries, key)
+		return "", false
+	}
+	return entry.value, true
+}
+
+// set caches a value for the ttl, removing expired entries so the cache does not grow unbounded
+func (c *ttlCache) set(key, value string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.ttl <= 0 {
+		return
+	}
+	now := c.now()
+	for k, entry := range c.entries {
+		if !now.Before(entry.expires) {
+			delete(c.entries, k)
+		}
+	}
+	c.entries[key] = cacheEntry{value: value, expires: now.Add(c.ttl)}
+}
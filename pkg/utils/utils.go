@@ -19,17 +19,27 @@ package utils
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	justintimev1 "jira-jit-rbac-operator/api/v1"
 	"net/http"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
 	"strings"
+	"time"
 
 	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
 
 	"jira-jit-rbac-operator/internal/config"
+	"jira-jit-rbac-operator/pkg/resilience"
 	"os"
 
 	jira "github.com/ctreminiom/go-atlassian/v2/jira/v2"
+	"github.com/ctreminiom/go-atlassian/v2/pkg/infra/models"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/util/validation/field"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -64,6 +74,14 @@ func Contains(slice []string, item string) bool {
 	return false
 }
 
+// AllowedClusterRoles returns the allowed cluster roles for a JitRequest, cluster scoped requests use their own allow-list
+func AllowedClusterRoles(operatorConfig *justintimev1.JustInTimeConfigSpec, jitRequest *justintimev1.JitRequest) []string {
+	if jitRequest.Spec.ClusterScoped {
+		return operatorConfig.AllowedClusterScopedRoles
+	}
+	return operatorConfig.AllowedClusterRoles
+}
+
 // ValidateNamespaceRegex validates namespace name with regex if provided
 func ValidateNamespaceRegex(namespaces []string) (string, error) {
 	if config.NamespaceAllowedRegex != nil {
@@ -80,6 +98,229 @@ func ValidateNamespaceRegex(namespaces []string) (string, error) {
 	return "", nil
 }
 
+// ValidateSubjects validates the typed subjects of a JitRequest
+func ValidateSubjects(subjects []justintimev1.SubjectSpec) *field.Error {
+	for i, subject := range subjects {
+		subjectPath := field.NewPath("spec").Child("subjects").Index(i)
+
+		if subject.Name == "" {
+			return field.Required(subjectPath.Child("name"), "subject name is required")
+		}
+
+		switch subject.Kind {
+		case rbacv1.ServiceAccountKind:
+			if subject.Namespace == "" {
+				return field.Required(subjectPath.Child("namespace"), "namespace is required for ServiceAccount subjects")
+			}
+		case rbacv1.UserKind, rbacv1.GroupKind:
+			if subject.Namespace != "" {
+				msg := fmt.Sprintf("namespace must not be set for %s subjects", subject.Kind)
+				return field.Invalid(subjectPath.Child("namespace"), subject.Namespace, msg)
+			}
+		default:
+			return field.NotSupported(subjectPath.Child("kind"), subject.Kind,
+				[]string{rbacv1.UserKind, rbacv1.GroupKind, rbacv1.ServiceAccountKind})
+		}
+	}
+	return nil
+}
+
+// RequestedTimes returns the start and end time of a JitRequest, startOnApproval requests start at the given time
+func RequestedTimes(jitRequest *justintimev1.JitRequest, now time.Time) (time.Time, time.Time) {
+	startTime := jitRequest.Spec.StartTime.Time
+	if jitRequest.Spec.StartOnApproval {
+		startTime = now
+	}
+
+	endTime := jitRequest.Spec.EndTime.Time
+	if jitRequest.Spec.Duration != nil {
+		endTime = startTime.Add(jitRequest.Spec.Duration.Duration)
+	}
+	return startTime, endTime
+}
+
+// ValidateTimes validates the start time, end time and duration of a JitRequest
+func ValidateTimes(jitRequest *justintimev1.JitRequest, now time.Time) *field.Error {
+	specPath := field.NewPath("spec")
+	spec := jitRequest.Spec
+
+	// check exactly one of endTime or duration is set
+	if spec.Duration != nil && !spec.EndTime.IsZero() {
+		return field.Invalid(specPath.Child("duration"), spec.Duration, "duration and endTime are mutually exclusive")
+	}
+	if spec.Duration == nil && spec.EndTime.IsZero() {
+		return field.Required(specPath.Child("endTime"), "one of endTime or duration is required")
+	}
+	if spec.Duration != nil && spec.Duration.Duration <= 0 {
+		return field.Invalid(specPath.Child("duration"), spec.Duration, "duration must be greater than 0")
+	}
+
+	// check startTime is after current time, or not set if starting on approval
+	if spec.StartOnApproval {
+		if !spec.StartTime.IsZero() {
+			return field.Invalid(specPath.Child("startTime"), spec.StartTime, "startTime must not be set with startOnApproval")
+		}
+	} else if !spec.StartTime.After(now) {
+		return field.Invalid(specPath.Child("startTime"), spec.StartTime, "start time must be after current time")
+	}
+
+	// check endTime is after startTime
+	startTime, endTime := RequestedTimes(jitRequest, now)
+	if !endTime.After(startTime) {
+		msg := fmt.Sprintf("end time must be after startTime '%s'", startTime)
+		return field.Invalid(specPath.Child("endTime"), spec.EndTime, msg)
+	}
+	return nil
+}
+
+// RolePolicy returns the policy for a cluster role, nil if none is configured
+func RolePolicy(operatorConfig *justintimev1.JustInTimeConfigSpec, clusterRole string) *justintimev1.RolePolicySpec {
+	policy, exists := operatorConfig.RolePolicies[clusterRole]
+	if !exists {
+		return nil
+	}
+	return &policy
+}
+
+// RequiredApprovals returns the number of distinct approvers required for a cluster role, 1 if not set in its policy
+func RequiredApprovals(operatorConfig *justintimev1.JustInTimeConfigSpec, clusterRole string) int {
+	if policy := RolePolicy(operatorConfig, clusterRole); policy != nil && policy.RequiredApprovals > 0 {
+		return policy.RequiredApprovals
+	}
+	return 1
+}
+
+// RejectedStatuses returns the configured statuses a Jira ticket is rejected, declined or closed in
+func RejectedStatuses(operatorConfig *justintimev1.JustInTimeConfigSpec) []string {
+	var statuses []string
+	if operatorConfig.JiraWorkflowRejectedStatus != "" {
+		statuses = append(statuses, operatorConfig.JiraWorkflowRejectedStatus)
+	}
+	for _, status := range operatorConfig.JiraWorkflowRejectedStatuses {
+		if status != "" {
+			statuses = append(statuses, status)
+		}
+	}
+	return statuses
+}
+
+// LinkedIssueProjects returns the Jira projects an existing issue can be linked from, the jiraProject if not set
+func LinkedIssueProjects(operatorConfig *justintimev1.JustInTimeConfigSpec) []string {
+	if len(operatorConfig.LinkedIssueProjects) > 0 {
+		return operatorConfig.LinkedIssueProjects
+	}
+	return []string{operatorConfig.JiraProject}
+}
+
+// ValidateRolePolicy validates a JitRequest against the policy for its cluster role, lead time is measured from requestedAt
+func ValidateRolePolicy(ctx context.Context, jitRequest *justintimev1.JitRequest, operatorConfig *justintimev1.JustInTimeConfigSpec, k8sClient client.Client, requestedAt time.Time) (*field.Error, error) { //nolint:lll
+	clusterRole := jitRequest.Spec.ClusterRole
+	policy := RolePolicy(operatorConfig, clusterRole)
+	if policy == nil {
+		return nil, nil
+	}
+	specPath := field.NewPath("spec")
+	spec := jitRequest.Spec
+
+	// check access does not exceed max duration
+	startTime, endTime := RequestedTimes(jitRequest, requestedAt)
+	if policy.MaxDuration != nil && endTime.Sub(startTime) > policy.MaxDuration.Duration {
+		msg := fmt.Sprintf("duration must not exceed '%s' for clusterRole '%s'", policy.MaxDuration.Duration, clusterRole)
+		if spec.Duration != nil {
+			return field.Invalid(specPath.Child("duration"), spec.Duration, msg), nil
+		}
+		return field.Invalid(specPath.Child("endTime"), spec.EndTime, msg), nil
+	}
+
+	// check start time is not too far ahead
+	if policy.MaxLeadTime != nil && startTime.Sub(requestedAt) > policy.MaxLeadTime.Duration {
+		msg := fmt.Sprintf("start time must be within '%s' of the request for clusterRole '%s'", policy.MaxLeadTime.Duration, clusterRole)
+		return field.Invalid(specPath.Child("startTime"), spec.StartTime, msg), nil
+	}
+
+	// check required jira fields are set
+	for _, fieldName := range policy.RequiredJiraFields {
+		if spec.JiraFields[fieldName] == "" {
+			msg := fmt.Sprintf("jira field '%s' is required for clusterRole '%s'", fieldName, clusterRole)
+			return field.Required(specPath.Child("jiraFields").Key(fieldName), msg), nil
+		}
+	}
+
+	// namespace rules do not apply to cluster scoped requests
+	if spec.ClusterScoped {
+		return nil, nil
+	}
+
+	// check each namespace matches at least one of the regexes
+	if len(policy.NamespaceAllowedRegexes) > 0 {
+		for i, namespace := range spec.Namespaces {
+			matched, err := matchesAnyRegex(policy.NamespaceAllowedRegexes, namespace)
+			if err != nil {
+				return nil, err
+			}
+			if !matched {
+				msg := fmt.Sprintf("namespace does not match the allowed patterns for clusterRole '%s': %s",
+					clusterRole, strings.Join(policy.NamespaceAllowedRegexes, ", "))
+				return field.Invalid(specPath.Child("namespaces").Index(i), namespace, msg), nil
+			}
+		}
+	}
+
+	// check each namespace matches the selector
+	if policy.NamespaceSelector != nil {
+		selector, err := metav1.LabelSelectorAsSelector(policy.NamespaceSelector)
+		if err != nil {
+			return nil, fmt.Errorf("invalid namespaceSelector for clusterRole '%s': %w", clusterRole, err)
+		}
+		namespaceList := &corev1.NamespaceList{}
+		if err := k8sClient.List(ctx, namespaceList, &client.ListOptions{LabelSelector: selector}); err != nil {
+			return nil, fmt.Errorf("failed to list namespaces: %v", err)
+		}
+		validNamespaces := make(map[string]struct{})
+		for _, ns := range namespaceList.Items {
+			validNamespaces[ns.Name] = struct{}{}
+		}
+		for i, namespace := range spec.Namespaces {
+			if _, found := validNamespaces[namespace]; !found {
+				msg := fmt.Sprintf("namespace does not match the namespaceSelector (%s) for clusterRole '%s'", selector, clusterRole)
+				return field.Invalid(specPath.Child("namespaces").Index(i), namespace, msg), nil
+			}
+		}
+	}
+
+	return nil, nil
+}
+
+// ValidateExtensionPolicy validates an extension does not take access beyond the max duration for the cluster role
+func ValidateExtensionPolicy(operatorConfig *justintimev1.JustInTimeConfigSpec, jitRequest *justintimev1.JitRequest) *field.Error {
+	clusterRole := jitRequest.Spec.ClusterRole
+	policy := RolePolicy(operatorConfig, clusterRole)
+	if policy == nil || policy.MaxDuration == nil {
+		return nil
+	}
+
+	extensionEndTime := jitRequest.Spec.Extension.EndTime
+	if extensionEndTime.Sub(jitRequest.Status.StartTime.Time) > policy.MaxDuration.Duration {
+		msg := fmt.Sprintf("total duration must not exceed '%s' for clusterRole '%s'", policy.MaxDuration.Duration, clusterRole)
+		return field.Invalid(field.NewPath("spec").Child("extension").Child("endTime"), extensionEndTime, msg)
+	}
+	return nil
+}
+
+// matchesAnyRegex checks if a string matches at least one of the regexes
+func matchesAnyRegex(regexes []string, s string) (bool, error) {
+	for _, expr := range regexes {
+		matched, err := regexp.MatchString(expr, s)
+		if err != nil {
+			return false, fmt.Errorf("invalid regex '%s': %w", expr, err)
+		}
+		if matched {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
 // ValidateNamespaceLabels validates namespace(s) have namespaceLabels
 func ValidateNamespaceLabels(ctx context.Context, jitRequest *justintimev1.JitRequest, k8sClient client.Client) ([]string, error) { //nolint:lll
 
@@ -128,16 +369,215 @@ func ValidateNamespaceLabels(ctx context.Context, jitRequest *justintimev1.JitRe
 	return nil, nil
 }
 
-// GetNameByEmail gets and returns ID for as Jira user by email - gets the 1st result
-func GetNameByEmail(email string, jiraClient *jira.Client) (string, error) {
+// JiraFlavour is the type of Jira deployment, which changes how users are searched and referenced
+type JiraFlavour string
+
+const (
+	// JiraServer is Jira Server/Data Center, users are referenced by name
+	JiraServer JiraFlavour = "server"
+	// JiraCloud is Jira Cloud, users are referenced by accountId
+	JiraCloud JiraFlavour = "cloud"
+)
+
+// ParseJiraFlavour returns the JiraFlavour for a value, defaulting to JiraServer if empty
+func ParseJiraFlavour(value string) (JiraFlavour, error) {
+	switch JiraFlavour(strings.ToLower(value)) {
+	case "", JiraServer:
+		return JiraServer, nil
+	case JiraCloud:
+		return JiraCloud, nil
+	default:
+		return "", fmt.Errorf("unknown jira flavour '%s', must be one of: %s, %s", value, JiraServer, JiraCloud)
+	}
+}
+
+// JiraUser returns a reference to a Jira user by name or accountId for the Jira flavour
+func JiraUser(flavour JiraFlavour, id string) *models.UserScheme {
+	if flavour == JiraCloud {
+		return &models.UserScheme{AccountID: id}
+	}
+	return &models.UserScheme{Name: id}
+}
+
+// JiraUserField returns the value of a user custom field by name or accountId for the Jira flavour
+func JiraUserField(flavour JiraFlavour, id string) map[string]interface{} {
+	if flavour == JiraCloud {
+		return map[string]interface{}{"accountId": id}
+	}
+	return map[string]interface{}{"name": id}
+}
+
+// MatchJiraTransition returns the ID of the transition matching an ID or name (case-insensitive),
+// or an error listing the available transitions if none match
+func MatchJiraTransition(transitions []*models.IssueTransitionScheme, transition string) (string, error) {
+	for _, t := range transitions {
+		if t.ID == transition {
+			return t.ID, nil
+		}
+	}
+	for _, t := range transitions {
+		if strings.EqualFold(t.Name, transition) {
+			return t.ID, nil
+		}
+	}
+
+	available := make([]string, 0, len(transitions))
+	for _, t := range transitions {
+		available = append(available, fmt.Sprintf("%s (%s)", t.Name, t.ID))
+	}
+	if len(available) == 0 {
+		return "", fmt.Errorf("transition '%s' is not available, no transitions are available from the current status", transition)
+	}
+	return "", fmt.Errorf("transition '%s' is not available from the current status, available transitions: %s", transition, strings.Join(available, ", "))
+}
+
+// Layouts of Jira date and datetime field values
+const (
+	JiraDateLayout     = "2006-01-02"
+	JiraDateTimeLayout = "2006-01-02T15:04:05.000-0700"
+)
+
+// JiraStatusCategoryDone is the key of the status category of done, closed and resolved statuses
+const JiraStatusCategoryDone = "done"
+
+// LoadTimezone returns the location of an IANA timezone, defaulting to the local timezone if empty
+func LoadTimezone(timezone string) (*time.Location, error) {
+	if timezone == "" {
+		return time.Local, nil
+	}
+	location, err := time.LoadLocation(timezone)
+	if err != nil {
+		return nil, fmt.Errorf("invalid timezone '%s': %w", timezone, err)
+	}
+	return location, nil
+}
+
+// FormatJiraTime formats a time in a location for a Jira field type, a date field only has the date
+func FormatJiraTime(t time.Time, fieldType string, location *time.Location) string {
+	t = t.In(location)
+	if fieldType == justintimev1.FieldTypeDate {
+		return t.Format(JiraDateLayout)
+	}
+	return t.Format(JiraDateTimeLayout)
+}
+
+// SplitFieldValues returns the comma separated values of a multi-value field, ignoring empty values
+func SplitFieldValues(value string) []string {
+	values := []string{}
+	for _, v := range strings.Split(value, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			values = append(values, v)
+		}
+	}
+	return values
+}
+
+// JiraFieldValue returns the value of a Jira field in a create payload for a field type,
+// or an error if the value is not valid for the type
+func JiraFieldValue(flavour JiraFlavour, fieldType, value string) (interface{}, error) {
+	switch fieldType {
+	case justintimev1.FieldTypeText:
+		return value, nil
+	case justintimev1.FieldTypeDate:
+		if _, err := time.Parse(JiraDateLayout, value); err != nil {
+			return nil, fmt.Errorf("invalid date '%s', must be formatted as %s", value, JiraDateLayout)
+		}
+		return value, nil
+	case justintimev1.FieldTypeDateTime:
+		// accept RFC3339 as well as the Jira format, keeping the offset
+		for _, layout := range []string{JiraDateTimeLayout, time.RFC3339} {
+			if t, err := time.Parse(layout, value); err == nil {
+				return t.Format(JiraDateTimeLayout), nil
+			}
+		}
+		return nil, fmt.Errorf("invalid datetime '%s', must be formatted as RFC3339", value)
+	case justintimev1.FieldTypeSelect:
+		return map[string]interface{}{"value": value}, nil
+	case justintimev1.FieldTypeMultiSelect:
+		options := []map[string]interface{}{}
+		for _, option := range SplitFieldValues(value) {
+			options = append(options, map[string]interface{}{"value": option})
+		}
+		return options, nil
+	case justintimev1.FieldTypeUser:
+		return JiraUserField(flavour, value), nil
+	case justintimev1.FieldTypeMultiUser:
+		users := []map[string]interface{}{}
+		for _, user := range SplitFieldValues(value) {
+			users = append(users, JiraUserField(flavour, user))
+		}
+		return users, nil
+	case justintimev1.FieldTypeNumber:
+		number, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid number '%s'", value)
+		}
+		return number, nil
+	case justintimev1.FieldTypeLabels:
+		labels := SplitFieldValues(value)
+		for _, label := range labels {
+			if strings.ContainsAny(label, " \t\n") {
+				return nil, fmt.Errorf("invalid label '%s', labels cannot contain spaces", label)
+			}
+		}
+		return labels, nil
+	case justintimev1.FieldTypeCascadingSelect:
+		parent, child, hasChild := strings.Cut(value, ":")
+		parent, child = strings.TrimSpace(parent), strings.TrimSpace(child)
+		if parent == "" || (hasChild && child == "") {
+			return nil, fmt.Errorf("invalid cascading select '%s', must be formatted as parent:child", value)
+		}
+		option := map[string]interface{}{"value": parent}
+		if hasChild {
+			option["child"] = map[string]interface{}{"value": child}
+		}
+		return option, nil
+	case justintimev1.FieldTypeURL:
+		u, err := url.ParseRequestURI(value)
+		if err != nil || u.Scheme == "" || u.Host == "" {
+			return nil, fmt.Errorf("invalid url '%s'", value)
+		}
+		return value, nil
+	case justintimev1.FieldTypeGroup:
+		return map[string]interface{}{"name": value}, nil
+	case justintimev1.FieldTypeMultiGroup:
+		groups := []map[string]interface{}{}
+		for _, group := range SplitFieldValues(value) {
+			groups = append(groups, map[string]interface{}{"name": group})
+		}
+		return groups, nil
+	default:
+		return nil, fmt.Errorf("unknown custom field type '%s'", fieldType)
+	}
+}
+
+// Errors of GetNameByEmail when no single Jira user has exactly the email
+var (
+	ErrUserNotFound  = errors.New("no users found with email")
+	ErrAmbiguousUser = errors.New("multiple users found with email")
+)
+
+// GetNameByEmail gets and returns ID for the Jira user with exactly the email, users are cached for the UserCacheTTL
+// Jira Server returns the user name, Jira Cloud returns the accountId
+func GetNameByEmail(ctx context.Context, email string, jiraClient *jira.Client, flavour JiraFlavour) (string, error) {
+	key := userCacheKey(jiraClient, flavour, email)
+	if accountId, ok := userCache.get(key); ok {
+		return accountId, nil
+	}
 
 	type User struct {
-		Name string `json:"name"`
+		Name         string `json:"name"`
+		AccountID    string `json:"accountId"`
+		EmailAddress string `json:"emailAddress"`
 	}
 
-	// RAW endpoint
-	apiEndpoint := fmt.Sprintf("rest/api/2/user/search?username=%s", email)
-	request, err := jiraClient.NewRequest(context.Background(), http.MethodGet, apiEndpoint, "", nil)
+	// RAW endpoint, Jira Cloud has no usernames and searches by query
+	searchParam := "username"
+	if flavour == JiraCloud {
+		searchParam = "query"
+	}
+	apiEndpoint := fmt.Sprintf("rest/api/2/user/search?%s=%s", searchParam, url.QueryEscape(email))
+	request, err := jiraClient.NewRequest(ctx, http.MethodGet, apiEndpoint, "", nil)
 	if err != nil {
 		return "", fmt.Errorf("failed to find account name for reporter email: %w", err)
 	}
@@ -153,12 +593,393 @@ func GetNameByEmail(email string, jiraClient *jira.Client) (string, error) {
 		}
 	}
 
-	// check if any users were found
-	if len(users) == 0 {
-		return "", fmt.Errorf("no users found with email: %s", email)
+	// the search also matches names and partial emails, only a user with exactly the email can be used
+	var matches, hidden []User
+	for _, user := range users {
+		if strings.EqualFold(strings.TrimSpace(user.EmailAddress), strings.TrimSpace(email)) {
+			matches = append(matches, user)
+		} else if user.EmailAddress == "" {
+			hidden = append(hidden, user)
+		}
+	}
+	// Jira Cloud hides the email by default, a user with a hidden email is only used once its email is confirmed
+	if flavour == JiraCloud {
+		for _, user := range hidden {
+			confirmed, err := getCloudUserEmail(ctx, user.AccountID, jiraClient)
+			if resilience.IsUnavailable(err) {
+				return "", fmt.Errorf("failed to confirm the email of account %s: %w", user.AccountID, err)
+			}
+			if err == nil && strings.EqualFold(strings.TrimSpace(confirmed), strings.TrimSpace(email)) {
+				matches = append(matches, user)
+			}
+		}
+	}
+	switch {
+	case len(users) == 0:
+		return "", fmt.Errorf("%w: %s", ErrUserNotFound, email)
+	case len(matches) == 0 && len(hidden) > 0:
+		return "", fmt.Errorf("%w: %s, the email of %d found users is hidden and could not be confirmed", ErrUserNotFound, email, len(hidden))
+	case len(matches) == 0:
+		return "", fmt.Errorf("%w: %s, the search only found users with other emails", ErrUserNotFound, email)
+	case len(matches) > 1:
+		return "", fmt.Errorf("%w: %s, %d users match", ErrAmbiguousUser, email, len(matches))
 	}
 
-	// get the account name
-	accountId := users[0].Name
+	// get the account name or ID
+	accountId := matches[0].Name
+	if flavour == JiraCloud {
+		accountId = matches[0].AccountID
+	}
+	if accountId == "" {
+		return "", fmt.Errorf("no %s user found with email: %s", flavour, email)
+	}
+	userCache.set(key, accountId)
 	return accountId, nil
 }
+
+// getCloudUserEmail returns the email of a Jira Cloud account, which is returned even if hidden from searches
+// as long as the operator's user may read emails
+func getCloudUserEmail(ctx context.Context, accountID string, jiraClient *jira.Client) (string, error) {
+	apiEndpoint := fmt.Sprintf("rest/api/3/user/email?accountId=%s", url.QueryEscape(accountID))
+	request, err := jiraClient.NewRequest(ctx, http.MethodGet, apiEndpoint, "", nil)
+	if err != nil {
+		return "", err
+	}
+
+	var user struct {
+		Email string `json:"email"`
+	}
+	if _, err := jiraClient.Call(request, &user); err != nil {
+		return "", err
+	}
+	return user.Email, nil
+}
+
+// CreateCustomerRequest creates a Jira Service Management customer request on behalf of a user, referenced by name or accountId,
+// the field values are keyed by field ID. Returns the issue key of the request.
+func CreateCustomerRequest(ctx context.Context, serviceDesk *justintimev1.ServiceDeskSpec, raiseOnBehalfOf string, fieldValues map[string]interface{}, jiraClient *jira.Client) (string, error) {
+
+	type CustomerRequest struct {
+		ServiceDeskID      string                 `json:"serviceDeskId"`
+		RequestTypeID      string                 `json:"requestTypeId"`
+		RequestFieldValues map[string]interface{} `json:"requestFieldValues"`
+		RaiseOnBehalfOf    string                 `json:"raiseOnBehalfOf,omitempty"`
+	}
+
+	type CreatedRequest struct {
+		IssueKey string `json:"issueKey"`
+	}
+
+	payload := &CustomerRequest{
+		ServiceDeskID:      serviceDesk.ServiceDeskID,
+		RequestTypeID:      serviceDesk.RequestTypeID,
+		RequestFieldValues: fieldValues,
+		RaiseOnBehalfOf:    raiseOnBehalfOf,
+	}
+	var result CreatedRequest
+	if err := callJira(ctx, jiraClient, http.MethodPost, "rest/servicedeskapi/request", payload, &result); err != nil {
+		return "", fmt.Errorf("failed to create customer request: %w", err)
+	}
+	if result.IssueKey == "" {
+		return "", fmt.Errorf("failed to create customer request: no issue key in response")
+	}
+	return result.IssueKey, nil
+}
+
+// JiraApproval is an approval of a Jira ticket by a user, ApprovedAt is zero if Jira has no time for it
+type JiraApproval struct {
+	// User name, or accountId on Jira Cloud
+	Approver    string
+	Email       string
+	DisplayName string
+	ApprovedAt  time.Time
+}
+
+// jiraApprovalUser is a user in the Jira Service Management approvals and changelog APIs
+type jiraApprovalUser struct {
+	Name         string `json:"name"`
+	AccountID    string `json:"accountId"`
+	EmailAddress string `json:"emailAddress"`
+	DisplayName  string `json:"displayName"`
+}
+
+// approval returns the approval by the user, referenced by name or accountId for the Jira flavour
+func (u *jiraApprovalUser) approval(flavour JiraFlavour, approvedAt time.Time) JiraApproval {
+	approver := u.Name
+	if flavour == JiraCloud {
+		approver = u.AccountID
+	}
+	return JiraApproval{
+		Approver:    approver,
+		Email:       u.EmailAddress,
+		DisplayName: u.DisplayName,
+		ApprovedAt:  approvedAt,
+	}
+}
+
+// GetServiceDeskApprovals returns the approvals of the Jira Service Management approvals of a ticket,
+// approvers are only timed once the approval is completed
+func GetServiceDeskApprovals(ctx context.Context, issueKey string, jiraClient *jira.Client, flavour JiraFlavour) ([]JiraApproval, error) {
+
+	type ServiceDeskApprovals struct {
+		Values []struct {
+			Approvers []struct {
+				Approver         jiraApprovalUser `json:"approver"`
+				ApproverDecision string           `json:"approverDecision"`
+			} `json:"approvers"`
+			CompletedDate *struct {
+				EpochMillis int64 `json:"epochMillis"`
+			} `json:"completedDate"`
+		} `json:"values"`
+	}
+
+	var result ServiceDeskApprovals
+	apiEndpoint := fmt.Sprintf("rest/servicedeskapi/request/%s/approval", url.PathEscape(issueKey))
+	if err := callJira(ctx, jiraClient, http.MethodGet, apiEndpoint, nil, &result); err != nil {
+		return nil, fmt.Errorf("failed to get service desk approvals: %w", err)
+	}
+
+	approvals := []JiraApproval{}
+	for _, value := range result.Values {
+		var approvedAt time.Time
+		if value.CompletedDate != nil {
+			approvedAt = time.UnixMilli(value.CompletedDate.EpochMillis)
+		}
+		for _, approver := range value.Approvers {
+			if approver.ApproverDecision != "approved" {
+				continue
+			}
+			approvals = append(approvals, approver.Approver.approval(flavour, approvedAt))
+		}
+	}
+	return approvals, nil
+}
+
+// changelogHistory is a change to a Jira ticket in its changelog
+type changelogHistory struct {
+	Author  jiraApprovalUser `json:"author"`
+	Created string           `json:"created"`
+	Items   []struct {
+		Field    string `json:"field"`
+		ToString string `json:"toString"`
+	} `json:"items"`
+}
+
+// GetChangelogApprovals returns the transitions of a ticket to the approved status in its changelog
+func GetChangelogApprovals(ctx context.Context, issueKey, approvedStatus string, jiraClient *jira.Client, flavour JiraFlavour) ([]JiraApproval, error) {
+	histories, err := getChangelog(ctx, issueKey, jiraClient, flavour)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get changelog: %w", err)
+	}
+
+	approvals := []JiraApproval{}
+	for _, history := range histories {
+		for _, item := range history.Items {
+			if item.Field != "status" || !strings.EqualFold(item.ToString, approvedStatus) {
+				continue
+			}
+			approvedAt, err := time.Parse(JiraDateTimeLayout, history.Created)
+			if err != nil {
+				return nil, fmt.Errorf("invalid changelog time '%s': %w", history.Created, err)
+			}
+			approvals = append(approvals, history.Author.approval(flavour, approvedAt))
+		}
+	}
+	return approvals, nil
+}
+
+// getChangelog returns the whole changelog of a ticket. Jira Cloud caps the expanded changelog at 100 histories,
+// so it is paged through until the last page, Jira Server has no changelog endpoint but expands all of it.
+func getChangelog(ctx context.Context, issueKey string, jiraClient *jira.Client, flavour JiraFlavour) ([]changelogHistory, error) {
+	if flavour != JiraCloud {
+		var result struct {
+			Changelog struct {
+				Histories []changelogHistory `json:"histories"`
+			} `json:"changelog"`
+		}
+		apiEndpoint := fmt.Sprintf("rest/api/2/issue/%s?fields=status&expand=changelog", url.PathEscape(issueKey))
+		if err := callJira(ctx, jiraClient, http.MethodGet, apiEndpoint, nil, &result); err != nil {
+			return nil, err
+		}
+		return result.Changelog.Histories, nil
+	}
+
+	var histories []changelogHistory
+	for {
+		var page struct {
+			IsLast bool               `json:"isLast"`
+			Values []changelogHistory `json:"values"`
+		}
+		apiEndpoint := fmt.Sprintf("rest/api/2/issue/%s/changelog?startAt=%d", url.PathEscape(issueKey), len(histories))
+		if err := callJira(ctx, jiraClient, http.MethodGet, apiEndpoint, nil, &page); err != nil {
+			return nil, err
+		}
+		histories = append(histories, page.Values...)
+		if page.IsLast || len(page.Values) == 0 {
+			return histories, nil
+		}
+	}
+}
+
+// DistinctApprovals returns the earliest approval of each distinct approver after since, excluding the excluded users by name,
+// accountId or email. Approvals Jira has no time for keep the time from the previous approvals, or now if they are new.
+func DistinctApprovals(approvals []JiraApproval, previous []justintimev1.Approval, excluded []string, since, now time.Time) []justintimev1.Approval {
+	seen := map[string]time.Time{}
+	for _, approval := range previous {
+		seen[approval.Approver] = approval.ApprovedAt.Time
+	}
+
+	distinct := map[string]justintimev1.Approval{}
+	for _, approval := range approvals {
+		if approval.Approver == "" || containsFold(excluded, approval.Approver) || containsFold(excluded, approval.Email) {
+			continue
+		}
+		approvedAt := approval.ApprovedAt
+		if approvedAt.IsZero() {
+			approvedAt = now
+			if t, ok := seen[approval.Approver]; ok {
+				approvedAt = t
+			}
+		}
+		if approvedAt.Before(since) {
+			continue
+		}
+		if existing, ok := distinct[approval.Approver]; ok && !approvedAt.Before(existing.ApprovedAt.Time) {
+			continue
+		}
+		distinct[approval.Approver] = justintimev1.Approval{
+			Approver:    approval.Approver,
+			DisplayName: approval.DisplayName,
+			ApprovedAt:  metav1.NewTime(approvedAt),
+		}
+	}
+
+	result := make([]justintimev1.Approval, 0, len(distinct))
+	for _, approval := range distinct {
+		result = append(result, approval)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if !result[i].ApprovedAt.Equal(&result[j].ApprovedAt) {
+			return result[i].ApprovedAt.Before(&result[j].ApprovedAt)
+		}
+		return result[i].Approver < result[j].Approver
+	})
+	return result
+}
+
+// ValidateLinkedIssue validates the existing Jira issue of a JitRequest is in an allowed project and still open
+func ValidateLinkedIssue(ctx context.Context, jitRequest *justintimev1.JitRequest, operatorConfig *justintimev1.JustInTimeConfigSpec, jiraClient *jira.Client) (*field.Error, error) { //nolint:lll
+	issueKey := jitRequest.Spec.LinkedJiraTicket
+	if issueKey == "" {
+		return nil, nil
+	}
+	linkedPath := field.NewPath("spec").Child("linkedJiraTicket")
+
+	type LinkedIssue struct {
+		Fields struct {
+			Project struct {
+				Key string `json:"key"`
+			} `json:"project"`
+			Status struct {
+				Name           string `json:"name"`
+				StatusCategory struct {
+					Key string `json:"key"`
+				} `json:"statusCategory"`
+			} `json:"status"`
+		} `json:"fields"`
+	}
+
+	var result LinkedIssue
+	apiEndpoint := fmt.Sprintf("rest/api/2/issue/%s?fields=project,status", url.PathEscape(issueKey))
+	if err := callJira(ctx, jiraClient, http.MethodGet, apiEndpoint, nil, &result); err != nil {
+		if errors.Is(err, models.ErrNotFound) {
+			return field.NotFound(linkedPath, issueKey), nil
+		}
+		return nil, fmt.Errorf("failed to get linked issue: %w", err)
+	}
+
+	// check the issue is in an allowed project
+	projects := LinkedIssueProjects(operatorConfig)
+	if !containsFold(projects, result.Fields.Project.Key) {
+		msg := fmt.Sprintf("linked issue must be in one of the projects '%s'", strings.Join(projects, ", "))
+		return field.Invalid(linkedPath, issueKey, msg), nil
+	}
+
+	// check the issue is not done, rejected, declined or closed
+	status := result.Fields.Status
+	if status.StatusCategory.Key == JiraStatusCategoryDone || containsFold(RejectedStatuses(operatorConfig), status.Name) {
+		msg := fmt.Sprintf("linked issue must be open, it is in status '%s'", status.Name)
+		return field.Invalid(linkedPath, issueKey, msg), nil
+	}
+	return nil, nil
+}
+
+// JiraTicketLabelPrefix is the prefix of the label tagging a Jira ticket with the UID of its JitRequest
+const JiraTicketLabelPrefix = "jit-request-"
+
+// JiraTicketLabel returns the label tagging the Jira ticket of a JitRequest, empty if the JitRequest has no UID yet
+func JiraTicketLabel(jitRequest *justintimev1.JitRequest) string {
+	if jitRequest.UID == "" {
+		return ""
+	}
+	return JiraTicketLabelPrefix + string(jitRequest.UID)
+}
+
+// FindJiraTicket returns the key of the oldest Jira ticket with the label, empty if there is none
+func FindJiraTicket(ctx context.Context, label string, jiraClient *jira.Client, flavour JiraFlavour) (string, error) {
+	if label == "" {
+		return "", nil
+	}
+
+	// Jira Cloud has replaced the search endpoint with search/jql
+	apiEndpoint := "rest/api/2/search"
+	if flavour == JiraCloud {
+		apiEndpoint = "rest/api/2/search/jql"
+	}
+	params := url.Values{}
+	params.Set("jql", fmt.Sprintf(`labels = "%s" ORDER BY created ASC`, label))
+	params.Set("fields", "summary")
+	params.Set("maxResults", "1")
+
+	var result struct {
+		Issues []struct {
+			Key string `json:"key"`
+		} `json:"issues"`
+	}
+	if err := callJira(ctx, jiraClient, http.MethodGet, apiEndpoint+"?"+params.Encode(), nil, &result); err != nil {
+		return "", fmt.Errorf("failed to search jira tickets with label '%s': %w", label, err)
+	}
+	if len(result.Issues) == 0 {
+		return "", nil
+	}
+	return result.Issues[0].Key, nil
+}
+
+// containsFold checks if a slice contains a non-empty item, case-insensitively
+func containsFold(slice []string, item string) bool {
+	if item == "" {
+		return false
+	}
+	for _, s := range slice {
+		if strings.EqualFold(s, item) {
+			return true
+		}
+	}
+	return false
+}
+
+// callJira calls a Jira API endpoint with an optional JSON body and decodes the response
+func callJira(ctx context.Context, jiraClient *jira.Client, method, apiEndpoint string, body, v interface{}) error {
+	request, err := jiraClient.NewRequest(ctx, method, apiEndpoint, "", body)
+	if err != nil {
+		return err
+	}
+	response, err := jiraClient.Call(request, v)
+	if err != nil {
+		if response != nil {
+			return fmt.Errorf("%w, response: %s", err, response.Bytes.String())
+		}
+		return fmt.Errorf("%w, response: nil response", err)
+	}
+	return nil
+}
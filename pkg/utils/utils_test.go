@@ -5,9 +5,14 @@ import (
 	"fmt"
 	v1 "jira-jit-rbac-operator/api/v1"
 	"jira-jit-rbac-operator/internal/config"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"regexp"
+	"time"
 
+	jira "github.com/ctreminiom/go-atlassian/v2/jira/v2"
+	"github.com/ctreminiom/go-atlassian/v2/pkg/infra/models"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 	corev1 "k8s.io/api/core/v1"
@@ -83,6 +88,30 @@ var _ = Describe("Utils", func() {
 		})
 	})
 
+	Describe("AllowedClusterRoles", func() {
+		var (
+			operatorConfig *v1.JustInTimeConfigSpec
+			jitRequest     *v1.JitRequest
+		)
+
+		BeforeEach(func() {
+			operatorConfig = &v1.JustInTimeConfigSpec{
+				AllowedClusterRoles:       []string{"edit"},
+				AllowedClusterScopedRoles: []string{"view"},
+			}
+			jitRequest = &v1.JitRequest{}
+		})
+
+		It("should return the allowed cluster roles for a namespaced request", func() {
+			Expect(AllowedClusterRoles(operatorConfig, jitRequest)).To(Equal([]string{"edit"}))
+		})
+
+		It("should return the allowed cluster scoped roles for a cluster scoped request", func() {
+			jitRequest.Spec.ClusterScoped = true
+			Expect(AllowedClusterRoles(operatorConfig, jitRequest)).To(Equal([]string{"view"}))
+		})
+	})
+
 	Describe("ValidateNamespaceRegex", func() {
 		var (
 			namespaces []string
@@ -115,6 +144,351 @@ var _ = Describe("Utils", func() {
 		})
 	})
 
+	Describe("RequestedTimes", func() {
+		now := time.Now()
+
+		It("should return the spec start and end time", func() {
+			jitRequest := &v1.JitRequest{Spec: v1.JitRequestSpec{
+				StartTime: metav1.NewTime(now.Add(time.Minute)),
+				EndTime:   metav1.NewTime(now.Add(time.Hour)),
+			}}
+			startTime, endTime := RequestedTimes(jitRequest, now)
+			Expect(startTime).To(Equal(jitRequest.Spec.StartTime.Time))
+			Expect(endTime).To(Equal(jitRequest.Spec.EndTime.Time))
+		})
+
+		It("should calculate the end time from the duration", func() {
+			jitRequest := &v1.JitRequest{Spec: v1.JitRequestSpec{
+				StartTime: metav1.NewTime(now.Add(time.Minute)),
+				Duration:  &metav1.Duration{Duration: time.Hour},
+			}}
+			_, endTime := RequestedTimes(jitRequest, now)
+			Expect(endTime).To(Equal(jitRequest.Spec.StartTime.Add(time.Hour)))
+		})
+
+		It("should start at the given time for startOnApproval requests", func() {
+			jitRequest := &v1.JitRequest{Spec: v1.JitRequestSpec{
+				StartOnApproval: true,
+				Duration:        &metav1.Duration{Duration: time.Hour},
+			}}
+			startTime, endTime := RequestedTimes(jitRequest, now)
+			Expect(startTime).To(Equal(now))
+			Expect(endTime).To(Equal(now.Add(time.Hour)))
+		})
+	})
+
+	Describe("ValidateTimes", func() {
+		now := time.Now()
+
+		It("should return no error for a valid start and end time", func() {
+			jitRequest := &v1.JitRequest{Spec: v1.JitRequestSpec{
+				StartTime: metav1.NewTime(now.Add(time.Minute)),
+				EndTime:   metav1.NewTime(now.Add(time.Hour)),
+			}}
+			Expect(ValidateTimes(jitRequest, now)).To(BeNil())
+		})
+
+		It("should return no error for a startOnApproval request with a duration", func() {
+			jitRequest := &v1.JitRequest{Spec: v1.JitRequestSpec{
+				StartOnApproval: true,
+				Duration:        &metav1.Duration{Duration: time.Hour},
+			}}
+			Expect(ValidateTimes(jitRequest, now)).To(BeNil())
+		})
+
+		It("should return an error if the duration is not positive", func() {
+			jitRequest := &v1.JitRequest{Spec: v1.JitRequestSpec{
+				StartTime: metav1.NewTime(now.Add(time.Minute)),
+				Duration:  &metav1.Duration{Duration: -time.Hour},
+			}}
+			err := ValidateTimes(jitRequest, now)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("duration must be greater than 0"))
+		})
+
+		It("should return an error if the start time is in the past", func() {
+			jitRequest := &v1.JitRequest{Spec: v1.JitRequestSpec{
+				StartTime: metav1.NewTime(now.Add(-time.Minute)),
+				EndTime:   metav1.NewTime(now.Add(time.Hour)),
+			}}
+			err := ValidateTimes(jitRequest, now)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("start time must be after current time"))
+		})
+
+		It("should return an error if a startOnApproval end time is in the past", func() {
+			jitRequest := &v1.JitRequest{Spec: v1.JitRequestSpec{
+				StartOnApproval: true,
+				EndTime:         metav1.NewTime(now.Add(-time.Minute)),
+			}}
+			err := ValidateTimes(jitRequest, now)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("end time must be after startTime"))
+		})
+	})
+
+	Describe("RolePolicy", func() {
+		operatorConfig := &v1.JustInTimeConfigSpec{
+			RolePolicies: map[string]v1.RolePolicySpec{
+				"admin": {MaxDuration: &metav1.Duration{Duration: time.Hour}},
+			},
+		}
+
+		It("should return the policy for a cluster role", func() {
+			policy := RolePolicy(operatorConfig, "admin")
+			Expect(policy).NotTo(BeNil())
+			Expect(policy.MaxDuration.Duration).To(Equal(time.Hour))
+		})
+
+		It("should return nil if no policy is configured for a cluster role", func() {
+			Expect(RolePolicy(operatorConfig, "edit")).To(BeNil())
+		})
+	})
+
+	Describe("RequiredApprovals", func() {
+		operatorConfig := &v1.JustInTimeConfigSpec{
+			RolePolicies: map[string]v1.RolePolicySpec{
+				"admin": {RequiredApprovals: 2},
+				"view":  {MaxDuration: &metav1.Duration{Duration: time.Hour}},
+			},
+		}
+
+		It("should return the required approvals of a role policy", func() {
+			Expect(RequiredApprovals(operatorConfig, "admin")).To(Equal(2))
+		})
+
+		It("should default to one approval", func() {
+			Expect(RequiredApprovals(operatorConfig, "view")).To(Equal(1))
+			Expect(RequiredApprovals(operatorConfig, "edit")).To(Equal(1))
+		})
+	})
+
+	Describe("RejectedStatuses", func() {
+		It("should return the rejected status and the further rejected statuses", func() {
+			operatorConfig := &v1.JustInTimeConfigSpec{
+				JiraWorkflowRejectedStatus:   "Rejected",
+				JiraWorkflowRejectedStatuses: []string{"Declined", "", "Closed"},
+			}
+			Expect(RejectedStatuses(operatorConfig)).To(Equal([]string{"Rejected", "Declined", "Closed"}))
+		})
+
+		It("should return no statuses if none are configured", func() {
+			Expect(RejectedStatuses(&v1.JustInTimeConfigSpec{})).To(BeEmpty())
+		})
+	})
+
+	Describe("LinkedIssueProjects", func() {
+		It("should return the linked issue projects", func() {
+			operatorConfig := &v1.JustInTimeConfigSpec{JiraProject: "IAM", LinkedIssueProjects: []string{"INC"}}
+			Expect(LinkedIssueProjects(operatorConfig)).To(Equal([]string{"INC"}))
+		})
+
+		It("should default to the jira project", func() {
+			Expect(LinkedIssueProjects(&v1.JustInTimeConfigSpec{JiraProject: "IAM"})).To(Equal([]string{"IAM"}))
+		})
+	})
+
+	Describe("ValidateRolePolicy", func() {
+		var (
+			ctx            context.Context
+			now            time.Time
+			jitRequest     *v1.JitRequest
+			operatorConfig *v1.JustInTimeConfigSpec
+			k8sClient      client.Client
+		)
+
+		BeforeEach(func() {
+			ctx = context.TODO()
+			now = time.Now()
+			jitRequest = &v1.JitRequest{Spec: v1.JitRequestSpec{
+				ClusterRole: "admin",
+				Namespaces:  []string{"team-a"},
+				StartTime:   metav1.NewTime(now.Add(time.Hour)),
+				EndTime:     metav1.NewTime(now.Add(2 * time.Hour)),
+				JiraFields:  map[string]string{"Justification": "I need a weapon"},
+			}}
+			operatorConfig = &v1.JustInTimeConfigSpec{
+				RolePolicies: map[string]v1.RolePolicySpec{
+					"admin": {
+						MaxDuration:             &metav1.Duration{Duration: 2 * time.Hour},
+						MaxLeadTime:             &metav1.Duration{Duration: 24 * time.Hour},
+						NamespaceAllowedRegexes: []string{"^team-.*", "^shared$"},
+						NamespaceSelector: &metav1.LabelSelector{
+							MatchLabels: map[string]string{"env": "dev"},
+						},
+						RequiredJiraFields: []string{"Justification"},
+					},
+				},
+			}
+			k8sClient = fake.NewClientBuilder().WithObjects(
+				&corev1.Namespace{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:   "team-a",
+						Labels: map[string]string{"env": "dev"},
+					},
+				},
+				&corev1.Namespace{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:   "team-b",
+						Labels: map[string]string{"env": "prod"},
+					},
+				},
+			).Build()
+		})
+
+		It("should return no error if the request is within the policy", func() {
+			fieldErr, err := ValidateRolePolicy(ctx, jitRequest, operatorConfig, k8sClient, now)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(fieldErr).To(BeNil())
+		})
+
+		It("should return no error if no policy is configured for the cluster role", func() {
+			jitRequest.Spec.ClusterRole = "edit"
+			jitRequest.Spec.EndTime = metav1.NewTime(now.Add(48 * time.Hour))
+			fieldErr, err := ValidateRolePolicy(ctx, jitRequest, operatorConfig, k8sClient, now)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(fieldErr).To(BeNil())
+		})
+
+		It("should return an error if the duration exceeds the max duration", func() {
+			jitRequest.Spec.EndTime = metav1.Time{}
+			jitRequest.Spec.Duration = &metav1.Duration{Duration: 3 * time.Hour}
+			fieldErr, err := ValidateRolePolicy(ctx, jitRequest, operatorConfig, k8sClient, now)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(fieldErr).NotTo(BeNil())
+			Expect(fieldErr.Field).To(Equal("spec.duration"))
+			Expect(fieldErr.Error()).To(ContainSubstring("duration must not exceed '2h0m0s' for clusterRole 'admin'"))
+		})
+
+		It("should return an error if the start time exceeds the max lead time", func() {
+			jitRequest.Spec.StartTime = metav1.NewTime(now.Add(48 * time.Hour))
+			jitRequest.Spec.EndTime = metav1.NewTime(now.Add(49 * time.Hour))
+			fieldErr, err := ValidateRolePolicy(ctx, jitRequest, operatorConfig, k8sClient, now)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(fieldErr).NotTo(BeNil())
+			Expect(fieldErr.Field).To(Equal("spec.startTime"))
+			Expect(fieldErr.Error()).To(ContainSubstring("start time must be within '24h0m0s'"))
+		})
+
+		It("should return an error if a required jira field is empty", func() {
+			jitRequest.Spec.JiraFields["Justification"] = ""
+			fieldErr, err := ValidateRolePolicy(ctx, jitRequest, operatorConfig, k8sClient, now)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(fieldErr).NotTo(BeNil())
+			Expect(fieldErr.Field).To(Equal("spec.jiraFields[Justification]"))
+		})
+
+		It("should return an error if a namespace does not match any regex", func() {
+			jitRequest.Spec.Namespaces = []string{"team-a", "kube-system"}
+			fieldErr, err := ValidateRolePolicy(ctx, jitRequest, operatorConfig, k8sClient, now)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(fieldErr).NotTo(BeNil())
+			Expect(fieldErr.Field).To(Equal("spec.namespaces[1]"))
+			Expect(fieldErr.Error()).To(ContainSubstring("namespace does not match the allowed patterns for clusterRole 'admin'"))
+		})
+
+		It("should return an error if a namespace does not match the selector", func() {
+			jitRequest.Spec.Namespaces = []string{"team-b"}
+			fieldErr, err := ValidateRolePolicy(ctx, jitRequest, operatorConfig, k8sClient, now)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(fieldErr).NotTo(BeNil())
+			Expect(fieldErr.Error()).To(ContainSubstring("namespace does not match the namespaceSelector (env=dev)"))
+		})
+
+		It("should skip namespace rules for cluster scoped requests", func() {
+			jitRequest.Spec.ClusterScoped = true
+			jitRequest.Spec.Namespaces = nil
+			fieldErr, err := ValidateRolePolicy(ctx, jitRequest, operatorConfig, k8sClient, now)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(fieldErr).To(BeNil())
+		})
+
+		It("should return an error for an invalid regex", func() {
+			policy := operatorConfig.RolePolicies["admin"]
+			policy.NamespaceAllowedRegexes = []string{"team-("}
+			operatorConfig.RolePolicies["admin"] = policy
+			_, err := ValidateRolePolicy(ctx, jitRequest, operatorConfig, k8sClient, now)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("invalid regex"))
+		})
+	})
+
+	Describe("ValidateExtensionPolicy", func() {
+		now := time.Now()
+		operatorConfig := &v1.JustInTimeConfigSpec{
+			RolePolicies: map[string]v1.RolePolicySpec{
+				"admin": {MaxDuration: &metav1.Duration{Duration: 2 * time.Hour}},
+			},
+		}
+
+		It("should return no error if the extension is within the max duration", func() {
+			jitRequest := &v1.JitRequest{
+				Spec: v1.JitRequestSpec{
+					ClusterRole: "admin",
+					Extension:   &v1.ExtensionSpec{EndTime: metav1.NewTime(now.Add(2 * time.Hour))},
+				},
+				Status: v1.JitRequestStatus{StartTime: metav1.NewTime(now)},
+			}
+			Expect(ValidateExtensionPolicy(operatorConfig, jitRequest)).To(BeNil())
+		})
+
+		It("should return an error if the extension exceeds the max duration", func() {
+			jitRequest := &v1.JitRequest{
+				Spec: v1.JitRequestSpec{
+					ClusterRole: "admin",
+					Extension:   &v1.ExtensionSpec{EndTime: metav1.NewTime(now.Add(3 * time.Hour))},
+				},
+				Status: v1.JitRequestStatus{StartTime: metav1.NewTime(now)},
+			}
+			err := ValidateExtensionPolicy(operatorConfig, jitRequest)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("total duration must not exceed '2h0m0s'"))
+		})
+	})
+
+	Describe("ValidateSubjects", func() {
+		It("should return no error if there are no subjects", func() {
+			Expect(ValidateSubjects(nil)).To(BeNil())
+		})
+
+		It("should return no error for valid subjects", func() {
+			subjects := []v1.SubjectSpec{
+				{Kind: "User", Name: "cpt-keyes@unsc.com"},
+				{Kind: "Group", Name: "on-call"},
+				{Kind: "ServiceAccount", Name: "deployer", Namespace: "automation"},
+			}
+			Expect(ValidateSubjects(subjects)).To(BeNil())
+		})
+
+		It("should return an error if a subject name is missing", func() {
+			subjects := []v1.SubjectSpec{{Kind: "Group"}}
+			err := ValidateSubjects(subjects)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("subject name is required"))
+		})
+
+		It("should return an error if a ServiceAccount subject has no namespace", func() {
+			subjects := []v1.SubjectSpec{{Kind: "ServiceAccount", Name: "deployer"}}
+			err := ValidateSubjects(subjects)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("namespace is required for ServiceAccount subjects"))
+		})
+
+		It("should return an error if a Group subject has a namespace", func() {
+			subjects := []v1.SubjectSpec{{Kind: "Group", Name: "on-call", Namespace: "automation"}}
+			err := ValidateSubjects(subjects)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("namespace must not be set for Group subjects"))
+		})
+
+		It("should return an error for an unsupported kind", func() {
+			subjects := []v1.SubjectSpec{{Kind: "Robot", Name: "343-guilty-spark"}}
+			err := ValidateSubjects(subjects)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("Unsupported value"))
+		})
+	})
+
 	Describe("ValidateNamespaceLabels", func() {
 		var (
 			ctx        context.Context
@@ -175,4 +549,592 @@ var _ = Describe("Utils", func() {
 		})
 	})
 
+	Describe("ParseJiraFlavour", func() {
+		It("should default to Jira Server", func() {
+			flavour, err := ParseJiraFlavour("")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(flavour).To(Equal(JiraServer))
+		})
+
+		It("should parse Jira Cloud", func() {
+			flavour, err := ParseJiraFlavour("Cloud")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(flavour).To(Equal(JiraCloud))
+		})
+
+		It("should return an error for an unknown flavour", func() {
+			_, err := ParseJiraFlavour("datacenter")
+			Expect(err).To(MatchError(ContainSubstring("unknown jira flavour 'datacenter'")))
+		})
+	})
+
+	Describe("JiraUser", func() {
+		It("should reference a Jira Server user by name", func() {
+			Expect(JiraUser(JiraServer, "john117").Name).To(Equal("john117"))
+			Expect(JiraUserField(JiraServer, "john117")).To(Equal(map[string]interface{}{"name": "john117"}))
+		})
+
+		It("should reference a Jira Cloud user by accountId", func() {
+			Expect(JiraUser(JiraCloud, "5b10a2844c20165700ede117").AccountID).To(Equal("5b10a2844c20165700ede117"))
+			Expect(JiraUserField(JiraCloud, "5b10a2844c20165700ede117")).To(Equal(map[string]interface{}{"accountId": "5b10a2844c20165700ede117"}))
+		})
+	})
+
+	Describe("FormatJiraTime", func() {
+		t := time.Date(2025, 1, 31, 23, 30, 0, 0, time.UTC)
+
+		It("should format a datetime in the location", func() {
+			location, err := LoadTimezone("Europe/Berlin")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(FormatJiraTime(t, "datetime", location)).To(Equal("2025-02-01T00:30:00.000+0100"))
+			Expect(FormatJiraTime(t, "text", time.UTC)).To(Equal("2025-01-31T23:30:00.000+0000"))
+		})
+
+		It("should format a date in the location", func() {
+			location, err := LoadTimezone("Europe/Berlin")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(FormatJiraTime(t, "date", location)).To(Equal("2025-02-01"))
+			Expect(FormatJiraTime(t, "date", time.UTC)).To(Equal("2025-01-31"))
+		})
+
+		It("should default to the local timezone", func() {
+			location, err := LoadTimezone("")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(location).To(Equal(time.Local))
+		})
+
+		It("should return an error for an unknown timezone", func() {
+			_, err := LoadTimezone("Mars/Olympus_Mons")
+			Expect(err).To(MatchError(ContainSubstring("invalid timezone 'Mars/Olympus_Mons'")))
+		})
+	})
+
+	Describe("JiraFieldValue", func() {
+		It("should convert a value for each field type", func() {
+			values := map[string]interface{}{
+				"text":            "some text",
+				"date":            "2025-01-31",
+				"datetime":        "2025-01-31T09:00:00.000+0100",
+				"select":          map[string]interface{}{"value": "edit"},
+				"multiselect":     []map[string]interface{}{{"value": "edit"}, {"value": "view"}},
+				"user":            map[string]interface{}{"name": "john117"},
+				"multiuser":       []map[string]interface{}{{"name": "john117"}, {"name": "cortana"}},
+				"number":          2.5,
+				"labels":          []string{"prod", "urgent"},
+				"cascadingselect": map[string]interface{}{"value": "Platform", "child": map[string]interface{}{"value": "Kubernetes"}},
+				"url":             "https://jira.example.com",
+				"group":           map[string]interface{}{"name": "admins"},
+				"multigroup":      []map[string]interface{}{{"name": "admins"}, {"name": "devs"}},
+			}
+			inputs := map[string]string{
+				"text":            "some text",
+				"date":            "2025-01-31",
+				"datetime":        "2025-01-31T09:00:00+01:00",
+				"select":          "edit",
+				"multiselect":     "edit, view",
+				"user":            "john117",
+				"multiuser":       "john117,cortana",
+				"number":          "2.5",
+				"labels":          "prod,urgent",
+				"cascadingselect": "Platform:Kubernetes",
+				"url":             "https://jira.example.com",
+				"group":           "admins",
+				"multigroup":      "admins, devs,",
+			}
+			for fieldType, input := range inputs {
+				value, err := JiraFieldValue(JiraServer, fieldType, input)
+				Expect(err).NotTo(HaveOccurred(), fieldType)
+				Expect(value).To(Equal(values[fieldType]), fieldType)
+			}
+		})
+
+		It("should reference Jira Cloud users by accountId", func() {
+			value, err := JiraFieldValue(JiraCloud, "multiuser", "5b10a2844c20165700ede117")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(value).To(Equal([]map[string]interface{}{{"accountId": "5b10a2844c20165700ede117"}}))
+		})
+
+		It("should return an error for a value that is invalid for the field type", func() {
+			invalid := map[string]string{
+				"date":            "31/01/2025",
+				"datetime":        "2025-01-31 09:00",
+				"number":          "two",
+				"labels":          "two words",
+				"cascadingselect": "Platform:",
+				"url":             "jira.example.com",
+			}
+			for fieldType, input := range invalid {
+				_, err := JiraFieldValue(JiraServer, fieldType, input)
+				Expect(err).To(HaveOccurred(), fieldType)
+			}
+		})
+
+		It("should return an error for an unknown field type", func() {
+			_, err := JiraFieldValue(JiraServer, "checkbox", "yes")
+			Expect(err).To(MatchError("unknown custom field type 'checkbox'"))
+		})
+	})
+
+	Describe("GetNameByEmail", func() {
+		var server *httptest.Server
+		var jiraClient *jira.Client
+		var searches int
+
+		BeforeEach(func() {
+			SetUserCacheTTL(DefaultUserCacheTTL)
+			searches = 0
+			server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				// Jira Cloud returns the hidden email of an account to users that may read emails
+				if r.URL.Path == "/rest/api/3/user/email" {
+					switch r.URL.Query().Get("accountId") {
+					case "5b10a2844c20165700edec07":
+						_, _ = w.Write([]byte(`{"accountId":"5b10a2844c20165700edec07","email":"cortana@unsc.com"}`))
+					case "5b10a2844c20165700ede001", "5b10a2844c20165700ede002":
+						_, _ = w.Write([]byte(`{"email":"arbiter@unsc.com"}`))
+					case "5b10a2844c20165700ede343":
+						_, _ = w.Write([]byte(`{"email":"343-guilty-spark@forerunner.org"}`))
+					default:
+						http.Error(w, `{"errorMessages":["forbidden"]}`, http.StatusForbidden)
+					}
+					return
+				}
+				Expect(r.URL.Path).To(Equal("/rest/api/2/user/search"))
+				searches++
+				switch {
+				case r.URL.Query().Get("username") == "master-chief@unsc.com":
+					_, _ = w.Write([]byte(`[{"name":"john117","emailAddress":"Master-Chief@unsc.com"}]`))
+				case r.URL.Query().Get("query") == "master-chief@unsc.com":
+					_, _ = w.Write([]byte(`[{"accountId":"5b10a2844c20165700ede117","emailAddress":"master-chief@unsc.com"}]`))
+				case r.URL.Query().Get("username") == "chief@unsc.com":
+					// a fuzzy search also matches partial emails
+					_, _ = w.Write([]byte(`[{"name":"john117","emailAddress":"master-chief@unsc.com"}]`))
+				case r.URL.Query().Get("username") == "spartan@unsc.com":
+					_, _ = w.Write([]byte(`[{"name":"john117","emailAddress":"spartan@unsc.com"},{"name":"kelly087","emailAddress":"spartan@unsc.com"}]`))
+				case r.URL.Query().Get("query") == "cortana@unsc.com":
+					// Jira Cloud hides the email of users that don't make it visible
+					_, _ = w.Write([]byte(`[{"accountId":"5b10a2844c20165700edec07"}]`))
+				case r.URL.Query().Get("query") == "arbiter@unsc.com":
+					_, _ = w.Write([]byte(`[{"accountId":"5b10a2844c20165700ede001"},{"accountId":"5b10a2844c20165700ede002"}]`))
+				case r.URL.Query().Get("query") == "spark@unsc.com":
+					// a fuzzy search hit for an unrelated user with a hidden email
+					_, _ = w.Write([]byte(`[{"accountId":"5b10a2844c20165700ede343"}]`))
+				case r.URL.Query().Get("query") == "johnson@unsc.com":
+					_, _ = w.Write([]byte(`[{"accountId":"5b10a2844c20165700ede404"}]`))
+				case r.URL.Query().Get("username") == "cortana@unsc.com":
+					_, _ = w.Write([]byte(`[{"name":"cortana"}]`))
+				default:
+					_, _ = w.Write([]byte(`[]`))
+				}
+			}))
+
+			var err error
+			jiraClient, err = jira.New(nil, server.URL)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		AfterEach(func() {
+			server.Close()
+		})
+
+		It("should return the user name on Jira Server", func() {
+			name, err := GetNameByEmail(context.TODO(), "master-chief@unsc.com", jiraClient, JiraServer)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(name).To(Equal("john117"))
+		})
+
+		It("should return the accountId on Jira Cloud", func() {
+			accountId, err := GetNameByEmail(context.TODO(), "master-chief@unsc.com", jiraClient, JiraCloud)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(accountId).To(Equal("5b10a2844c20165700ede117"))
+		})
+
+		It("should return an error if no user is found", func() {
+			_, err := GetNameByEmail(context.TODO(), "flood@unsc.com", jiraClient, JiraCloud)
+			Expect(err).To(MatchError("no users found with email: flood@unsc.com"))
+		})
+
+		It("should not match a user by a partial email", func() {
+			_, err := GetNameByEmail(context.TODO(), "chief@unsc.com", jiraClient, JiraServer)
+			Expect(err).To(MatchError(ErrUserNotFound))
+			Expect(err).To(MatchError("no users found with email: chief@unsc.com, the search only found users with other emails"))
+		})
+
+		It("should match a Jira Cloud user with a hidden email once the email is confirmed", func() {
+			accountId, err := GetNameByEmail(context.TODO(), "cortana@unsc.com", jiraClient, JiraCloud)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(accountId).To(Equal("5b10a2844c20165700edec07"))
+		})
+
+		It("should refuse a single Jira Cloud user with a hidden email that does not match", func() {
+			_, err := GetNameByEmail(context.TODO(), "spark@unsc.com", jiraClient, JiraCloud)
+			Expect(err).To(MatchError(ErrUserNotFound))
+			Expect(err).To(MatchError("no users found with email: spark@unsc.com, the email of 1 found users is hidden and could not be confirmed"))
+		})
+
+		It("should refuse a single Jira Cloud user with a hidden email that can't be confirmed", func() {
+			_, err := GetNameByEmail(context.TODO(), "johnson@unsc.com", jiraClient, JiraCloud)
+			Expect(err).To(MatchError(ErrUserNotFound))
+		})
+
+		It("should return an error if multiple Jira Cloud users with a hidden email are found", func() {
+			_, err := GetNameByEmail(context.TODO(), "arbiter@unsc.com", jiraClient, JiraCloud)
+			Expect(err).To(MatchError(ErrAmbiguousUser))
+		})
+
+		It("should not match a Jira Server user without the email", func() {
+			_, err := GetNameByEmail(context.TODO(), "cortana@unsc.com", jiraClient, JiraServer)
+			Expect(err).To(MatchError(ErrUserNotFound))
+		})
+
+		It("should return an error if multiple users match the email", func() {
+			_, err := GetNameByEmail(context.TODO(), "spartan@unsc.com", jiraClient, JiraServer)
+			Expect(err).To(MatchError(ErrAmbiguousUser))
+			Expect(err).To(MatchError("multiple users found with email: spartan@unsc.com, 2 users match"))
+		})
+
+		It("should cache found users until the ttl has passed", func() {
+			for range 2 {
+				name, err := GetNameByEmail(context.TODO(), "master-chief@unsc.com", jiraClient, JiraServer)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(name).To(Equal("john117"))
+			}
+			Expect(searches).To(Equal(1))
+
+			By("caching Jira Server and Cloud users separately")
+			_, err := GetNameByEmail(context.TODO(), "master-chief@unsc.com", jiraClient, JiraCloud)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(searches).To(Equal(2))
+
+			By("searching again once expired")
+			userCache.now = func() time.Time { return time.Now().Add(DefaultUserCacheTTL) }
+			DeferCleanup(func() { userCache.now = time.Now })
+			_, err = GetNameByEmail(context.TODO(), "master-chief@unsc.com", jiraClient, JiraServer)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(searches).To(Equal(3))
+		})
+
+		It("should search again once the cache is cleared", func() {
+			_, err := GetNameByEmail(context.TODO(), "master-chief@unsc.com", jiraClient, JiraServer)
+			Expect(err).NotTo(HaveOccurred())
+
+			By("clearing the cache as the Jira credentials are reloaded")
+			ClearUserCache()
+			_, err = GetNameByEmail(context.TODO(), "master-chief@unsc.com", jiraClient, JiraServer)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(searches).To(Equal(2))
+		})
+
+		It("should not cache users that are not found", func() {
+			for range 2 {
+				_, err := GetNameByEmail(context.TODO(), "flood@unsc.com", jiraClient, JiraServer)
+				Expect(err).To(HaveOccurred())
+			}
+			Expect(searches).To(Equal(2))
+		})
+
+		It("should not cache users if disabled", func() {
+			SetUserCacheTTL(0)
+			for range 2 {
+				_, err := GetNameByEmail(context.TODO(), "master-chief@unsc.com", jiraClient, JiraServer)
+				Expect(err).NotTo(HaveOccurred())
+			}
+			Expect(searches).To(Equal(2))
+		})
+	})
+
+	Describe("FindJiraTicket", func() {
+		var server *httptest.Server
+		var jiraClient *jira.Client
+
+		BeforeEach(func() {
+			server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				Expect(r.URL.Query().Get("maxResults")).To(Equal("1"))
+				switch r.URL.Query().Get("jql") {
+				case `labels = "jit-request-117" ORDER BY created ASC`:
+					_, _ = w.Write([]byte(`{"issues": [{"key": "IAM-1"}]}`))
+				case `labels = "jit-request-500" ORDER BY created ASC`:
+					w.WriteHeader(http.StatusBadRequest)
+				default:
+					_, _ = w.Write([]byte(`{"issues": []}`))
+				}
+			}))
+
+			var err error
+			jiraClient, err = jira.New(nil, server.URL)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		AfterEach(func() {
+			server.Close()
+		})
+
+		It("should return the label of a JitRequest", func() {
+			Expect(JiraTicketLabel(&v1.JitRequest{ObjectMeta: metav1.ObjectMeta{UID: "117"}})).To(Equal("jit-request-117"))
+			Expect(JiraTicketLabel(&v1.JitRequest{})).To(BeEmpty())
+		})
+
+		It("should find the ticket with the label on Jira Server and Cloud", func() {
+			jiraTicket, err := FindJiraTicket(context.TODO(), "jit-request-117", jiraClient, JiraServer)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(jiraTicket).To(Equal("IAM-1"))
+
+			jiraTicket, err = FindJiraTicket(context.TODO(), "jit-request-117", jiraClient, JiraCloud)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(jiraTicket).To(Equal("IAM-1"))
+		})
+
+		It("should return no ticket if none has the label", func() {
+			jiraTicket, err := FindJiraTicket(context.TODO(), "jit-request-343", jiraClient, JiraServer)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(jiraTicket).To(BeEmpty())
+
+			jiraTicket, err = FindJiraTicket(context.TODO(), "", jiraClient, JiraServer)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(jiraTicket).To(BeEmpty())
+		})
+
+		It("should return an error if the search fails", func() {
+			_, err := FindJiraTicket(context.TODO(), "jit-request-500", jiraClient, JiraServer)
+			Expect(err).To(MatchError(ContainSubstring("failed to search jira tickets with label 'jit-request-500'")))
+		})
+	})
+
+	Describe("Jira approvals", func() {
+		var server *httptest.Server
+		var jiraClient *jira.Client
+
+		BeforeEach(func() {
+			server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				switch r.URL.Path {
+				case "/rest/servicedeskapi/request/IAM-1/approval":
+					_, _ = w.Write([]byte(`{"values": [
+						{"approvers": [
+							{"approver": {"name": "cptKeyes", "accountId": "5b10a2844c20165700ede21f", "displayName": "Captain Keyes"}, "approverDecision": "approved"},
+							{"approver": {"name": "oni", "accountId": "5b10a2844c20165700ede0n1"}, "approverDecision": "pending"}
+						], "completedDate": {"epochMillis": 1735725600000}},
+						{"approvers": [
+							{"approver": {"name": "john117", "accountId": "5b10a2844c20165700ede117"}, "approverDecision": "approved"}
+						]}
+					]}`))
+				case "/rest/api/2/issue/IAM-1":
+					Expect(r.URL.Query().Get("expand")).To(Equal("changelog"))
+					_, _ = w.Write([]byte(`{"changelog": {"histories": [
+						{"author": {"name": "cptKeyes"}, "created": "2025-01-01T10:00:00.000+0000", "items": [{"field": "status", "toString": "Approved"}]},
+						{"author": {"name": "oni"}, "created": "2025-01-01T11:00:00.000+0000", "items": [{"field": "assignee", "toString": "Approved"}]},
+						{"author": {"name": "john117"}, "created": "2025-01-01T12:00:00.000+0000", "items": [{"field": "status", "toString": "To Do"}]}
+					]}}`))
+				case "/rest/api/2/issue/IAM-1/changelog":
+					// two pages of the Jira Cloud changelog, the approval is on the last one
+					switch r.URL.Query().Get("startAt") {
+					case "0":
+						_, _ = w.Write([]byte(`{"startAt": 0, "maxResults": 2, "total": 3, "isLast": false, "values": [
+							{"author": {"accountId": "5b10a2844c20165700ede0n1"}, "created": "2025-01-01T09:00:00.000+0000", "items": [{"field": "assignee", "toString": "Approved"}]},
+							{"author": {"accountId": "5b10a2844c20165700ede117"}, "created": "2025-01-01T09:30:00.000+0000", "items": [{"field": "status", "toString": "To Do"}]}
+						]}`))
+					case "2":
+						_, _ = w.Write([]byte(`{"startAt": 2, "maxResults": 2, "total": 3, "isLast": true, "values": [
+							{"author": {"accountId": "5b10a2844c20165700ede21f"}, "created": "2025-01-01T10:00:00.000+0000", "items": [{"field": "status", "toString": "Approved"}]}
+						]}`))
+					default:
+						http.Error(w, "unexpected startAt", http.StatusBadRequest)
+					}
+				default:
+					http.NotFound(w, r)
+				}
+			}))
+
+			var err error
+			jiraClient, err = jira.New(nil, server.URL)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		AfterEach(func() {
+			server.Close()
+		})
+
+		It("should return the approved service desk approvers", func() {
+			approvals, err := GetServiceDeskApprovals(context.TODO(), "IAM-1", jiraClient, JiraServer)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(approvals).To(Equal([]JiraApproval{
+				{Approver: "cptKeyes", DisplayName: "Captain Keyes", ApprovedAt: time.UnixMilli(1735725600000)},
+				{Approver: "john117"},
+			}))
+		})
+
+		It("should return the service desk approvers by accountId on Jira Cloud", func() {
+			approvals, err := GetServiceDeskApprovals(context.TODO(), "IAM-1", jiraClient, JiraCloud)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(approvals).To(HaveLen(2))
+			Expect(approvals[0].Approver).To(Equal("5b10a2844c20165700ede21f"))
+		})
+
+		It("should return the users who moved the ticket to the approved status", func() {
+			approvals, err := GetChangelogApprovals(context.TODO(), "IAM-1", "approved", jiraClient, JiraServer)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(approvals).To(HaveLen(1))
+			Expect(approvals[0].Approver).To(Equal("cptKeyes"))
+			Expect(approvals[0].ApprovedAt.Equal(time.Date(2025, 1, 1, 10, 0, 0, 0, time.UTC))).To(BeTrue())
+		})
+
+		It("should page through the changelog on Jira Cloud", func() {
+			approvals, err := GetChangelogApprovals(context.TODO(), "IAM-1", "approved", jiraClient, JiraCloud)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(approvals).To(HaveLen(1))
+			Expect(approvals[0].Approver).To(Equal("5b10a2844c20165700ede21f"))
+			Expect(approvals[0].ApprovedAt.Equal(time.Date(2025, 1, 1, 10, 0, 0, 0, time.UTC))).To(BeTrue())
+		})
+
+		It("should return an error if the approvals can't be fetched", func() {
+			_, err := GetServiceDeskApprovals(context.TODO(), "IAM-2", jiraClient, JiraServer)
+			Expect(err).To(MatchError(ContainSubstring("failed to get service desk approvals")))
+		})
+	})
+
+	Describe("ValidateLinkedIssue", func() {
+		var server *httptest.Server
+		var jiraClient *jira.Client
+		var operatorConfig *v1.JustInTimeConfigSpec
+		var jitRequest *v1.JitRequest
+
+		BeforeEach(func() {
+			server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				switch r.URL.Path {
+				case "/rest/api/2/issue/INC-1":
+					Expect(r.URL.Query().Get("fields")).To(Equal("project,status"))
+					_, _ = w.Write([]byte(`{"fields": {"project": {"key": "INC"}, "status": {"name": "In Progress", "statusCategory": {"key": "indeterminate"}}}}`))
+				case "/rest/api/2/issue/INC-2":
+					_, _ = w.Write([]byte(`{"fields": {"project": {"key": "INC"}, "status": {"name": "Resolved", "statusCategory": {"key": "done"}}}}`))
+				case "/rest/api/2/issue/INC-3":
+					_, _ = w.Write([]byte(`{"fields": {"project": {"key": "INC"}, "status": {"name": "Declined", "statusCategory": {"key": "indeterminate"}}}}`))
+				case "/rest/api/2/issue/OPS-1":
+					_, _ = w.Write([]byte(`{"fields": {"project": {"key": "OPS"}, "status": {"name": "In Progress", "statusCategory": {"key": "indeterminate"}}}}`))
+				case "/rest/api/2/issue/INC-500":
+					w.WriteHeader(http.StatusBadGateway)
+				default:
+					http.NotFound(w, r)
+				}
+			}))
+
+			var err error
+			jiraClient, err = jira.New(nil, server.URL)
+			Expect(err).NotTo(HaveOccurred())
+
+			operatorConfig = &v1.JustInTimeConfigSpec{
+				JiraProject:                  "IAM",
+				JiraWorkflowRejectedStatuses: []string{"Declined"},
+				LinkedIssueProjects:          []string{"IAM", "INC"},
+			}
+			jitRequest = &v1.JitRequest{Spec: v1.JitRequestSpec{LinkedJiraTicket: "INC-1"}}
+		})
+
+		AfterEach(func() {
+			server.Close()
+		})
+
+		It("should accept an open issue in an allowed project", func() {
+			fieldErr, err := ValidateLinkedIssue(context.TODO(), jitRequest, operatorConfig, jiraClient)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(fieldErr).To(BeNil())
+		})
+
+		It("should accept a JitRequest without a linked issue", func() {
+			jitRequest.Spec.LinkedJiraTicket = ""
+			fieldErr, err := ValidateLinkedIssue(context.TODO(), jitRequest, operatorConfig, jiraClient)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(fieldErr).To(BeNil())
+		})
+
+		It("should reject an issue in another project", func() {
+			jitRequest.Spec.LinkedJiraTicket = "OPS-1"
+			fieldErr, err := ValidateLinkedIssue(context.TODO(), jitRequest, operatorConfig, jiraClient)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(fieldErr).To(MatchError(ContainSubstring("linked issue must be in one of the projects 'IAM, INC'")))
+		})
+
+		It("should reject a done or rejected issue", func() {
+			for issueKey, status := range map[string]string{"INC-2": "Resolved", "INC-3": "Declined"} {
+				jitRequest.Spec.LinkedJiraTicket = issueKey
+				fieldErr, err := ValidateLinkedIssue(context.TODO(), jitRequest, operatorConfig, jiraClient)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(fieldErr).To(MatchError(ContainSubstring(fmt.Sprintf("linked issue must be open, it is in status '%s'", status))))
+			}
+		})
+
+		It("should reject an issue that does not exist", func() {
+			jitRequest.Spec.LinkedJiraTicket = "INC-404"
+			fieldErr, err := ValidateLinkedIssue(context.TODO(), jitRequest, operatorConfig, jiraClient)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(fieldErr).To(MatchError(ContainSubstring("spec.linkedJiraTicket: Not found")))
+		})
+
+		It("should return an error if the issue can't be fetched", func() {
+			jitRequest.Spec.LinkedJiraTicket = "INC-500"
+			_, err := ValidateLinkedIssue(context.TODO(), jitRequest, operatorConfig, jiraClient)
+			Expect(err).To(MatchError(ContainSubstring("failed to get linked issue")))
+		})
+	})
+
+	Describe("DistinctApprovals", func() {
+		since := time.Date(2025, 1, 1, 10, 0, 0, 0, time.UTC)
+		now := since.Add(time.Hour)
+
+		It("should keep the earliest approval of each approver after since", func() {
+			approvals := []JiraApproval{
+				{Approver: "oni", ApprovedAt: since.Add(20 * time.Minute)},
+				{Approver: "cptKeyes", ApprovedAt: since.Add(30 * time.Minute)},
+				{Approver: "oni", ApprovedAt: since.Add(10 * time.Minute)},
+				{Approver: "john117", ApprovedAt: since.Add(-time.Minute)},
+			}
+			distinct := DistinctApprovals(approvals, nil, nil, since, now)
+			Expect(distinct).To(HaveLen(2))
+			Expect(distinct[0].Approver).To(Equal("oni"))
+			Expect(distinct[0].ApprovedAt.Time).To(Equal(since.Add(10 * time.Minute)))
+			Expect(distinct[1].Approver).To(Equal("cptKeyes"))
+		})
+
+		It("should exclude users by name or email", func() {
+			approvals := []JiraApproval{
+				{Approver: "john117", ApprovedAt: now},
+				{Approver: "5b10a2844c20165700ede117", Email: "Master-Chief@unsc.com", ApprovedAt: now},
+				{Approver: "oni", ApprovedAt: now},
+			}
+			distinct := DistinctApprovals(approvals, nil, []string{"master-chief@unsc.com", "john117"}, since, now)
+			Expect(distinct).To(HaveLen(1))
+			Expect(distinct[0].Approver).To(Equal("oni"))
+		})
+
+		It("should keep the previous time of approvals without a time", func() {
+			previous := []v1.Approval{{Approver: "oni", ApprovedAt: metav1.NewTime(since.Add(time.Minute))}}
+			approvals := []JiraApproval{{Approver: "oni"}, {Approver: "cptKeyes"}}
+			distinct := DistinctApprovals(approvals, previous, nil, since, now)
+			Expect(distinct).To(HaveLen(2))
+			Expect(distinct[0].ApprovedAt.Time).To(Equal(since.Add(time.Minute)))
+			Expect(distinct[1].ApprovedAt.Time).To(Equal(now))
+		})
+	})
+
+	Describe("MatchJiraTransition", func() {
+		transitions := []*models.IssueTransitionScheme{
+			{ID: "21", Name: "Reject"},
+			{ID: "41", Name: "Complete"},
+		}
+
+		It("should match a transition by ID", func() {
+			Expect(MatchJiraTransition(transitions, "41")).To(Equal("41"))
+		})
+
+		It("should match a transition by name case-insensitively", func() {
+			Expect(MatchJiraTransition(transitions, "reject")).To(Equal("21"))
+		})
+
+		It("should return an error listing the available transitions", func() {
+			_, err := MatchJiraTransition(transitions, "Revoke")
+			Expect(err).To(MatchError("transition 'Revoke' is not available from the current status, available transitions: Reject (21), Complete (41)"))
+		})
+
+		It("should return an error if no transitions are available", func() {
+			_, err := MatchJiraTransition(nil, "Revoke")
+			Expect(err).To(MatchError(ContainSubstring("no transitions are available")))
+		})
+	})
 })
@@ -20,6 +20,7 @@ const (
 	RoleBindingName                   = JitRequestName + "-jit"
 	ValidClusterRole           string = "edit"
 	InvalidClusterRole                = "admin"
+	ValidClusterScopedRole            = "view"
 	TestJiraWorkflowToDoStatus        = "ToDo"
 	TestJiraWorkflowApproved          = "Approved"
 	EventValidationFailed             = "ValidationFailed"
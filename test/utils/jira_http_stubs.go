@@ -1,32 +1,92 @@
 package utils
 
 import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"net"
 	"net/http"
 	"net/http/httptest"
+	"regexp"
+	"slices"
 	"strings"
 )
 
 type Issue struct {
-	ID       string   `json:"id"`
-	Key      string   `json:"key"`
-	Self     string   `json:"self"`
-	Fields   Fields   `json:"fields"`
-	Comments []string `json:"comments"`
+	ID        string     `json:"id"`
+	Key       string     `json:"key"`
+	Self      string     `json:"self"`
+	Fields    Fields     `json:"fields"`
+	Comments  []string   `json:"comments"`
+	Changelog *Changelog `json:"changelog,omitempty"`
+}
+
+type Changelog struct {
+	Histories []History `json:"histories"`
+}
+
+type History struct {
+	Author  User          `json:"author"`
+	Created string        `json:"created"`
+	Items   []HistoryItem `json:"items"`
+}
+
+type HistoryItem struct {
+	Field    string `json:"field"`
+	ToString string `json:"toString"`
 }
 
 type Fields struct {
-	Summary string `json:"summary"`
-	Status  Status `json:"status"`
+	Summary string   `json:"summary"`
+	Status  Status   `json:"status"`
+	Labels  []string `json:"labels,omitempty"`
+	Project *Project `json:"project,omitempty"`
+}
+
+type Project struct {
+	Key string `json:"key"`
+}
+
+// IssueLink is an issue link payload
+type IssueLink struct {
+	Type struct {
+		Name string `json:"name"`
+	} `json:"type"`
+	InwardIssue struct {
+		Key string `json:"key"`
+	} `json:"inwardIssue"`
+	OutwardIssue struct {
+		Key string `json:"key"`
+	} `json:"outwardIssue"`
+}
+
+// CustomerRequest is a Jira Service Management customer request payload
+type CustomerRequest struct {
+	ServiceDeskID      string                 `json:"serviceDeskId"`
+	RequestTypeID      string                 `json:"requestTypeId"`
+	RequestFieldValues map[string]interface{} `json:"requestFieldValues"`
+	RaiseOnBehalfOf    string                 `json:"raiseOnBehalfOf"`
 }
 
 type Status struct {
-	Name string `json:"name"`
+	Name           string          `json:"name"`
+	StatusCategory *StatusCategory `json:"statusCategory,omitempty"`
+}
+
+type StatusCategory struct {
+	Key string `json:"key"`
 }
 
 type User struct {
+	Name         string `json:"name"`
+	AccountID    string `json:"accountId"`
+	EmailAddress string `json:"emailAddress,omitempty"`
+}
+
+type Transition struct {
+	ID   string `json:"id"`
 	Name string `json:"name"`
 }
 
@@ -42,11 +102,64 @@ type Author struct {
 }
 
 var IssueStatus string
+
+// IssueApprover is the user name of who moved an issue to its current status in the changelog
+var IssueApprover = "cptKeyes"
+
+// IssueApprovedAt is when the issue was moved to its current status in the changelog
+var IssueApprovedAt = "2025-01-20T21:01:46.000+0000"
+
+// LastCustomerRequest is the payload of the last customer request created
+var LastCustomerRequest CustomerRequest
+
+// CreatedIssues counts the issues created, to check a ticket is not created twice
+var CreatedIssues int
+
+// Existing issues a JitRequest can be linked to
+const (
+	LinkedIssue             = "INC-1"
+	ClosedLinkedIssue       = "INC-2"
+	OtherProjectLinkedIssue = "OPS-1"
+)
+
+// LastIssueLink is the payload of the last issue link created
+var LastIssueLink IssueLink
+
+var linkedIssues = map[string]Issue{
+	LinkedIssue: {Key: LinkedIssue, Fields: Fields{
+		Project: &Project{Key: "INC"},
+		Status:  Status{Name: "In Progress", StatusCategory: &StatusCategory{Key: "indeterminate"}},
+	}},
+	ClosedLinkedIssue: {Key: ClosedLinkedIssue, Fields: Fields{
+		Project: &Project{Key: "INC"},
+		Status:  Status{Name: "Resolved", StatusCategory: &StatusCategory{Key: "done"}},
+	}},
+	OtherProjectLinkedIssue: {Key: OtherProjectLinkedIssue, Fields: Fields{
+		Project: &Project{Key: "OPS"},
+		Status:  Status{Name: "In Progress", StatusCategory: &StatusCategory{Key: "indeterminate"}},
+	}},
+}
+
+// ServiceDeskApprovers are the emails of the users who approved the Jira Service Management approval of an issue
+var ServiceDeskApprovers []string
+var transitions = []Transition{
+	{ID: "1", Name: "Done"},
+	{ID: "10", Name: "Close"},
+	{ID: "21", Name: "Reject"},
+	{ID: "41", Name: "Complete"},
+	{ID: "51", Name: "Revoke"},
+	{ID: "61", Name: "Request Extension"},
+	{ID: "71", Name: "Expire"},
+}
 var issues = make(map[string]*Issue)
+
+// AmbiguousUser is the email of two Jira users
+const AmbiguousUser = "spartan@unsc.com"
+
 var users = map[string]User{
-	"master-chief@unsc.com": {Name: "john117"},
-	"cpt-keyes@unsc.com":    {Name: "cptKeyes"},
-	"oni@unsc.com":          {Name: "oni"},
+	"master-chief@unsc.com": {Name: "john117", AccountID: "5b10a2844c20165700ede117"},
+	"cpt-keyes@unsc.com":    {Name: "cptKeyes", AccountID: "5b10a2844c20165700ede21f"},
+	"oni@unsc.com":          {Name: "oni", AccountID: "5b10a2844c20165700ede0n1"},
 }
 
 func CreateHTTPServer() *httptest.Server {
@@ -62,6 +175,10 @@ func CreateHTTPServer() *httptest.Server {
 		case http.MethodPost:
 			if r.URL.Path == "/rest/api/2/issue" {
 				createIssue(w, r)
+			} else if r.URL.Path == "/rest/servicedeskapi/request" {
+				createCustomerRequest(w, r)
+			} else if r.URL.Path == "/rest/api/2/issueLink" {
+				createIssueLink(w, r)
 			} else if strings.HasPrefix(r.URL.Path, "/rest/api/2/issue/") && strings.HasSuffix(r.URL.Path, "/comment") {
 				addComment(w, r)
 			}
@@ -70,12 +187,20 @@ func CreateHTTPServer() *httptest.Server {
 				transitionIssue(w, r, "rejected")
 			} else if r.URL.Path == "/rest/api/2/issue/transition/completed" {
 				transitionIssue(w, r, "completed")
+			} else if strings.HasPrefix(r.URL.Path, "/rest/api/2/issue/") {
+				updateIssue(w, r)
 			}
 		case http.MethodGet:
-			if strings.HasPrefix(r.URL.Path, "/rest/api/2/issue/") {
+			if strings.HasPrefix(r.URL.Path, "/rest/api/2/issue/") && strings.HasSuffix(r.URL.Path, "/transitions") {
+				getTransitions(w, r)
+			} else if strings.HasPrefix(r.URL.Path, "/rest/api/2/issue/") {
 				getIssueDetails(w, r)
+			} else if strings.HasPrefix(r.URL.Path, "/rest/servicedeskapi/request/") && strings.HasSuffix(r.URL.Path, "/approval") {
+				getServiceDeskApprovals(w, r)
 			} else if r.URL.Path == "/rest/api/2/user/search" {
 				getUserByEmail(w, r)
+			} else if r.URL.Path == "/rest/api/2/search" || r.URL.Path == "/rest/api/2/search/jql" {
+				searchIssues(w, r)
 			}
 		default:
 			http.NotFound(w, r)
@@ -98,12 +223,82 @@ func createIssue(w http.ResponseWriter, r *http.Request) {
 	}
 	issue.Key = "IAM-1"
 	issues[issue.Key] = &issue
+	CreatedIssues++
 	w.WriteHeader(http.StatusCreated)
 	if err := json.NewEncoder(w).Encode(issue); err != nil {
 		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
 	}
 }
 
+func createCustomerRequest(w http.ResponseWriter, r *http.Request) {
+	var request CustomerRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, "Invalid request payload", http.StatusBadRequest)
+		return
+	}
+	LastCustomerRequest = request
+
+	summary, _ := request.RequestFieldValues["summary"].(string)
+	issue := &Issue{Key: "IAM-1", Fields: Fields{Summary: summary}}
+	issues[issue.Key] = issue
+	w.WriteHeader(http.StatusCreated)
+	if err := json.NewEncoder(w).Encode(map[string]string{"issueId": "10000", "issueKey": issue.Key}); err != nil {
+		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+	}
+}
+
+func createIssueLink(w http.ResponseWriter, r *http.Request) {
+	var link IssueLink
+	if err := json.NewDecoder(r.Body).Decode(&link); err != nil {
+		http.Error(w, "Invalid request payload", http.StatusBadRequest)
+		return
+	}
+	_, inward := issues[link.InwardIssue.Key]
+	_, outward := linkedIssues[link.OutwardIssue.Key]
+	if !inward || !outward {
+		http.Error(w, "issue not found", http.StatusNotFound)
+		return
+	}
+	LastIssueLink = link
+	w.WriteHeader(http.StatusCreated)
+}
+
+func updateIssue(w http.ResponseWriter, r *http.Request) {
+	issueKey := r.URL.Path[len("/rest/api/2/issue/"):]
+
+	var req struct {
+		Fields Fields `json:"fields"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request payload", http.StatusBadRequest)
+		return
+	}
+	if issue, ok := issues[issueKey]; ok {
+		if req.Fields.Labels != nil {
+			issue.Fields.Labels = req.Fields.Labels
+		}
+		w.WriteHeader(http.StatusNoContent)
+	} else {
+		http.NotFound(w, r)
+	}
+}
+
+// GetIssueLabels returns the labels of an issue
+func GetIssueLabels(issueKey string) []string {
+	if issue, ok := issues[issueKey]; ok {
+		return issue.Fields.Labels
+	}
+	return nil
+}
+
+// GetIssueComments returns the comments of an issue
+func GetIssueComments(issueKey string) []string {
+	if issue, ok := issues[issueKey]; ok {
+		return issue.Comments
+	}
+	return nil
+}
+
 func addComment(w http.ResponseWriter, r *http.Request) {
 	issueKey := r.URL.Path[len("/rest/api/2/issue/"):]
 	issueKey = issueKey[:len(issueKey)-len("/comment")]
@@ -151,23 +346,103 @@ func transitionIssue(w http.ResponseWriter, r *http.Request, status string) {
 	}
 }
 
-func getUserByEmail(w http.ResponseWriter, r *http.Request) {
-	email := r.URL.Query().Get("username")
-	if user, ok := users[email]; ok {
+func getTransitions(w http.ResponseWriter, r *http.Request) {
+	issueKey := r.URL.Path[len("/rest/api/2/issue/"):]
+	issueKey = issueKey[:len(issueKey)-len("/transitions")]
+
+	if _, ok := issues[issueKey]; ok {
 		w.WriteHeader(http.StatusOK)
-		w.Header().Set("Content-Type", "application/json")
-		if err := json.NewEncoder(w).Encode([]User{user}); err != nil {
+		response := struct {
+			Transitions []Transition `json:"transitions"`
+		}{Transitions: transitions}
+		if err := json.NewEncoder(w).Encode(response); err != nil {
 			http.Error(w, "Failed to encode response", http.StatusInternalServerError)
 		}
 	} else {
-		http.NotFound(w, r)
+		http.Error(w, "issue not found", http.StatusNotFound)
+	}
+}
+
+func getUserByEmail(w http.ResponseWriter, r *http.Request) {
+	// Jira Server searches by username, Jira Cloud by query, both also match partial emails
+	query := r.URL.Query().Get("username")
+	if query == "" {
+		query = r.URL.Query().Get("query")
+	}
+	found := []User{}
+	if query != "" {
+		for email, user := range users {
+			if strings.Contains(email, query) {
+				user.EmailAddress = email
+				found = append(found, user)
+			}
+		}
+		if query == AmbiguousUser {
+			found = append(found,
+				User{Name: "john117", AccountID: "5b10a2844c20165700ede117", EmailAddress: AmbiguousUser},
+				User{Name: "kelly087", AccountID: "5b10a2844c20165700ede087", EmailAddress: AmbiguousUser},
+			)
+		}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(found); err != nil {
+		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+	}
+}
+
+// searchIssues supports the JQL of a single label, labels = "<label>"
+func searchIssues(w http.ResponseWriter, r *http.Request) {
+	type SearchResult struct {
+		Issues []Issue `json:"issues"`
+	}
+
+	result := SearchResult{Issues: []Issue{}}
+	match := regexp.MustCompile(`labels = "([^"]+)"`).FindStringSubmatch(r.URL.Query().Get("jql"))
+	if match != nil {
+		for key, issue := range issues {
+			if slices.Contains(issue.Fields.Labels, match[1]) {
+				result.Issues = append(result.Issues, Issue{Key: key})
+			}
+		}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(result); err != nil {
+		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+	}
+}
+
+func getServiceDeskApprovals(w http.ResponseWriter, r *http.Request) {
+	type Approver struct {
+		Approver         User   `json:"approver"`
+		ApproverDecision string `json:"approverDecision"`
+	}
+
+	approvers := []Approver{}
+	for _, email := range ServiceDeskApprovers {
+		user := users[email]
+		user.EmailAddress = email
+		approvers = append(approvers, Approver{Approver: user, ApproverDecision: "approved"})
+	}
+	response := map[string]any{
+		"values": []map[string]any{
+			{"approvers": approvers},
+		},
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
 	}
 }
 
 func getIssueDetails(w http.ResponseWriter, r *http.Request) {
 	issueKey := r.URL.Path[len("/rest/api/2/issue/"):]
 
-	if issue, ok := issues[issueKey]; ok {
+	if linkedIssue, ok := linkedIssues[issueKey]; ok {
+		w.WriteHeader(http.StatusOK)
+		if err := json.NewEncoder(w).Encode(linkedIssue); err != nil {
+			http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+		}
+	} else if issue, ok := issues[issueKey]; ok {
 		w.WriteHeader(http.StatusOK)
 		issueResponse := Issue{
 			ID:   "10000",
@@ -180,6 +455,15 @@ func getIssueDetails(w http.ResponseWriter, r *http.Request) {
 				},
 			},
 		}
+		if r.URL.Query().Get("expand") == "changelog" {
+			issueResponse.Changelog = &Changelog{
+				Histories: []History{{
+					Author:  User{Name: IssueApprover},
+					Created: IssueApprovedAt,
+					Items:   []HistoryItem{{Field: "status", ToString: IssueStatus}},
+				}},
+			}
+		}
 		if err := json.NewEncoder(w).Encode(issueResponse); err != nil {
 			http.Error(w, "Failed to encode response", http.StatusInternalServerError)
 		}
@@ -187,3 +471,29 @@ func getIssueDetails(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "issue not found", http.StatusNotFound)
 	}
 }
+
+// JiraWebhookPayload returns a Jira webhook payload for an updated issue with the given status
+func JiraWebhookPayload(issueKey, status string) []byte {
+	payload := map[string]any{
+		"webhookEvent": "jira:issue_updated",
+		"issue": Issue{
+			ID:  "10000",
+			Key: issueKey,
+			Fields: Fields{
+				Status: Status{Name: status},
+			},
+		},
+	}
+	data, err := json.Marshal(payload)
+	if err != nil {
+		panic(err)
+	}
+	return data
+}
+
+// SignJiraWebhook returns the X-Hub-Signature header value Jira sends for a payload with the given secret
+func SignJiraWebhook(payload []byte, secret string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(payload)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
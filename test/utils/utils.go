@@ -236,6 +236,33 @@ func CreateJitRequest(ctx context.Context, k8sClient client.Client, startDelay t
 	return jit, nil
 }
 
+// CreateClusterScopedJitRequest creates a cluster scoped JustInTimeRequest with a startTime delay in seconds
+func CreateClusterScopedJitRequest(ctx context.Context, k8sClient client.Client, startDelay time.Duration, clusterRole string) (*justintimev1.JitRequest, error) { //nolint:lll
+	jit := &justintimev1.JitRequest{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "e2e-jit-test",
+		},
+		Spec: justintimev1.JitRequestSpec{
+			ClusterRole:   clusterRole,
+			ClusterScoped: true,
+			Reporter:      "master-chief@unsc.com",
+			StartTime:     metav1.NewTime(metav1.Now().Add(startDelay * time.Second)),
+			EndTime:       metav1.NewTime(metav1.Now().Add(20 * time.Second)),
+			JiraFields: map[string]string{
+				"Approver":      "cptKeyes",
+				"ProductOwner":  "Oni",
+				"Justification": "I need a weapon",
+			},
+		},
+	}
+
+	if err := k8sClient.Create(ctx, jit); err != nil {
+		return nil, fmt.Errorf("failed to create JIT request: %w", err)
+	}
+
+	return jit, nil
+}
+
 // CreateJitConfig creates a JustInTimeConfig
 func CreateJitConfig(ctx context.Context, k8sClient client.Client, clusterRole, namespace string) error {
 
@@ -247,13 +274,23 @@ func CreateJitConfig(ctx context.Context, k8sClient client.Client, clusterRole,
 			AllowedClusterRoles: []string{
 				clusterRole,
 			},
-			JiraWorkflowApproveStatus: "Approved",
-			RejectedTransitionID:      "21",
-			JiraProject:               "IAM",
-			JiraIssueType:             "Access Request",
-			CompletedTransitionID:     "41",
-			AdditionalCommentText:     "config: default",
-			NamespaceAllowedRegex:     fmt.Sprintf("^%s$", namespace),
+			AllowedClusterScopedRoles: []string{
+				ValidClusterScopedRole,
+			},
+			JiraWorkflowApproveStatus:    "Approved",
+			JiraWorkflowRejectedStatus:   "rejected",
+			JiraWorkflowRejectedStatuses: []string{"declined"},
+			RejectedTransitionID:         "21",
+			JiraProject:                  "IAM",
+			JiraIssueType:                "Access Request",
+			LinkedIssueProjects:          []string{"IAM", "INC"},
+			IssueLinkType:                "Relates",
+			CompletedTransitionID:        "41",
+			RevokedTransitionID:          "51",
+			ExpiredTransitionID:          "71",
+			ExtensionTransitionID:        "61",
+			AdditionalCommentText:        "config: default",
+			NamespaceAllowedRegex:        fmt.Sprintf("^%s$", namespace),
 			Labels: []string{
 				"default-config",
 			},
@@ -262,9 +299,9 @@ func CreateJitConfig(ctx context.Context, k8sClient client.Client, clusterRole,
 				Cluster:     "minikube",
 			},
 			RequiredFields: &justintimev1.RequiredFieldsSpec{
-				StartTime:   justintimev1.CustomFieldSettings{Type: "date", JiraCustomField: "customfield_10118"},
-				EndTime:     justintimev1.CustomFieldSettings{Type: "date", JiraCustomField: "customfield_10119"},
-				ClusterRole: justintimev1.CustomFieldSettings{Type: "date", JiraCustomField: "customfield_10117"},
+				StartTime:   justintimev1.CustomFieldSettings{Type: "datetime", JiraCustomField: "customfield_10118"},
+				EndTime:     justintimev1.CustomFieldSettings{Type: "datetime", JiraCustomField: "customfield_10119"},
+				ClusterRole: justintimev1.CustomFieldSettings{Type: "text", JiraCustomField: "customfield_10117"},
 			},
 			CustomFields: map[string]justintimev1.CustomFieldSettings{
 				"Approver":      {Type: "user", JiraCustomField: "customfield_10114"},
@@ -272,6 +309,19 @@ func CreateJitConfig(ctx context.Context, k8sClient client.Client, clusterRole,
 				"Justification": {Type: "text", JiraCustomField: "customfield_10116"},
 			},
 			SelfApprovalEnabled: false,
+			// short retention so finished JitRequests are removed during tests
+			RetentionPeriod: &metav1.Duration{Duration: 5 * time.Second},
+			// poll often and allow a short window for late approvals during tests
+			ApprovalPollInterval: &metav1.Duration{Duration: 5 * time.Second},
+			ApprovalGracePeriod:  &metav1.Duration{Duration: 10 * time.Second},
+			ApprovalMode:         justintimev1.ApprovalModeStatus,
+			RolePolicies: map[string]justintimev1.RolePolicySpec{
+				ValidClusterScopedRole: {
+					MaxDuration:        &metav1.Duration{Duration: time.Hour},
+					RequiredJiraFields: []string{"Justification"},
+				},
+			},
+			Timezone: "UTC",
 		},
 	}
 